@@ -0,0 +1,15 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CertificateStatus is status of a certificate used by registry or notary
+type CertificateStatus struct {
+	// Secret is the name of tls secret which has the certificate
+	Secret string `json:"secret"`
+	// NotAfter is the time when the certificate expires
+	NotAfter metav1.Time `json:"notAfter"`
+	// LastRenewal is the latest time when the certificate is reissued by the operator or replaced in the secret
+	LastRenewal *metav1.Time `json:"lastRenewal,omitempty"`
+}
@@ -19,12 +19,33 @@ const (
 	ConditionTypeSecretTLS = status.ConditionType("SecretTlsExist")
 	// ConditionTypeIngress is a condition that ingress exists
 	ConditionTypeIngress = status.ConditionType("IngressExist")
+	// ConditionTypeRoute is a condition that gateway route exists and is accepted
+	ConditionTypeRoute = status.ConditionType("RouteExist")
 	// ConditionTypePvc is a condition that PVC exists
 	ConditionTypePvc = status.ConditionType("PvcExist")
 	// ConditionTypeConfigMap is a condition that confimap exists
 	ConditionTypeConfigMap = status.ConditionType("ConfigMapExist")
 	// ConditionTypeNotary is a condition that notary exists
 	ConditionTypeNotary = status.ConditionType("NotaryExist")
+	// ConditionTypePodDisruptionBudget is a condition that pod disruption budget exists
+	ConditionTypePodDisruptionBudget = status.ConditionType("PodDisruptionBudgetExist")
+	// ConditionTypeHorizontalPodAutoscaler is a condition that horizontal pod autoscaler exists
+	ConditionTypeHorizontalPodAutoscaler = status.ConditionType("HorizontalPodAutoscalerExist")
+	// ConditionTypeStorageNearlyFull is a condition that registry's storage is nearly full.
+	// It is informational and does not affect registry phase.
+	ConditionTypeStorageNearlyFull = status.ConditionType("StorageNearlyFull")
+	// ConditionTypeCertificateExpiring is a condition that a certificate issued by the operator expires soon.
+	// It is informational and used by both registry and notary.
+	ConditionTypeCertificateExpiring = status.ConditionType("CertificateExpiring")
+	// ConditionTypeAPIReachable is a condition that registry serves /v2/ api.
+	// It is informational and does not change registry's phase, like conditions below.
+	ConditionTypeAPIReachable = status.ConditionType("APIReachable")
+	// ConditionTypeAuthWorking is a condition that a token can be acquired with registry's login credential
+	ConditionTypeAuthWorking = status.ConditionType("AuthWorking")
+	// ConditionTypeStorageWritable is a condition that registry can write a test blob to its storage
+	ConditionTypeStorageWritable = status.ConditionType("StorageWritable")
+	// ConditionTypeNotaryHealthy is a condition that notary server reports it is healthy
+	ConditionTypeNotaryHealthy = status.ConditionType("NotaryHealthy")
 
 	/* Notary conditions */
 
@@ -36,6 +57,8 @@ const (
 	ConditionTypeNotaryDBService = status.ConditionType("NotaryDBServiceExist")
 	// ConditionTypeNotaryServerIngress is a condition that notary server ingress exists
 	ConditionTypeNotaryServerIngress = status.ConditionType("NotaryServerIngressExist")
+	// ConditionTypeNotaryServerRoute is a condition that notary server gateway route exists and is accepted
+	ConditionTypeNotaryServerRoute = status.ConditionType("NotaryServerRouteExist")
 	// ConditionTypeNotaryServerPod is a condition that notary server pod exists
 	ConditionTypeNotaryServerPod = status.ConditionType("NotaryServerPodExist")
 	// ConditionTypeNotaryServerSecret is a condition that notary server secret exists
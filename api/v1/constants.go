@@ -20,4 +20,6 @@ const (
 	RegistryRootCASecretName = "registry-ca"
 	// KeycloakCASecretName is keycloak cert secret name
 	KeycloakCASecretName = "keycloak-cert"
+	// RegistryTokenKeySecretName is the secret which has certificate bundle to verify registry tokens
+	RegistryTokenKeySecretName = "registry-token-key"
 )
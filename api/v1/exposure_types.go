@@ -0,0 +1,44 @@
+package v1
+
+// ExposureOptions is settings to expose registry or notary server out of the cluster
+type ExposureOptions struct {
+	// Ingress class of the ingress for Ingress service type (default: ingress.class_name config)
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Hostname template for Ingress and Gateway service types. {name}, {namespace}, {component}(registry or notary)
+	// and {domain} are replaced. (default: ingress.hostname_template config)
+	// (example: {name}.{namespace}.registry.example.com)
+	HostnameTemplate string `json:"hostnameTemplate,omitempty"`
+	// Gateway to attach a route to for Gateway service type
+	Gateway *GatewayRef `json:"gateway,omitempty"`
+}
+
+// GatewayRouteKind is kind of Gateway API route
+type GatewayRouteKind string
+
+const (
+	// GatewayRouteKindTLS passes tls through the gateway to the server
+	GatewayRouteKindTLS = GatewayRouteKind("TLSRoute")
+	// GatewayRouteKindHTTP needs the gateway to terminate tls and to connect to the server with https
+	GatewayRouteKindHTTP = GatewayRouteKind("HTTPRoute")
+)
+
+// GatewayRef is a reference to Gateway API Gateway
+type GatewayRef struct {
+	// Name of the gateway
+	Name string `json:"name"`
+	// Namespace of the gateway (default: namespace of the registry)
+	Namespace string `json:"namespace,omitempty"`
+	// Listener of the gateway to attach the route to
+	SectionName string `json:"sectionName,omitempty"`
+	// Kind of the route to create (default: TLSRoute)
+	// +kubebuilder:validation:Enum=TLSRoute;HTTPRoute
+	RouteKind GatewayRouteKind `json:"routeKind,omitempty"`
+}
+
+// GetRouteKind returns kind of the route to create
+func (g *GatewayRef) GetRouteKind() GatewayRouteKind {
+	if g.RouteKind == "" {
+		return GatewayRouteKindTLS
+	}
+	return g.RouteKind
+}
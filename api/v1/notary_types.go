@@ -34,9 +34,11 @@ type NotarySpec struct {
 	// Settings for registry authentication config
 	AuthConfig AuthConfig `json:"authConfig"`
 	// Service type to expose notary
-	// +kubebuilder:validation:Enum=Ingress;LoadBalancer
-	ServiceType           NotaryServiceType `json:"serviceType"`
-	PersistentVolumeClaim NotaryPVC         `json:"persistentVolumeClaim"`
+	// +kubebuilder:validation:Enum=Ingress;LoadBalancer;NodePort;Gateway
+	ServiceType NotaryServiceType `json:"serviceType"`
+	// Settings to expose notary server by the service type
+	ExposureOptions       `json:",inline"`
+	PersistentVolumeClaim NotaryPVC `json:"persistentVolumeClaim"`
 
 	// Settings for notary server
 	Server NotaryServer `json:"server,omitempty"`
@@ -44,6 +46,8 @@ type NotarySpec struct {
 	Signer NotarySigner `json:"signer,omitempty"`
 	// Settings for notary database
 	DB NotaryDB `json:"db,omitempty"`
+	// Settings to use a certificate of your own or from cert-manager for notary server
+	TLS *TLSConfig `json:"tls,omitempty"`
 }
 
 type NotaryServiceType string
@@ -51,6 +55,8 @@ type NotaryServiceType string
 const (
 	NotaryServiceTypeIngress      = NotaryServiceType("Ingress")
 	NotaryServiceTypeLoadBalancer = NotaryServiceType("LoadBalancer")
+	NotaryServiceTypeNodePort     = NotaryServiceType("NodePort")
+	NotaryServiceTypeGateway      = NotaryServiceType("Gateway")
 )
 
 type NotaryPVC struct {
@@ -93,6 +99,8 @@ type NotaryStatus struct {
 	SignerClusterIP      string            `json:"signerClusterIP,omitempty"`
 	SignerLoadBalancerIP string            `json:"signerLoadBalancerIP,omitempty"`
 	NotaryURL            string            `json:"notaryURL,omitempty"`
+	// Certificates is expiry of certificates issued by the operator for notary server and signer
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,42 @@
+package v1
+
+// RegistryQuota is storage quota of registry
+type RegistryQuota struct {
+	// Maximum total size of images in registry like "50Gi". If exceeded, registry becomes read-only.
+	MaxSize string `json:"maxSize,omitempty"`
+
+	// Maximum number of repositories. Pushing an image to a new repository beyond it is rejected.
+	// +kubebuilder:validation:Minimum=0
+	MaxRepositories *int32 `json:"maxRepositories,omitempty"`
+
+	// Maximum number of tags per repository. Pushing a new tag beyond it is rejected.
+	// +kubebuilder:validation:Minimum=0
+	MaxTagsPerRepository *int32 `json:"maxTagsPerRepository,omitempty"`
+}
+
+// RepositoryQuota is storage quota of repository. Pushing an image beyond it is rejected.
+type RepositoryQuota struct {
+	// Maximum total size of images in repository like "5Gi"
+	MaxSize string `json:"maxSize,omitempty"`
+
+	// Maximum number of tags. It overrides registry's `maxTagsPerRepository`.
+	// +kubebuilder:validation:Minimum=0
+	MaxTags *int32 `json:"maxTags,omitempty"`
+}
+
+// QuotaStatus is quota usage of registry
+type QuotaStatus struct {
+	// UsedBytes is the size of unique blobs referenced by all repositories
+	UsedBytes int64 `json:"usedBytes"`
+	// Repositories is the number of repositories
+	Repositories int32 `json:"repositories"`
+	// Exceeded is set if registry exceeds its quota and is switched to read-only
+	Exceeded bool `json:"exceeded,omitempty"`
+	// Message is the reason why quota is exceeded
+	Message string `json:"message,omitempty"`
+}
+
+// IsExceeded returns true if quota status is set and exceeded
+func (q *QuotaStatus) IsExceeded() bool {
+	return q != nil && q.Exceeded
+}
@@ -0,0 +1,20 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryCredentialRotation is settings for automatic rotation of registry's login password
+type RegistryCredentialRotation struct {
+	// Period to generate a new login password, like "720h"
+	Period metav1.Duration `json:"period"`
+	// GracePeriod while the previous password is still accepted after rotation (default: 1h).
+	// It is honored only by the builtin token service.
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// CredentialRotationStatus is status of login password rotation
+type CredentialRotationStatus struct {
+	// LastRotation is the latest time when login password is rotated
+	LastRotation *metav1.Time `json:"lastRotation,omitempty"`
+}
@@ -0,0 +1,26 @@
+package v1
+
+// RegistryDeletionPolicy is what to do with registry's data when registry is deleted
+// +kubebuilder:validation:Enum=Retain;Delete;Snapshot
+type RegistryDeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps registry's pvc by removing its owner reference
+	DeletionPolicyRetain = RegistryDeletionPolicy("Retain")
+	// DeletionPolicyDelete deletes registry's pvc created by the operator
+	DeletionPolicyDelete = RegistryDeletionPolicy("Delete")
+	// DeletionPolicySnapshot takes a VolumeSnapshot of registry's pvc and then deletes the pvc created by the operator
+	DeletionPolicySnapshot = RegistryDeletionPolicy("Snapshot")
+)
+
+// GetDeletionPolicy returns registry's deletion policy.
+// If it is not set, Delete is used for the pvc created with deleteWithPvc and Retain for others.
+func (s RegistrySpec) GetDeletionPolicy() RegistryDeletionPolicy {
+	if s.DeletionPolicy != "" {
+		return s.DeletionPolicy
+	}
+	if s.PersistentVolumeClaim.Create != nil && s.PersistentVolumeClaim.Create.DeleteWithPvc {
+		return DeletionPolicyDelete
+	}
+	return DeletionPolicyRetain
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryGarbageCollection is scheduled garbage collection setting of registry.
+// Registry is switched to read-only while garbage collection is running unless DryRun is set.
+type RegistryGarbageCollection struct {
+	// Schedule is a cron spec like "0 3 * * *" when garbage collection runs
+	Schedule string `json:"schedule"`
+	// If true, only reports what would be deleted without deleting anything
+	DryRun bool `json:"dryRun,omitempty"`
+	// If true, manifests which are not referenced by any tag are also deleted
+	DeleteUntagged bool `json:"deleteUntagged,omitempty"`
+}
+
+// GarbageCollectionStatus is status of scheduled garbage collection
+type GarbageCollectionStatus struct {
+	// LastScheduledTime is the latest time when garbage collection is scheduled
+	LastScheduledTime *metav1.Time `json:"lastScheduledTime,omitempty"`
+	// LastReport is the report of the latest garbage collection
+	LastReport *GarbageCollectionReport `json:"lastReport,omitempty"`
+	// StartTime is the time when the running garbage collection is started. It is cleared when garbage collection is finished.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// GarbageCollectionReport is the result of garbage collection
+type GarbageCollectionReport struct {
+	// StartTime is the time when garbage collection is started
+	StartTime metav1.Time `json:"startTime"`
+	// CompletionTime is the time when garbage collection is finished
+	CompletionTime metav1.Time `json:"completionTime"`
+	// Duration is how long garbage collection took including read-only window
+	Duration string `json:"duration"`
+	// DryRun is set if nothing is deleted actually
+	DryRun bool `json:"dryRun,omitempty"`
+	// BlobsMarked is the number of blobs in use
+	BlobsMarked int32 `json:"blobsMarked"`
+	// BlobsDeleted is the number of blobs deleted (or to be deleted if dry-run)
+	BlobsDeleted int32 `json:"blobsDeleted"`
+	// ManifestsDeleted is the number of manifests deleted (or to be deleted if dry-run)
+	ManifestsDeleted int32 `json:"manifestsDeleted"`
+	// ReclaimedBytes is the size of deleted blobs (or to be deleted if dry-run). It is only counted for filesystem storage,
+	// and unset if unknown.
+	// +optional
+	ReclaimedBytes *int64 `json:"reclaimedBytes,omitempty"`
+	// Error is set if garbage collection failed
+	Error string `json:"error,omitempty"`
+}
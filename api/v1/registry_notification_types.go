@@ -0,0 +1,68 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorNotificationEndpoint is the name of notification endpoint which sends registry events to the operator
+const OperatorNotificationEndpoint = "registry-operator"
+
+// RegistryNotificationAction is an action of registry event
+// +kubebuilder:validation:Enum=push;pull;mount;delete
+type RegistryNotificationAction string
+
+const (
+	// RegistryNotificationActionPush is sent when manifest or blob is pushed
+	RegistryNotificationActionPush = RegistryNotificationAction("push")
+	// RegistryNotificationActionPull is sent when manifest or blob is pulled
+	RegistryNotificationActionPull = RegistryNotificationAction("pull")
+	// RegistryNotificationActionMount is sent when blob is mounted from another repository
+	RegistryNotificationActionMount = RegistryNotificationAction("mount")
+	// RegistryNotificationActionDelete is sent when manifest or blob is deleted
+	RegistryNotificationActionDelete = RegistryNotificationAction("delete")
+)
+
+// RegistryNotificationActions are all actions of registry event
+var RegistryNotificationActions = []RegistryNotificationAction{
+	RegistryNotificationActionPush,
+	RegistryNotificationActionPull,
+	RegistryNotificationActionMount,
+	RegistryNotificationActionDelete,
+}
+
+// RegistryNotifications is settings for sending registry events to user-defined endpoints.
+// The endpoint of the operator is always kept.
+type RegistryNotifications struct {
+	// Endpoints which registry events are sent to
+	Endpoints []RegistryNotificationEndpoint `json:"endpoints,omitempty"`
+}
+
+// RegistryNotificationEndpoint is an endpoint which registry events are sent to
+type RegistryNotificationEndpoint struct {
+	// Name of the endpoint. It must be unique and cannot be `registry-operator`.
+	Name string `json:"name"`
+	// URL which events are posted to
+	URL string `json:"url"`
+	// Headers added to the requests
+	Headers []RegistryNotificationHeader `json:"headers,omitempty"`
+	// Actions of events to send. If empty, events of all actions are sent.
+	Actions []RegistryNotificationAction `json:"actions,omitempty"`
+	// How long to wait for a response (default: registry's default 1s)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// How many failures are allowed before backing off (default: registry's default 10)
+	// +kubebuilder:validation:Minimum=1
+	Threshold int32 `json:"threshold,omitempty"`
+	// How long to back off after failures exceed threshold (default: registry's default 1s)
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// RegistryNotificationHeader is a header of notification request. Either value or valueFrom must be set.
+type RegistryNotificationHeader struct {
+	// Header name
+	Name string `json:"name"`
+	// Header value
+	Value string `json:"value,omitempty"`
+	// Key of a secret in the same namespace which has header value
+	ValueFrom *corev1.SecretKeySelector `json:"valueFrom,omitempty"`
+}
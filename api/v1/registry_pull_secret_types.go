@@ -0,0 +1,26 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImagePullSecretDistributionLabel is the label of namespaces which accept image pull secrets distributed by registries.
+// Namespaces other than the registry's own are selected only if it is "true".
+const ImagePullSecretDistributionLabel = "tmax.io/image-pull-secret-distribution"
+
+// ImagePullSecretDistribution is settings to distribute registry's image pull secret to other namespaces
+type ImagePullSecretDistribution struct {
+	// NamespaceSelector selects namespaces where a synchronized copy of the image pull secret is kept.
+	// Namespaces other than the registry's own must also have the label tmax.io/image-pull-secret-distribution=true.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+	// ServiceAccounts are names of service accounts in the selected namespaces whose imagePullSecrets refer to the copy
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// ImagePullSecretDistributionStatus is status of image pull secret distribution
+type ImagePullSecretDistributionStatus struct {
+	// Namespaces where the image pull secret is distributed
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Message is an error message of the latest distribution
+	Message string `json:"message,omitempty"`
+}
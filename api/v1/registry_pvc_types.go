@@ -19,7 +19,7 @@ type CreatePvc struct {
 	// StorageClassName like "csi-cephfs-sc"
 	StorageClassName string `json:"storageClassName"`
 
-	// Delete the pvc as well when this registry is deleted (default: false)
+	// Delete the pvc as well when this registry is deleted (default: false). Deprecated: use registry's deletionPolicy.
 	DeleteWithPvc bool `json:"deleteWithPvc,omitempty"`
 }
 
@@ -0,0 +1,55 @@
+package v1
+
+// RegistryStorageType is a storage driver type of registry
+type RegistryStorageType string
+
+const (
+	// RegistryStorageTypeFilesystem stores registry data in pvc
+	RegistryStorageTypeFilesystem = RegistryStorageType("Filesystem")
+	// RegistryStorageTypeS3 stores registry data in S3-compatible object storage
+	RegistryStorageTypeS3 = RegistryStorageType("S3")
+)
+
+const (
+	// S3AccessKey is the key of access key in S3 credential secret
+	S3AccessKey = "accessKey"
+	// S3SecretKey is the key of secret key in S3 credential secret
+	S3SecretKey = "secretKey"
+)
+
+type RegistryStorage struct {
+	// Storage driver type. If type is Filesystem, `persistentVolumeClaim` is used. (default: Filesystem)
+	// +kubebuilder:validation:Enum=Filesystem;S3
+	Type RegistryStorageType `json:"type,omitempty"`
+
+	// Settings for S3-compatible object storage. Required if type is S3.
+	S3 *S3Storage `json:"s3,omitempty"`
+}
+
+type S3Storage struct {
+	// Endpoint of S3-compatible storage like "http://minio.minio-system:9000". Leave empty to use AWS S3.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Bucket name to store registry data
+	Bucket string `json:"bucket"`
+
+	// Region of the bucket like "us-east-1"
+	Region string `json:"region"`
+
+	// Path prefix in the bucket where registry data is stored
+	RootDirectory string `json:"rootDirectory,omitempty"`
+
+	// Secret's name which has `accessKey` and `secretKey`
+	CredentialSecret string `json:"credentialSecret"`
+
+	// Use http instead of https to connect to the endpoint (default: false)
+	Insecure bool `json:"insecure,omitempty"`
+
+	// Skip verifying the endpoint's TLS certificate (default: false)
+	SkipVerify bool `json:"skipVerify,omitempty"`
+}
+
+// UsePVC returns true if registry data is stored in pvc
+func (s RegistryStorage) UsePVC() bool {
+	return s.Type != RegistryStorageTypeS3
+}
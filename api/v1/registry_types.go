@@ -33,36 +33,74 @@ type RegistrySpec struct {
 	Description string `json:"description,omitempty"`
 	// Login ID for registry
 	LoginID string `json:"loginId"`
-	// Login password for registry
-	LoginPassword string `json:"loginPassword"`
+	// Login password for registry. Either loginPassword or passwordSecretRef must be set.
+	LoginPassword string `json:"loginPassword,omitempty"`
+	// Key of a secret in the same namespace which has login password for registry. It takes precedence over loginPassword.
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	// Settings for automatic rotation of login password. Once rotated, the generated password replaces the given one.
+	CredentialRotation *RegistryCredentialRotation `json:"credentialRotation,omitempty"`
+	// Settings to distribute image pull secret of the registry to other namespaces and their service accounts
+	ImagePullSecretDistribution *ImagePullSecretDistribution `json:"imagePullSecretDistribution,omitempty"`
 	// If ReadOnly is true, clients will not be allowed to write(push) to the registry.
 	ReadOnly bool `json:"readOnly,omitempty"`
 	// Settings for notary service
 	Notary RegistryNotary `json:"notary,omitempty"`
 	// The name of the configmap where the registry config.yml content
 	CustomConfigYml string `json:"customConfigYml,omitempty"`
+	// YAML or JSON fragment deep-merged into config.yml generated by the operator. Settings managed by the operator,
+	// such as auth, http.tls, notifications.endpoints, proxy and storage drivers, cannot be overridden.
+	// It cannot be used with customConfigYml.
+	ConfigOverrides string `json:"configOverrides,omitempty"`
+	// Settings for sending registry events to user-defined endpoints in addition to the operator
+	Notifications *RegistryNotifications `json:"notifications,omitempty"`
 
 	// Settings for registry's deployemnt
 	RegistryDeployment RegistryDeployment `json:"registryDeployment,omitempty"`
 	// Service type to expose registry
 	RegistryService RegistryService `json:"service"`
-	// Settings for registry pvc. Either `Exist` or `Create` must be entered.
-	PersistentVolumeClaim RegistryPVC `json:"persistentVolumeClaim"`
+	// Settings for registry pvc. Either `Exist` or `Create` must be entered if storage type is Filesystem.
+	PersistentVolumeClaim RegistryPVC `json:"persistentVolumeClaim,omitempty"`
+	// Settings for registry storage driver (default: Filesystem)
+	Storage RegistryStorage `json:"storage,omitempty"`
+	// Settings for pull-through cache. If set, registry proxies the external registry and push is not allowed.
+	Proxy *RegistryProxy `json:"proxy,omitempty"`
+	// Settings for storage quota of registry and its repositories
+	Quota *RegistryQuota `json:"quota,omitempty"`
+	// Settings for scheduled garbage collection
+	GarbageCollection *RegistryGarbageCollection `json:"garbageCollection,omitempty"`
+	// Settings to use a certificate of your own or from cert-manager instead of the operator's one
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// What to do with registry's pvc when registry is deleted: Retain, Delete or Snapshot.
+	// The pvc given by persistentVolumeClaim.exist is never deleted. (default: Delete if deleteWithPvc is set, otherwise Retain)
+	DeletionPolicy RegistryDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// VolumeSnapshotClass of the snapshot taken when deletionPolicy is Snapshot (default: cluster's default class)
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// RegistryProxy is pull-through cache configuration
+type RegistryProxy struct {
+	// ExternalRegistry's name in the same namespace to proxy. Its login secret is used as remote credentials.
+	ExternalRegistry string `json:"externalRegistry"`
 }
 
 // RegistryNotary is notary service configuration
 type RegistryNotary struct {
 	// Activate notary service to sign images
 	Enabled bool `json:"enabled"`
-	// Use Ingress or LoadBalancer
-	// +kubebuilder:validation:Enum=Ingress;LoadBalancer
+	// Use Ingress, LoadBalancer, NodePort or Gateway
+	// +kubebuilder:validation:Enum=Ingress;LoadBalancer;NodePort;Gateway
 	ServiceType NotaryServiceType `json:"serviceType,omitempty"`
+	// Settings to expose notary server by the service type
+	ExposureOptions `json:",inline"`
 	// Settings for notary pvc. Either `Exist` or `Create` must be entered.
 	PersistentVolumeClaim NotaryPVC `json:"persistentVolumeClaim,omitempty"`
 	// Settings for notary server
 	Server NotaryServer `json:"server,omitempty"`
 	// Settings for notary signer
 	Signer NotarySigner `json:"signer,omitempty"`
+	// Settings to use a certificate of your own or from cert-manager for notary server.
+	// Notary signer is only reached inside the cluster, so its certificate is always issued by the operator.
+	TLS *TLSConfig `json:"tls,omitempty"`
 	// Settings for notary database
 	DB NotaryDB `json:"db,omitempty"`
 }
@@ -79,6 +117,55 @@ type RegistryDeployment struct {
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 	// Deployment's resource requirements (default: Both limits and requests are `cpu:100m` and `memory:512Mi`)
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Number of registry pods. More than one replica requires shared storage (S3 or ReadWriteMany pvc). (default: 1)
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Spread registry pods across nodes. Preferred or Required. (default: no anti-affinity)
+	// +kubebuilder:validation:Enum=Preferred;Required
+	AntiAffinity RegistryAntiAffinityType `json:"antiAffinity,omitempty"`
+	// Settings for registry's horizontal pod autoscaler. If set, replicas is managed by autoscaler.
+	Autoscaling *RegistryAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// RegistryAntiAffinityType is pod anti-affinity type of registry pods
+type RegistryAntiAffinityType string
+
+const (
+	// RegistryAntiAffinityPreferred tries to schedule registry pods on different nodes
+	RegistryAntiAffinityPreferred = RegistryAntiAffinityType("Preferred")
+	// RegistryAntiAffinityRequired schedules registry pods only on different nodes
+	RegistryAntiAffinityRequired = RegistryAntiAffinityType("Required")
+)
+
+// RegistryAutoscaling is horizontal pod autoscaler settings of registry server
+type RegistryAutoscaling struct {
+	// Minimum number of registry pods (default: 1)
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Maximum number of registry pods
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Target average CPU utilization of registry pods (default: 80)
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+// DesiredReplicas returns the number of registry pods to run at least
+func (d RegistryDeployment) DesiredReplicas() int32 {
+	if d.Autoscaling != nil && d.Autoscaling.MinReplicas != nil {
+		return *d.Autoscaling.MinReplicas
+	}
+	if d.Replicas != nil {
+		return *d.Replicas
+	}
+	return 1
+}
+
+// MaxReplicas returns the number of registry pods which can be run at most
+func (d RegistryDeployment) MaxReplicas() int32 {
+	if d.Autoscaling != nil {
+		return d.Autoscaling.MaxReplicas
+	}
+	return d.DesiredReplicas()
 }
 
 // RegistryServiceType is type of registry service
@@ -87,12 +174,17 @@ type RegistryServiceType string
 const (
 	RegServiceTypeLoadBalancer = "LoadBalancer"
 	RegServiceTypeIngress      = "ClusterIP"
+	RegServiceTypeNodePort     = "NodePort"
+	// RegServiceTypeGateway exposes ClusterIP service by Gateway API route
+	RegServiceTypeGateway = "Gateway"
 )
 
 type RegistryService struct {
-	// Use Ingress or LoadBalancer
-	// +kubebuilder:validation:Enum=Ingress;LoadBalancer
+	// Use Ingress, LoadBalancer, NodePort or Gateway
+	// +kubebuilder:validation:Enum=Ingress;LoadBalancer;NodePort;Gateway
 	ServiceType RegistryServiceType `json:"serviceType"`
+	// Settings to expose registry by the service type
+	ExposureOptions `json:",inline"`
 	// use ingress service type
 	// (Deprecated)
 	// Ingress Ingress `json:"ingress,omitempty"`
@@ -142,6 +234,76 @@ type RegistryStatus struct {
 	ServerURL string `json:"serverURL,omitempty"`
 	// NotaryURL is notary server URL
 	NotaryURL string `json:"notaryURL,omitempty"`
+	// Replicas is ready and desired replicas of each registry component
+	Replicas []ComponentReplicas `json:"replicas,omitempty"`
+	// Proxy is pull-through cache status
+	Proxy *ProxyStatus `json:"proxy,omitempty"`
+	// StorageUsage is usage of registry's pvc
+	StorageUsage *StorageUsage `json:"storageUsage,omitempty"`
+	// Quota is quota usage of registry
+	Quota *QuotaStatus `json:"quota,omitempty"`
+	// GarbageCollection is status of scheduled garbage collection
+	GarbageCollection *GarbageCollectionStatus `json:"garbageCollection,omitempty"`
+	// CredentialRotation is status of login password rotation
+	CredentialRotation *CredentialRotationStatus `json:"credentialRotation,omitempty"`
+	// ImagePullSecretDistribution is status of image pull secret distribution
+	ImagePullSecretDistribution *ImagePullSecretDistributionStatus `json:"imagePullSecretDistribution,omitempty"`
+	// Certificates is expiry of certificates issued by the operator for registry
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+	// ConfigHash is the hash of effective config.yml generated by the operator. Registry pods roll out when it changes.
+	ConfigHash string `json:"configHash,omitempty"`
+	// HealthProbe is status of periodic health probes. Their results are conditions.
+	HealthProbe *HealthProbeStatus `json:"healthProbe,omitempty"`
+}
+
+// HealthProbeStatus is status of health probes of running registry
+type HealthProbeStatus struct {
+	// LastUpdated is the time when registry was probed
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// StorageUsage is usage of registry's storage
+type StorageUsage struct {
+	// UsedBytes is the size of used storage
+	UsedBytes int64 `json:"usedBytes"`
+	// FreeBytes is the size of available storage
+	FreeBytes int64 `json:"freeBytes"`
+	// LastUpdated is the time when the usage was collected
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// ProxyStatus is pull-through cache status of registry
+type ProxyStatus struct {
+	// RemoteURL is the url of proxied registry
+	RemoteURL string `json:"remoteURL,omitempty"`
+	// Blobs is cache statistics of blobs
+	Blobs ProxyCacheStats `json:"blobs,omitempty"`
+	// Manifests is cache statistics of manifests
+	Manifests ProxyCacheStats `json:"manifests,omitempty"`
+	// LastUpdated is the time when the statistics were collected
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// ProxyCacheStats is cache statistics summed up from all registry pods since they started
+type ProxyCacheStats struct {
+	// Hits is the number of requests served from cache
+	Hits int64 `json:"hits"`
+	// Misses is the number of requests fetched from remote registry
+	Misses int64 `json:"misses"`
+	// BytesPulled is the size of data fetched from remote registry
+	BytesPulled int64 `json:"bytesPulled"`
+	// BytesServed is the size of data served from cache
+	BytesServed int64 `json:"bytesServed"`
+}
+
+// ComponentReplicas is replica status of a registry component
+type ComponentReplicas struct {
+	// Name of the component like registry, notary-server, notary-signer and notary-db
+	Name string `json:"name"`
+	// Desired is the number of desired pods
+	Desired int32 `json:"desired"`
+	// Ready is the number of ready pods
+	Ready int32 `json:"ready"`
 }
 
 // +kubebuilder:object:root=true
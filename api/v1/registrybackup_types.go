@@ -0,0 +1,105 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupStorage is where registry backup is stored. Either PVC or S3 must be set.
+type BackupStorage struct {
+	// PVC to store backup. It is mounted to a temporary agent pod while backup or restore is running.
+	PVC *BackupPVC `json:"pvc,omitempty"`
+	// S3-compatible bucket to store backup
+	S3 *S3Storage `json:"s3,omitempty"`
+}
+
+// BackupPVC is a pvc to store backup
+type BackupPVC struct {
+	// Name of the pvc in the same namespace
+	ClaimName string `json:"claimName"`
+	// Directory in the pvc where backups are stored (default: /)
+	Path string `json:"path,omitempty"`
+}
+
+// BackupProgress is progress of backup or restore
+type BackupProgress struct {
+	// TotalImages is the number of images(=tags) to be handled
+	TotalImages int32 `json:"totalImages"`
+	// CompletedImages is the number of images handled
+	CompletedImages int32 `json:"completedImages"`
+	// Blobs is the number of blobs transferred
+	Blobs int32 `json:"blobs"`
+	// Bytes is the size of blobs transferred
+	Bytes int64 `json:"bytes"`
+}
+
+// RegistryBackupSpec defines the desired state of RegistryBackup
+type RegistryBackupSpec struct {
+	// Name of the registry to back up in the same namespace
+	Registry string `json:"registry"`
+	// Storage where backup is stored. Backup is stored under the directory named after RegistryBackup.
+	Storage BackupStorage `json:"storage"`
+	// If true, signer keys of the registry's images are backed up. They have private keys of image signers
+	// and are stored unencrypted, so the storage must be protected as well as the keys.
+	// +optional
+	IncludeSignerKeys bool `json:"includeSignerKeys,omitempty"`
+}
+
+// RegistryBackupStatus defines the observed state of RegistryBackup
+type RegistryBackupStatus struct {
+	// State is a state of the backup
+	State RegistryJobState `json:"state,omitempty"`
+	// Message is a message for the backup (normally an error string)
+	Message string `json:"message,omitempty"`
+	// StartTime is the time when backup is started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when backup is completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Progress is progress of the backup
+	Progress BackupProgress `json:"progress,omitempty"`
+	// Repositories is the number of repositories backed up
+	Repositories int32 `json:"repositories,omitempty"`
+	// Checksum is sha256 checksum of the backup index which has checksums of all backed up contents
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=regbackup
+// +kubebuilder:printcolumn:name="REGISTRY",type=string,JSONPath=`.spec.registry`
+// +kubebuilder:printcolumn:name="STATE",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RegistryBackup is the Schema for the registrybackups API
+type RegistryBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistryBackupSpec   `json:"spec,omitempty"`
+	Status RegistryBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryBackupList contains a list of RegistryBackup
+type RegistryBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryBackup{}, &RegistryBackupList{})
+}
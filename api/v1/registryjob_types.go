@@ -36,11 +36,14 @@ const (
 
 	JobTypeSynchronizeExtReg = RegistryJobType("SynchronizeExtReg")
 	JobTypeImageReplicate    = RegistryJobType("ImageReplicate")
+	JobTypeRegistryBackup    = RegistryJobType("RegistryBackup")
+	JobTypeRegistryRestore   = RegistryJobType("RegistryRestore")
+	JobTypeRetentionPolicy   = RegistryJobType("RetentionPolicy")
 )
 
 // RegistryJobClaim is a claim of registry job
 type RegistryJobClaim struct {
-	// +kubebuilder:validation:Enum=SynchronizeExtReg;ImageReplicate
+	// +kubebuilder:validation:Enum=SynchronizeExtReg;ImageReplicate;RegistryBackup;RegistryRestore;RetentionPolicy
 	// Type of job to work
 	JobType RegistryJobType `json:"jobType"`
 	// HandleObject refers to the HandleObject
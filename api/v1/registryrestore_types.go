@@ -0,0 +1,79 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryRestoreSpec defines the desired state of RegistryRestore
+type RegistryRestoreSpec struct {
+	// Storage where the backup is stored
+	Storage BackupStorage `json:"storage"`
+	// Name of the backup(=RegistryBackup's name) in the storage
+	Backup string `json:"backup"`
+	// Name of the registry to restore into in the same namespace.
+	// If it does not exist, it is created with the backed up registry spec.
+	Registry string `json:"registry"`
+}
+
+// RegistryRestoreStatus defines the observed state of RegistryRestore
+type RegistryRestoreStatus struct {
+	// State is a state of the restore
+	State RegistryJobState `json:"state,omitempty"`
+	// Message is a message for the restore (normally an error string)
+	Message string `json:"message,omitempty"`
+	// StartTime is the time when restore is started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when restore is completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Progress is progress of the restore
+	Progress BackupProgress `json:"progress,omitempty"`
+	// Checksum is sha256 checksum of the backup index which is verified before restore
+	Checksum string `json:"checksum,omitempty"`
+	// VerifiedContents is the number of restored contents whose checksum is verified
+	VerifiedContents int32 `json:"verifiedContents,omitempty"`
+	// RegistryCreated is set if the registry is created by the restore
+	RegistryCreated bool `json:"registryCreated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=regrestore
+// +kubebuilder:printcolumn:name="BACKUP",type=string,JSONPath=`.spec.backup`
+// +kubebuilder:printcolumn:name="REGISTRY",type=string,JSONPath=`.spec.registry`
+// +kubebuilder:printcolumn:name="STATE",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RegistryRestore is the Schema for the registryrestores API
+type RegistryRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistryRestoreSpec   `json:"spec,omitempty"`
+	Status RegistryRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryRestoreList contains a list of RegistryRestore
+type RegistryRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryRestore{}, &RegistryRestoreList{})
+}
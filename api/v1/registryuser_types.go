@@ -0,0 +1,111 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryUserState is a state of registry user
+type RegistryUserState string
+
+const (
+	// RegistryUserPending is a state that the user is waiting for the registry to be running
+	RegistryUserPending = RegistryUserState("Pending")
+	// RegistryUserActive is a state that the user can log in to the registry
+	RegistryUserActive = RegistryUserState("Active")
+	// RegistryUserExpired is a state that the user is expired and revoked
+	RegistryUserExpired = RegistryUserState("Expired")
+	// RegistryUserFailed is a state that the user failed to be provisioned
+	RegistryUserFailed = RegistryUserState("Failed")
+)
+
+const (
+	// RegistryUserUsernameKey is the key of username in the password secret
+	RegistryUserUsernameKey = "username"
+	// RegistryUserPasswordKey is the key of password in the password secret
+	RegistryUserPasswordKey = "password"
+)
+
+// RegistryUserSpec defines the desired state of RegistryUser
+type RegistryUserSpec struct {
+	// Name of the registry in the same namespace
+	Registry string `json:"registry"`
+	// Username to log in to the registry (default: name of RegistryUser)
+	Username string `json:"username,omitempty"`
+	// ExpiresAt is the time when the user is revoked. The user never expires if not set.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// RegistryUserStatus defines the observed state of RegistryUser
+type RegistryUserStatus struct {
+	// State is a state of the user
+	State RegistryUserState `json:"state,omitempty"`
+	// Message is a message for the state (normally an error string)
+	Message string `json:"message,omitempty"`
+	// PasswordSecret is the name of the secret which has username and generated password
+	PasswordSecret string `json:"passwordSecret,omitempty"`
+	// DockerConfigSecret is the name of the generated dockerconfigjson secret
+	DockerConfigSecret string `json:"dockerConfigSecret,omitempty"`
+	// LastUsed is the last time when the user pushed or pulled, taken from registry events
+	LastUsed *metav1.Time `json:"lastUsed,omitempty"`
+}
+
+// GetUsername returns the username to log in
+func (u *RegistryUser) GetUsername() string {
+	if u.Spec.Username != "" {
+		return u.Spec.Username
+	}
+	return u.Name
+}
+
+// IsExpired returns true if the user is expired at the time
+func (u *RegistryUser) IsExpired(now metav1.Time) bool {
+	return u.Spec.ExpiresAt != nil && !now.Before(u.Spec.ExpiresAt)
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=reguser
+// +kubebuilder:printcolumn:name="REGISTRY",type=string,JSONPath=`.spec.registry`
+// +kubebuilder:printcolumn:name="USERNAME",type=string,JSONPath=`.spec.username`
+// +kubebuilder:printcolumn:name="STATE",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="LAST_USED",type=date,JSONPath=`.status.lastUsed`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RegistryUser is the Schema for the registryusers API.
+// It is a named account of a registry whose password is generated into a secret.
+type RegistryUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegistryUserSpec   `json:"spec,omitempty"`
+	Status RegistryUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RegistryUserList contains a list of RegistryUser
+type RegistryUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RegistryUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RegistryUser{}, &RegistryUserList{})
+}
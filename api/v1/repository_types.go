@@ -9,6 +9,42 @@ type RepositorySpec struct {
 	Versions []ImageVersion `json:"versions,omitempty"`
 	// Name of Registry which owns repository
 	Registry string `json:"registry,omitempty"`
+	// Settings for storage quota of repository
+	Quota *RepositoryQuota `json:"quota,omitempty"`
+}
+
+type RepositoryStatus struct {
+	// UsedBytes is the size of unique blobs referenced by the versions
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+	// Versions are details of the versions(=tags) recorded from their manifests and configs
+	Versions []ImageVersionStatus `json:"versions,omitempty"`
+}
+
+// ImageVersionStatus is details of a version(=tag) of image
+type ImageVersionStatus struct {
+	// Version(=Tag) name
+	Version string `json:"version"`
+	// Digest of the manifest which the version refers to
+	Digest string `json:"digest,omitempty"`
+	// Media type of the manifest, e.g. manifest list or OCI image index for multi-platform images
+	MediaType string `json:"mediaType,omitempty"`
+	// Total compressed size of the manifests, configs and layers
+	Size int64 `json:"size,omitempty"`
+	// Platforms which the image runs on. For manifest lists, platforms of all manifests.
+	Platforms []ImagePlatform `json:"platforms,omitempty"`
+	// Labels of the image config, e.g. `org.opencontainers.image.*`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Number of times the version is pulled by its tag or digest
+	Pulls int64 `json:"pulls,omitempty"`
+	// Last time the version is pulled
+	LastPulledAt *metav1.Time `json:"lastPulledAt,omitempty"`
+}
+
+// ImagePlatform is an os and cpu architecture which an image runs on
+type ImagePlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
 }
 
 type ImageVersion struct {
@@ -34,7 +70,8 @@ type Repository struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec RepositorySpec `json:"spec"`
+	Spec   RepositorySpec   `json:"spec"`
+	Status RepositoryStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,77 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RepositoryAction is an action on repositories in a registry token scope
+// +kubebuilder:validation:Enum=pull;push;delete;*
+type RepositoryAction string
+
+const (
+	RepositoryActionPull   = RepositoryAction("pull")
+	RepositoryActionPush   = RepositoryAction("push")
+	RepositoryActionDelete = RepositoryAction("delete")
+	RepositoryActionAll    = RepositoryAction("*")
+)
+
+// RepositoryPermissionSpec defines the desired state of RepositoryPermission
+type RepositoryPermissionSpec struct {
+	// Name of the registry in the same namespace
+	Registry string `json:"registry"`
+	// Repositories are name patterns of repositories like team-a/* (shell file name pattern)
+	// +kubebuilder:validation:MinItems=1
+	Repositories []string `json:"repositories"`
+	// Actions granted on the repositories
+	// +kubebuilder:validation:MinItems=1
+	Actions []RepositoryAction `json:"actions"`
+	// Subjects are Kubernetes users, groups and service accounts to be granted.
+	// Namespace of a ServiceAccount defaults to the namespace of RepositoryPermission.
+	// +kubebuilder:validation:MinItems=1
+	Subjects []rbacv1.Subject `json:"subjects"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=repoperm
+// +kubebuilder:printcolumn:name="REGISTRY",type=string,JSONPath=`.spec.registry`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RepositoryPermission is the Schema for the repositorypermissions API.
+// It grants actions on repositories of a registry to Kubernetes subjects, who log in to the registry with their Kubernetes tokens.
+// It is enforced by the builtin token service. Actions can also be granted by RBAC verbs(pull, push, delete) on repositories resource.
+type RepositoryPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RepositoryPermissionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryPermissionList contains a list of RepositoryPermission
+type RepositoryPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositoryPermission `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RepositoryPermission{}, &RepositoryPermissionList{})
+}
@@ -0,0 +1,117 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RetentionPolicySpec defines the desired state of RetentionPolicy.
+// A tag is deleted only if it is matched by all of the rules which are set.
+// Signed tags and tags used by running pods are never deleted.
+type RetentionPolicySpec struct {
+	// Name of the registry in the same namespace
+	Registry string `json:"registry"`
+	// Patterns of repository names which the policy is applied to (e.g. `library/*`).
+	// If empty, the policy is applied to all repositories of the registry.
+	Repositories []string `json:"repositories,omitempty"`
+	// Keep the last N tags of each repository, ordered by creation time
+	// +kubebuilder:validation:Minimum=0
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// Delete tags created more than this duration ago (e.g. `720h`)
+	OlderThan *metav1.Duration `json:"olderThan,omitempty"`
+	// Regular expressions of tags to apply the policy to. If empty, all tags are included.
+	IncludeTags []string `json:"includeTags,omitempty"`
+	// Regular expressions of tags not to apply the policy to
+	ExcludeTags []string `json:"excludeTags,omitempty"`
+	// Cron schedule to run the policy periodically (e.g. `0 3 * * *`).
+	// The policy is also run once whenever its spec is changed.
+	Schedule string `json:"schedule,omitempty"`
+	// If true, candidate tags are only reported and not deleted
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// RetentionCandidate is a tag which is (or would be, in dry-run) deleted by the policy
+type RetentionCandidate struct {
+	// Repository name
+	Repository string `json:"repository"`
+	// Tag name
+	Tag string `json:"tag"`
+	// Created time of the tag
+	CreatedAt metav1.Time `json:"createdAt,omitempty"`
+}
+
+// RetentionReport is a result of a run of the policy
+type RetentionReport struct {
+	// StartTime is the time when the run is started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the run is completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// DryRun is true if candidates were not deleted
+	DryRun bool `json:"dryRun,omitempty"`
+	// Repositories is the number of repositories evaluated
+	Repositories int32 `json:"repositories,omitempty"`
+	// Protected is the number of tags which match the rules but are kept, since they are signed or used by running pods
+	Protected int32 `json:"protected,omitempty"`
+	// Candidates are tags which are deleted, or would be deleted in dry-run
+	Candidates []RetentionCandidate `json:"candidates,omitempty"`
+}
+
+// RetentionPolicyStatus defines the observed state of RetentionPolicy
+type RetentionPolicyStatus struct {
+	// State is a state of the run triggered by the last spec change
+	State RegistryJobState `json:"state,omitempty"`
+	// Message is a message for the run (normally an error string)
+	Message string `json:"message,omitempty"`
+	// CompletionTime is the time when the run triggered by the last spec change is completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// ObservedGeneration is the generation of the spec which the run is triggered for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastReport is the report of the last run, either triggered by a spec change or scheduled
+	LastReport *RetentionReport `json:"lastReport,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=retention
+// +kubebuilder:printcolumn:name="REGISTRY",type=string,JSONPath=`.spec.registry`
+// +kubebuilder:printcolumn:name="SCHEDULE",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="DRYRUN",type=boolean,JSONPath=`.spec.dryRun`
+// +kubebuilder:printcolumn:name="STATE",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RetentionPolicy is the Schema for the retentionpolicies API
+type RetentionPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RetentionPolicySpec   `json:"spec,omitempty"`
+	Status RetentionPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RetentionPolicyList contains a list of RetentionPolicy
+type RetentionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RetentionPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RetentionPolicy{}, &RetentionPolicyList{})
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TLSConfig is settings to use certificates not issued by the operator.
+// Only one of secretRef and issuerRef can be set.
+type TLSConfig struct {
+	// Secret of type kubernetes.io/tls in the same namespace to use as is.
+	// If it has ca.crt, the operator trusts it to connect to the server.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+	// cert-manager issuer to request a Certificate from. The operator creates the Certificate with the server's SANs.
+	IssuerRef *CertIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// CertIssuerRef is a reference to cert-manager Issuer or ClusterIssuer
+type CertIssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+	// Kind of the issuer (default: Issuer)
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer (default: cert-manager.io)
+	Group string `json:"group,omitempty"`
+}
+
+// IsOperatorIssued returns true if the operator issues the certificate by itself
+func (t *TLSConfig) IsOperatorIssued() bool {
+	return t == nil || (t.SecretRef == nil && t.IssuerRef == nil)
+}
@@ -23,6 +23,8 @@ package v1
 import (
 	"github.com/operator-framework/operator-lib/status"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -71,6 +73,111 @@ func (in *Authorizer) DeepCopy() *Authorizer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPVC) DeepCopyInto(out *BackupPVC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPVC.
+func (in *BackupPVC) DeepCopy() *BackupPVC {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProgress) DeepCopyInto(out *BackupProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProgress.
+func (in *BackupProgress) DeepCopy() *BackupProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorage) DeepCopyInto(out *BackupStorage) {
+	*out = *in
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(BackupPVC)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Storage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorage.
+func (in *BackupStorage) DeepCopy() *BackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertIssuerRef) DeepCopyInto(out *CertIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertIssuerRef.
+func (in *CertIssuerRef) DeepCopy() *CertIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	if in.LastRenewal != nil {
+		in, out := &in.LastRenewal, &out.LastRenewal
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentReplicas) DeepCopyInto(out *ComponentReplicas) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentReplicas.
+func (in *ComponentReplicas) DeepCopy() *ComponentReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CreatePvc) DeepCopyInto(out *CreatePvc) {
 	*out = *in
@@ -91,6 +198,25 @@ func (in *CreatePvc) DeepCopy() *CreatePvc {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialRotationStatus) DeepCopyInto(out *CredentialRotationStatus) {
+	*out = *in
+	if in.LastRotation != nil {
+		in, out := &in.LastRotation, &out.LastRotation
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialRotationStatus.
+func (in *CredentialRotationStatus) DeepCopy() *CredentialRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExistPvc) DeepCopyInto(out *ExistPvc) {
 	*out = *in
@@ -106,6 +232,31 @@ func (in *ExistPvc) DeepCopy() *ExistPvc {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureOptions) DeepCopyInto(out *ExposureOptions) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureOptions.
+func (in *ExposureOptions) DeepCopy() *ExposureOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalRegistry) DeepCopyInto(out *ExternalRegistry) {
 	*out = *in
@@ -203,6 +354,87 @@ func (in *ExternalRegistryStatus) DeepCopy() *ExternalRegistryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectionReport) DeepCopyInto(out *GarbageCollectionReport) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.ReclaimedBytes != nil {
+		in, out := &in.ReclaimedBytes, &out.ReclaimedBytes
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectionReport.
+func (in *GarbageCollectionReport) DeepCopy() *GarbageCollectionReport {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectionReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectionStatus) DeepCopyInto(out *GarbageCollectionStatus) {
+	*out = *in
+	if in.LastScheduledTime != nil {
+		in, out := &in.LastScheduledTime, &out.LastScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastReport != nil {
+		in, out := &in.LastReport, &out.LastReport
+		*out = new(GarbageCollectionReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectionStatus.
+func (in *GarbageCollectionStatus) DeepCopy() *GarbageCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRef) DeepCopyInto(out *GatewayRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRef.
+func (in *GatewayRef) DeepCopy() *GatewayRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthProbeStatus) DeepCopyInto(out *HealthProbeStatus) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthProbeStatus.
+func (in *HealthProbeStatus) DeepCopy() *HealthProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageInfo) DeepCopyInto(out *ImageInfo) {
 	*out = *in
@@ -218,6 +450,62 @@ func (in *ImageInfo) DeepCopy() *ImageInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePlatform) DeepCopyInto(out *ImagePlatform) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePlatform.
+func (in *ImagePlatform) DeepCopy() *ImagePlatform {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePlatform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullSecretDistribution) DeepCopyInto(out *ImagePullSecretDistribution) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullSecretDistribution.
+func (in *ImagePullSecretDistribution) DeepCopy() *ImagePullSecretDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullSecretDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullSecretDistributionStatus) DeepCopyInto(out *ImagePullSecretDistributionStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullSecretDistributionStatus.
+func (in *ImagePullSecretDistributionStatus) DeepCopy() *ImagePullSecretDistributionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullSecretDistributionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageReplicate) DeepCopyInto(out *ImageReplicate) {
 	*out = *in
@@ -656,6 +944,37 @@ func (in *ImageVersion) DeepCopy() *ImageVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVersionStatus) DeepCopyInto(out *ImageVersionStatus) {
+	*out = *in
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]ImagePlatform, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastPulledAt != nil {
+		in, out := &in.LastPulledAt, &out.LastPulledAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVersionStatus.
+func (in *ImageVersionStatus) DeepCopy() *ImageVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ingress) DeepCopyInto(out *Ingress) {
 	*out = *in
@@ -822,10 +1141,16 @@ func (in *NotarySigner) DeepCopy() *NotarySigner {
 func (in *NotarySpec) DeepCopyInto(out *NotarySpec) {
 	*out = *in
 	out.AuthConfig = in.AuthConfig
+	in.ExposureOptions.DeepCopyInto(&out.ExposureOptions)
 	in.PersistentVolumeClaim.DeepCopyInto(&out.PersistentVolumeClaim)
 	in.Server.DeepCopyInto(&out.Server)
 	in.Signer.DeepCopyInto(&out.Signer)
 	in.DB.DeepCopyInto(&out.DB)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotarySpec.
@@ -848,6 +1173,13 @@ func (in *NotaryStatus) DeepCopyInto(out *NotaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotaryStatus.
@@ -861,54 +1193,247 @@ func (in *NotaryStatus) DeepCopy() *NotaryStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Registry) DeepCopyInto(out *Registry) {
+func (in *ProxyCacheStats) DeepCopyInto(out *ProxyCacheStats) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Registry.
-func (in *Registry) DeepCopy() *Registry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyCacheStats.
+func (in *ProxyCacheStats) DeepCopy() *ProxyCacheStats {
 	if in == nil {
 		return nil
 	}
-	out := new(Registry)
+	out := new(ProxyCacheStats)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Registry) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyStatus) DeepCopyInto(out *ProxyStatus) {
+	*out = *in
+	out.Blobs = in.Blobs
+	out.Manifests = in.Manifests
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyStatus.
+func (in *ProxyStatus) DeepCopy() *ProxyStatus {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ProxyStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistryCronJob) DeepCopyInto(out *RegistryCronJob) {
+func (in *QuotaStatus) DeepCopyInto(out *QuotaStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCronJob.
-func (in *RegistryCronJob) DeepCopy() *RegistryCronJob {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaStatus.
+func (in *QuotaStatus) DeepCopy() *QuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistryCronJob)
+	out := new(QuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RegistryCronJob) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Registry) DeepCopyInto(out *Registry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Registry.
+func (in *Registry) DeepCopy() *Registry {
+	if in == nil {
+		return nil
+	}
+	out := new(Registry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Registry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryAutoscaling) DeepCopyInto(out *RegistryAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryAutoscaling.
+func (in *RegistryAutoscaling) DeepCopy() *RegistryAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryBackup) DeepCopyInto(out *RegistryBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryBackup.
+func (in *RegistryBackup) DeepCopy() *RegistryBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryBackupList) DeepCopyInto(out *RegistryBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RegistryBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryBackupList.
+func (in *RegistryBackupList) DeepCopy() *RegistryBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryBackupSpec) DeepCopyInto(out *RegistryBackupSpec) {
+	*out = *in
+	in.Storage.DeepCopyInto(&out.Storage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryBackupSpec.
+func (in *RegistryBackupSpec) DeepCopy() *RegistryBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryBackupStatus) DeepCopyInto(out *RegistryBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	out.Progress = in.Progress
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryBackupStatus.
+func (in *RegistryBackupStatus) DeepCopy() *RegistryBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryCredentialRotation) DeepCopyInto(out *RegistryCredentialRotation) {
+	*out = *in
+	out.Period = in.Period
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCredentialRotation.
+func (in *RegistryCredentialRotation) DeepCopy() *RegistryCredentialRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryCredentialRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryCronJob) DeepCopyInto(out *RegistryCronJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCronJob.
+func (in *RegistryCronJob) DeepCopy() *RegistryCronJob {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryCronJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryCronJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
@@ -1007,6 +1532,16 @@ func (in *RegistryDeployment) DeepCopyInto(out *RegistryDeployment) {
 		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(RegistryAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryDeployment.
@@ -1098,6 +1633,21 @@ func (in *RegistryEvents) DeepCopy() *RegistryEvents {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryGarbageCollection) DeepCopyInto(out *RegistryGarbageCollection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryGarbageCollection.
+func (in *RegistryGarbageCollection) DeepCopy() *RegistryGarbageCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryGarbageCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryJob) DeepCopyInto(out *RegistryJob) {
 	*out = *in
@@ -1251,9 +1801,15 @@ func (in *RegistryList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryNotary) DeepCopyInto(out *RegistryNotary) {
 	*out = *in
+	in.ExposureOptions.DeepCopyInto(&out.ExposureOptions)
 	in.PersistentVolumeClaim.DeepCopyInto(&out.PersistentVolumeClaim)
 	in.Server.DeepCopyInto(&out.Server)
 	in.Signer.DeepCopyInto(&out.Signer)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DB.DeepCopyInto(&out.DB)
 }
 
@@ -1268,122 +1824,170 @@ func (in *RegistryNotary) DeepCopy() *RegistryNotary {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistryPVC) DeepCopyInto(out *RegistryPVC) {
+func (in *RegistryNotificationEndpoint) DeepCopyInto(out *RegistryNotificationEndpoint) {
 	*out = *in
-	if in.Exist != nil {
-		in, out := &in.Exist, &out.Exist
-		*out = new(ExistPvc)
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]RegistryNotificationHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]RegistryNotificationAction, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.Create != nil {
-		in, out := &in.Create, &out.Create
-		*out = new(CreatePvc)
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryNotificationEndpoint.
+func (in *RegistryNotificationEndpoint) DeepCopy() *RegistryNotificationEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryNotificationEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryNotificationHeader) DeepCopyInto(out *RegistryNotificationHeader) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryPVC.
-func (in *RegistryPVC) DeepCopy() *RegistryPVC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryNotificationHeader.
+func (in *RegistryNotificationHeader) DeepCopy() *RegistryNotificationHeader {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistryPVC)
+	out := new(RegistryNotificationHeader)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistrySecret) DeepCopyInto(out *RegistrySecret) {
+func (in *RegistryNotifications) DeepCopyInto(out *RegistryNotifications) {
 	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]RegistryNotificationEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySecret.
-func (in *RegistrySecret) DeepCopy() *RegistrySecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryNotifications.
+func (in *RegistryNotifications) DeepCopy() *RegistryNotifications {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistrySecret)
+	out := new(RegistryNotifications)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistryService) DeepCopyInto(out *RegistryService) {
+func (in *RegistryPVC) DeepCopyInto(out *RegistryPVC) {
 	*out = *in
+	if in.Exist != nil {
+		in, out := &in.Exist, &out.Exist
+		*out = new(ExistPvc)
+		**out = **in
+	}
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(CreatePvc)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryService.
-func (in *RegistryService) DeepCopy() *RegistryService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryPVC.
+func (in *RegistryPVC) DeepCopy() *RegistryPVC {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistryService)
+	out := new(RegistryPVC)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistrySpec) DeepCopyInto(out *RegistrySpec) {
+func (in *RegistryProxy) DeepCopyInto(out *RegistryProxy) {
 	*out = *in
-	in.Notary.DeepCopyInto(&out.Notary)
-	in.RegistryDeployment.DeepCopyInto(&out.RegistryDeployment)
-	out.RegistryService = in.RegistryService
-	in.PersistentVolumeClaim.DeepCopyInto(&out.PersistentVolumeClaim)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySpec.
-func (in *RegistrySpec) DeepCopy() *RegistrySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryProxy.
+func (in *RegistryProxy) DeepCopy() *RegistryProxy {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistrySpec)
+	out := new(RegistryProxy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistryStatus) DeepCopyInto(out *RegistryStatus) {
+func (in *RegistryQuota) DeepCopyInto(out *RegistryQuota) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(status.Conditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MaxRepositories != nil {
+		in, out := &in.MaxRepositories, &out.MaxRepositories
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTagsPerRepository != nil {
+		in, out := &in.MaxTagsPerRepository, &out.MaxTagsPerRepository
+		*out = new(int32)
+		**out = **in
 	}
-	in.PhaseChangedAt.DeepCopyInto(&out.PhaseChangedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryStatus.
-func (in *RegistryStatus) DeepCopy() *RegistryStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryQuota.
+func (in *RegistryQuota) DeepCopy() *RegistryQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistryStatus)
+	out := new(RegistryQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Repository) DeepCopyInto(out *Repository) {
+func (in *RegistryRestore) DeepCopyInto(out *RegistryRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Repository.
-func (in *Repository) DeepCopy() *Repository {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryRestore.
+func (in *RegistryRestore) DeepCopy() *RegistryRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(Repository)
+	out := new(RegistryRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Repository) DeepCopyObject() runtime.Object {
+func (in *RegistryRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1391,13 +1995,390 @@ func (in *Repository) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepositoryList) DeepCopyInto(out *RepositoryList) {
+func (in *RegistryRestoreList) DeepCopyInto(out *RegistryRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Repository, len(*in))
+		*out = make([]RegistryRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryRestoreList.
+func (in *RegistryRestoreList) DeepCopy() *RegistryRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryRestoreSpec) DeepCopyInto(out *RegistryRestoreSpec) {
+	*out = *in
+	in.Storage.DeepCopyInto(&out.Storage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryRestoreSpec.
+func (in *RegistryRestoreSpec) DeepCopy() *RegistryRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryRestoreStatus) DeepCopyInto(out *RegistryRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	out.Progress = in.Progress
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryRestoreStatus.
+func (in *RegistryRestoreStatus) DeepCopy() *RegistryRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrySecret) DeepCopyInto(out *RegistrySecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySecret.
+func (in *RegistrySecret) DeepCopy() *RegistrySecret {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrySecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryService) DeepCopyInto(out *RegistryService) {
+	*out = *in
+	in.ExposureOptions.DeepCopyInto(&out.ExposureOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryService.
+func (in *RegistryService) DeepCopy() *RegistryService {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrySpec) DeepCopyInto(out *RegistrySpec) {
+	*out = *in
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialRotation != nil {
+		in, out := &in.CredentialRotation, &out.CredentialRotation
+		*out = new(RegistryCredentialRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecretDistribution != nil {
+		in, out := &in.ImagePullSecretDistribution, &out.ImagePullSecretDistribution
+		*out = new(ImagePullSecretDistribution)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Notary.DeepCopyInto(&out.Notary)
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(RegistryNotifications)
+		(*in).DeepCopyInto(*out)
+	}
+	in.RegistryDeployment.DeepCopyInto(&out.RegistryDeployment)
+	in.RegistryService.DeepCopyInto(&out.RegistryService)
+	in.PersistentVolumeClaim.DeepCopyInto(&out.PersistentVolumeClaim)
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(RegistryProxy)
+		**out = **in
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(RegistryQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(RegistryGarbageCollection)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySpec.
+func (in *RegistrySpec) DeepCopy() *RegistrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryStatus) DeepCopyInto(out *RegistryStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.PhaseChangedAt.DeepCopyInto(&out.PhaseChangedAt)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]ComponentReplicas, len(*in))
+		copy(*out, *in)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		*out = new(StorageUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(QuotaStatus)
+		**out = **in
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(GarbageCollectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialRotation != nil {
+		in, out := &in.CredentialRotation, &out.CredentialRotation
+		*out = new(CredentialRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecretDistribution != nil {
+		in, out := &in.ImagePullSecretDistribution, &out.ImagePullSecretDistribution
+		*out = new(ImagePullSecretDistributionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthProbe != nil {
+		in, out := &in.HealthProbe, &out.HealthProbe
+		*out = new(HealthProbeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryStatus.
+func (in *RegistryStatus) DeepCopy() *RegistryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryStorage) DeepCopyInto(out *RegistryStorage) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Storage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryStorage.
+func (in *RegistryStorage) DeepCopy() *RegistryStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryUser) DeepCopyInto(out *RegistryUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryUser.
+func (in *RegistryUser) DeepCopy() *RegistryUser {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryUserList) DeepCopyInto(out *RegistryUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RegistryUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryUserList.
+func (in *RegistryUserList) DeepCopy() *RegistryUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryUserSpec) DeepCopyInto(out *RegistryUserSpec) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryUserSpec.
+func (in *RegistryUserSpec) DeepCopy() *RegistryUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryUserStatus) DeepCopyInto(out *RegistryUserStatus) {
+	*out = *in
+	if in.LastUsed != nil {
+		in, out := &in.LastUsed, &out.LastUsed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryUserStatus.
+func (in *RegistryUserStatus) DeepCopy() *RegistryUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Repository) DeepCopyInto(out *Repository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Repository.
+func (in *Repository) DeepCopy() *Repository {
+	if in == nil {
+		return nil
+	}
+	out := new(Repository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Repository) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryList) DeepCopyInto(out *RepositoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Repository, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1409,19 +2390,127 @@ func (in *RepositoryList) DeepCopy() *RepositoryList {
 	if in == nil {
 		return nil
 	}
-	out := new(RepositoryList)
+	out := new(RepositoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPermission) DeepCopyInto(out *RepositoryPermission) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPermission.
+func (in *RepositoryPermission) DeepCopy() *RepositoryPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPermission)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RepositoryList) DeepCopyObject() runtime.Object {
+func (in *RepositoryPermission) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPermissionList) DeepCopyInto(out *RepositoryPermissionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RepositoryPermission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPermissionList.
+func (in *RepositoryPermissionList) DeepCopy() *RepositoryPermissionList {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPermissionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryPermissionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPermissionSpec) DeepCopyInto(out *RepositoryPermissionSpec) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]RepositoryAction, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPermissionSpec.
+func (in *RepositoryPermissionSpec) DeepCopy() *RepositoryPermissionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPermissionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryQuota) DeepCopyInto(out *RepositoryQuota) {
+	*out = *in
+	if in.MaxTags != nil {
+		in, out := &in.MaxTags, &out.MaxTags
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryQuota.
+func (in *RepositoryQuota) DeepCopy() *RepositoryQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 	*out = *in
@@ -1432,6 +2521,11 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(RepositoryQuota)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositorySpec.
@@ -1444,6 +2538,28 @@ func (in *RepositorySpec) DeepCopy() *RepositorySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]ImageVersionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatus.
+func (in *RepositoryStatus) DeepCopy() *RepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequestRecord) DeepCopyInto(out *RequestRecord) {
 	*out = *in
@@ -1459,6 +2575,190 @@ func (in *RequestRecord) DeepCopy() *RequestRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionCandidate) DeepCopyInto(out *RetentionCandidate) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionCandidate.
+func (in *RetentionCandidate) DeepCopy() *RetentionCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicy) DeepCopyInto(out *RetentionPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicy.
+func (in *RetentionPolicy) DeepCopy() *RetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RetentionPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicyList) DeepCopyInto(out *RetentionPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RetentionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicyList.
+func (in *RetentionPolicyList) DeepCopy() *RetentionPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RetentionPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicySpec) DeepCopyInto(out *RetentionPolicySpec) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OlderThan != nil {
+		in, out := &in.OlderThan, &out.OlderThan
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.IncludeTags != nil {
+		in, out := &in.IncludeTags, &out.IncludeTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeTags != nil {
+		in, out := &in.ExcludeTags, &out.ExcludeTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicySpec.
+func (in *RetentionPolicySpec) DeepCopy() *RetentionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicyStatus) DeepCopyInto(out *RetentionPolicyStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastReport != nil {
+		in, out := &in.LastReport, &out.LastReport
+		*out = new(RetentionReport)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicyStatus.
+func (in *RetentionPolicyStatus) DeepCopy() *RetentionPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionReport) DeepCopyInto(out *RetentionReport) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]RetentionCandidate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionReport.
+func (in *RetentionReport) DeepCopy() *RetentionReport {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Storage) DeepCopyInto(out *S3Storage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Storage.
+func (in *S3Storage) DeepCopy() *S3Storage {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Storage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScanResult) DeepCopyInto(out *ScanResult) {
 	*out = *in
@@ -1652,6 +2952,47 @@ func (in *SourceRecord) DeepCopy() *SourceRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsage) DeepCopyInto(out *StorageUsage) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsage.
+func (in *StorageUsage) DeepCopy() *StorageUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertIssuerRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrustKey) DeepCopyInto(out *TrustKey) {
 	*out = *in
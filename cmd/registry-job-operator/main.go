@@ -26,8 +26,10 @@ import (
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	backuphandler "github.com/tmax-cloud/registry-operator/controllers/backupctl/handler"
 	exreghandler "github.com/tmax-cloud/registry-operator/controllers/exregctl/handler"
 	replhandler "github.com/tmax-cloud/registry-operator/controllers/replicatectl/handler"
+	retentionhandler "github.com/tmax-cloud/registry-operator/controllers/retentionctl/handler"
 	"github.com/tmax-cloud/registry-operator/pkg/scheduler"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -83,6 +85,14 @@ func main() {
 		setupLog.Error(err, "unable to register handler", "handler", "ImageReplicate")
 		os.Exit(1)
 	}
+	if err := backuphandler.RegisterHandler(mgr, s); err != nil {
+		setupLog.Error(err, "unable to register handler", "handler", "RegistryBackup")
+		os.Exit(1)
+	}
+	if err := retentionhandler.RegisterHandler(mgr, s); err != nil {
+		setupLog.Error(err, "unable to register handler", "handler", "RetentionPolicy")
+		os.Exit(1)
+	}
 
 	if err = (&controllers.RegistryJobReconciler{
 		Client:    mgr.GetClient(),
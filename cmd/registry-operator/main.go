@@ -30,6 +30,7 @@ import (
 	"github.com/tmax-cloud/registry-operator/pkg/apiserver"
 
 	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
 	regmgr "github.com/tmax-cloud/registry-operator/pkg/manager"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -39,6 +40,7 @@ import (
 
 	tmaxiov1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/controllers"
+	"github.com/tmax-cloud/registry-operator/controllers/certctl"
 	"github.com/tmax-cloud/registry-operator/server"
 	// +kubebuilder:scaffold:imports
 )
@@ -72,6 +74,11 @@ func main() {
 
 	ctrl.SetLogger(createDailyRotateLogger("/var/log/registry-operator/operator.log"))
 
+	if err := auth.ValidateConfig(); err != nil {
+		setupLog.Error(err, "invalid token service config")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -85,9 +92,10 @@ func main() {
 	}
 
 	if err = (&controllers.RegistryReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Registry"),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Registry"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("registry-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Registry")
 		os.Exit(1)
@@ -148,8 +156,48 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ImageReplicate")
 		os.Exit(1)
 	}
+	if err = (&controllers.RegistryBackupReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("RegistryBackup"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryBackup")
+		os.Exit(1)
+	}
+	if err = (&controllers.RegistryRestoreReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("RegistryRestore"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryRestore")
+		os.Exit(1)
+	}
+	if err = (&controllers.RegistryUserReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("RegistryUser"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryUser")
+		os.Exit(1)
+	}
+	if err = (&controllers.RetentionPolicyReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("RetentionPolicy"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RetentionPolicy")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err = mgr.Add(&certctl.RootCARotator{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("certctl").WithName("RootCARotator"),
+	}); err != nil {
+		setupLog.Error(err, "unable to add root ca rotator")
+		os.Exit(1)
+	}
+
 	// API Server
 	apiServer := apiserver.New()
 	go apiServer.Start()
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/backup"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewBackupHandler returns a new handler to back up registry
+func NewBackupHandler(k8sClient client.Client, scheme *runtime.Scheme) *BackupHandler {
+	return &BackupHandler{
+		k8sClient: k8sClient,
+		scheme:    scheme,
+	}
+}
+
+// BackupHandler contains objects to use in handle function
+type BackupHandler struct {
+	k8sClient client.Client
+	scheme    *runtime.Scheme
+}
+
+// Handle backs up images of the registry, its Repository objects and signer keys of its images
+func (h *BackupHandler) Handle(object types.NamespacedName) error {
+	object.Name = strings.TrimPrefix(object.Name, v1.K8sPrefix+schemes.BackupPrefix)
+	regBackup := &v1.RegistryBackup{}
+	if err := h.k8sClient.Get(context.TODO(), object, regBackup); err != nil {
+		logger.Error(err, "failed to get registry backup")
+		return err
+	}
+
+	reg := &v1.Registry{}
+	if err := h.k8sClient.Get(context.TODO(), types.NamespacedName{Name: regBackup.Spec.Registry, Namespace: object.Namespace}, reg); err != nil {
+		logger.Error(err, "failed to get registry")
+		return err
+	}
+	if reg.Status.Phase != string(v1.StatusRunning) {
+		return fmt.Errorf("registry %s is not running", reg.Name)
+	}
+
+	regClient, err := inter.GetClient(h.k8sClient, reg, h.scheme)
+	if err != nil {
+		logger.Error(err, "failed to get registry client")
+		return err
+	}
+
+	store, err := backup.NewStore(h.k8sClient, "RegistryBackup", regBackup, &regBackup.Spec.Storage)
+	if err != nil {
+		logger.Error(err, "failed to get backup storage")
+		return err
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error(err, "failed to close backup storage")
+		}
+	}()
+
+	objects, err := h.collectObjects(reg, regBackup.Spec.IncludeSignerKeys)
+	if err != nil {
+		logger.Error(err, "failed to collect objects")
+		return err
+	}
+
+	original := regBackup.DeepCopy()
+	now := metav1.Now()
+	regBackup.Status.StartTime = &now
+	regBackup.Status.Progress = v1.BackupProgress{}
+	h.patchStatus(regBackup, original)
+
+	progress := v1.BackupProgress{}
+	due := throttle(progressInterval)
+	notify := func(p v1.BackupProgress) {
+		progress = p
+		if !due() {
+			return
+		}
+		original := regBackup.DeepCopy()
+		regBackup.Status.Progress = progress
+		h.patchStatus(regBackup, original)
+	}
+
+	backuper := backup.NewBackuper(regClient, serverHost(reg), store, regBackup.Name, notify)
+	index, checksum, err := backuper.Backup(reg, objects)
+	if err != nil {
+		logger.Error(err, "failed to back up registry", "registry", reg.Name)
+		return err
+	}
+
+	original = regBackup.DeepCopy()
+	regBackup.Status.Checksum = checksum
+	regBackup.Status.Repositories = int32(len(index.Repositories()))
+	regBackup.Status.Progress = progress
+	h.patchStatus(regBackup, original)
+
+	return nil
+}
+
+// collectObjects returns kubernetes objects of the registry to back up.
+// Only user-defined fields are kept, since they are recreated into another registry.
+// Signer keys have private keys, so they are collected only if they are included explicitly.
+func (h *BackupHandler) collectObjects(reg *v1.Registry, includeSignerKeys bool) (map[string]interface{}, error) {
+	backupReg := &v1.Registry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   reg.Name,
+			Labels: reg.Labels,
+		},
+		Spec: reg.Spec,
+	}
+
+	repoList := &v1.RepositoryList{}
+	if err := h.k8sClient.List(context.TODO(), repoList, client.InNamespace(reg.Namespace), client.MatchingLabels{"registry": reg.Name}); err != nil {
+		return nil, err
+	}
+	repos := []v1.Repository{}
+	for _, repo := range repoList.Items {
+		repos = append(repos, v1.Repository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   repo.Name,
+				Labels: repo.Labels,
+			},
+			Spec: repo.Spec,
+		})
+	}
+
+	objects := map[string]interface{}{
+		objectRegistry:     backupReg,
+		objectRepositories: repos,
+	}
+	if !includeSignerKeys {
+		return objects, nil
+	}
+
+	// Targets of signer keys are {registry host/image name: key}
+	keyList := &v1.SignerKeyList{}
+	if err := h.k8sClient.List(context.TODO(), keyList); err != nil {
+		return nil, err
+	}
+	prefix := serverHost(reg) + "/"
+	keys := []v1.SignerKey{}
+	for _, key := range keyList.Items {
+		targets := map[string]v1.TrustKey{}
+		for target, trustKey := range key.Spec.Targets {
+			if strings.HasPrefix(target, prefix) {
+				targets[target] = trustKey
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		keys = append(keys, v1.SignerKey{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: key.Name,
+			},
+			Spec: v1.SignerKeySpec{
+				Root:    key.Spec.Root,
+				Targets: targets,
+			},
+		})
+	}
+
+	objects[objectSignerKeys] = keys
+
+	return objects, nil
+}
+
+func (h *BackupHandler) patchStatus(regBackup, original *v1.RegistryBackup) {
+	if err := h.k8sClient.Status().Patch(context.TODO(), regBackup, client.MergeFrom(original)); err != nil {
+		logger.Error(err, "failed to patch status", "name", regBackup.Name)
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/scheduler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = log.Log.WithName("backup-handler")
+
+const (
+	// progressInterval is the minimum interval between progress updates of status
+	progressInterval = 5 * time.Second
+
+	objectRegistry     = "registry"
+	objectRepositories = "repositories"
+	objectSignerKeys   = "signerkeys"
+)
+
+func RegisterHandler(mgr ctrl.Manager, s *scheduler.Scheduler) error {
+	backup := NewBackupHandler(mgr.GetClient(), mgr.GetScheme())
+	if err := s.RegisterHandler(v1.JobTypeRegistryBackup, backup); err != nil {
+		logger.Error(err, "unable to register handler", "type", v1.JobTypeRegistryBackup)
+		return err
+	}
+	restore := NewRestoreHandler(mgr.GetClient(), mgr.GetScheme())
+	if err := s.RegisterHandler(v1.JobTypeRegistryRestore, restore); err != nil {
+		logger.Error(err, "unable to register handler", "type", v1.JobTypeRegistryRestore)
+		return err
+	}
+	return nil
+}
+
+// throttle returns a function which returns true at most once in the interval
+func throttle(interval time.Duration) func() bool {
+	last := time.Time{}
+	return func() bool {
+		if time.Since(last) < interval {
+			return false
+		}
+		last = time.Now()
+		return true
+	}
+}
+
+// serverHost returns host of the registry server url
+func serverHost(reg *v1.Registry) string {
+	host := strings.TrimPrefix(reg.Status.ServerURL, "http://")
+	return strings.TrimPrefix(host, "https://")
+}
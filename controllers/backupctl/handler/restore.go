@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/backup"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// registryRunningTimeout is the timeout for a registry created by restore to be running
+const registryRunningTimeout = 10 * time.Minute
+
+// NewRestoreHandler returns a new handler to restore registry
+func NewRestoreHandler(k8sClient client.Client, scheme *runtime.Scheme) *RestoreHandler {
+	return &RestoreHandler{
+		k8sClient: k8sClient,
+		scheme:    scheme,
+	}
+}
+
+// RestoreHandler contains objects to use in handle function
+type RestoreHandler struct {
+	k8sClient client.Client
+	scheme    *runtime.Scheme
+}
+
+// Handle restores images, Repository objects and signer keys from the backup into the registry.
+// The registry is created with the backed up spec if it does not exist.
+func (h *RestoreHandler) Handle(object types.NamespacedName) error {
+	object.Name = strings.TrimPrefix(object.Name, v1.K8sPrefix+schemes.RestorePrefix)
+	regRestore := &v1.RegistryRestore{}
+	if err := h.k8sClient.Get(context.TODO(), object, regRestore); err != nil {
+		logger.Error(err, "failed to get registry restore")
+		return err
+	}
+
+	store, err := backup.NewStore(h.k8sClient, "RegistryRestore", regRestore, &regRestore.Spec.Storage)
+	if err != nil {
+		logger.Error(err, "failed to get backup storage")
+		return err
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error(err, "failed to close backup storage")
+		}
+	}()
+
+	index, checksum, err := backup.ReadIndex(store, regRestore.Spec.Backup)
+	if err != nil {
+		logger.Error(err, "failed to read backup index", "backup", regRestore.Spec.Backup)
+		return err
+	}
+
+	original := regRestore.DeepCopy()
+	now := metav1.Now()
+	regRestore.Status.StartTime = &now
+	regRestore.Status.Checksum = checksum
+	regRestore.Status.Progress = v1.BackupProgress{}
+	h.patchStatus(regRestore, original)
+
+	reg, created, err := h.getOrCreateRegistry(regRestore, store, index)
+	if err != nil {
+		logger.Error(err, "failed to get registry", "registry", regRestore.Spec.Registry)
+		return err
+	}
+	if created {
+		original := regRestore.DeepCopy()
+		regRestore.Status.RegistryCreated = true
+		h.patchStatus(regRestore, original)
+	}
+
+	regClient, err := inter.GetClient(h.k8sClient, reg, h.scheme)
+	if err != nil {
+		logger.Error(err, "failed to get registry client")
+		return err
+	}
+
+	progress, verified := v1.BackupProgress{}, int32(0)
+	due := throttle(progressInterval)
+	notify := func(p v1.BackupProgress, v int32) {
+		progress, verified = p, v
+		if !due() {
+			return
+		}
+		original := regRestore.DeepCopy()
+		regRestore.Status.Progress = progress
+		regRestore.Status.VerifiedContents = verified
+		h.patchStatus(regRestore, original)
+	}
+
+	restorer := backup.NewRestorer(regClient, serverHost(reg), store, regRestore.Spec.Backup, index, notify)
+	if err := restorer.Restore(); err != nil {
+		logger.Error(err, "failed to restore registry", "registry", reg.Name)
+		return err
+	}
+
+	if err := h.restoreRepositories(reg, store, regRestore.Spec.Backup, index); err != nil {
+		logger.Error(err, "failed to restore repositories")
+		return err
+	}
+	if err := h.restoreSignerKeys(reg, store, regRestore.Spec.Backup, index); err != nil {
+		logger.Error(err, "failed to restore signer keys")
+		return err
+	}
+
+	original = regRestore.DeepCopy()
+	regRestore.Status.Progress = progress
+	regRestore.Status.VerifiedContents = verified
+	h.patchStatus(regRestore, original)
+
+	return nil
+}
+
+// getOrCreateRegistry returns the running registry to restore into. It returns true if the registry is created.
+func (h *RestoreHandler) getOrCreateRegistry(regRestore *v1.RegistryRestore, store backup.Store, index *backup.Index) (*v1.Registry, bool, error) {
+	created := false
+	reg := &v1.Registry{}
+	key := types.NamespacedName{Name: regRestore.Spec.Registry, Namespace: regRestore.Namespace}
+	if err := h.k8sClient.Get(context.TODO(), key, reg); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, false, err
+		}
+
+		if err := backup.ReadObject(store, regRestore.Spec.Backup, index, objectRegistry, reg); err != nil {
+			return nil, false, err
+		}
+		reg.Name = key.Name
+		reg.Namespace = key.Namespace
+		logger.Info("create registry from backup", "namespace", reg.Namespace, "name", reg.Name)
+		if err := h.k8sClient.Create(context.TODO(), reg); err != nil {
+			return nil, false, err
+		}
+		created = true
+	}
+
+	if err := wait.PollImmediate(5*time.Second, registryRunningTimeout, func() (bool, error) {
+		if err := h.k8sClient.Get(context.TODO(), key, reg); err != nil {
+			return false, err
+		}
+		return reg.Status.Phase == string(v1.StatusRunning), nil
+	}); err != nil {
+		return nil, created, fmt.Errorf("registry %s is not running: %s", key.Name, err.Error())
+	}
+
+	return reg, created, nil
+}
+
+// restoreRepositories recreates Repository objects of the registry.
+// Repositories may already be created by the registry server while images are pushed,
+// so backed up versions and quota are merged into them.
+func (h *RestoreHandler) restoreRepositories(reg *v1.Registry, store backup.Store, name string, index *backup.Index) error {
+	repos := []v1.Repository{}
+	if err := backup.ReadObject(store, name, index, objectRepositories, &repos); err != nil {
+		return err
+	}
+
+	for _, backupRepo := range repos {
+		repoName := schemes.RepositoryName(backupRepo.Spec.Name, reg.Name)
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			repo := &v1.Repository{}
+			if err := h.k8sClient.Get(context.TODO(), types.NamespacedName{Name: repoName, Namespace: reg.Namespace}, repo); err != nil {
+				if !errors.IsNotFound(err) {
+					return err
+				}
+
+				repo = schemes.Repository(reg, backupRepo.Spec.Name, nil)
+				repo.Spec.Versions = backupRepo.Spec.Versions
+				repo.Spec.Quota = backupRepo.Spec.Quota
+				if err := controllerutil.SetControllerReference(reg, repo, h.scheme); err != nil {
+					return err
+				}
+				return h.k8sClient.Create(context.TODO(), repo)
+			}
+
+			mergeVersions(repo, backupRepo.Spec.Versions)
+			if repo.Spec.Quota == nil {
+				repo.Spec.Quota = backupRepo.Spec.Quota
+			}
+			return h.k8sClient.Update(context.TODO(), repo)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeVersions adds backed up versions which do not exist, and keeps signers of existing ones
+func mergeVersions(repo *v1.Repository, versions []v1.ImageVersion) {
+	for _, version := range versions {
+		found := false
+		for i, v := range repo.Spec.Versions {
+			if v.Version != version.Version {
+				continue
+			}
+			found = true
+			if v.Signer == "" {
+				repo.Spec.Versions[i].Signer = version.Signer
+			}
+			break
+		}
+		if !found {
+			repo.Spec.Versions = append(repo.Spec.Versions, version)
+		}
+	}
+}
+
+// restoreSignerKeys restores target keys of the images, replacing the backed up registry host with the new one,
+// if they are backed up
+func (h *RestoreHandler) restoreSignerKeys(reg *v1.Registry, store backup.Store, name string, index *backup.Index) error {
+	// signer keys are not backed up unless they are included explicitly
+	if _, ok := index.Objects[objectSignerKeys]; !ok {
+		return nil
+	}
+
+	keys := []v1.SignerKey{}
+	if err := backup.ReadObject(store, name, index, objectSignerKeys, &keys); err != nil {
+		return err
+	}
+
+	oldPrefix, newPrefix := index.Host+"/", serverHost(reg)+"/"
+	for _, backupKey := range keys {
+		targets := map[string]v1.TrustKey{}
+		for target, trustKey := range backupKey.Spec.Targets {
+			targets[newPrefix+strings.TrimPrefix(target, oldPrefix)] = trustKey
+		}
+
+		key := &v1.SignerKey{}
+		if err := h.k8sClient.Get(context.TODO(), types.NamespacedName{Name: backupKey.Name}, key); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+
+			key = &v1.SignerKey{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: backupKey.Name,
+				},
+				Spec: v1.SignerKeySpec{
+					Root:    backupKey.Spec.Root,
+					Targets: targets,
+				},
+			}
+			if err := h.k8sClient.Create(context.TODO(), key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		patchKey := key.DeepCopy()
+		if patchKey.Spec.Targets == nil {
+			patchKey.Spec.Targets = map[string]v1.TrustKey{}
+		}
+		for target, trustKey := range targets {
+			if _, ok := patchKey.Spec.Targets[target]; !ok {
+				patchKey.Spec.Targets[target] = trustKey
+			}
+		}
+		if err := h.k8sClient.Patch(context.TODO(), patchKey, client.MergeFrom(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *RestoreHandler) patchStatus(regRestore, original *v1.RegistryRestore) {
+	if err := h.k8sClient.Status().Patch(context.TODO(), regRestore, client.MergeFrom(original)); err != nil {
+		logger.Error(err, "failed to patch status", "name", regRestore.Name)
+	}
+}
@@ -0,0 +1,72 @@
+package backupctl
+
+import (
+	"context"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/admission"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// JobStatus is the status of a backup or restore which follows its registry job
+type JobStatus struct {
+	State          regv1.RegistryJobState
+	Message        string
+	CompletionTime *metav1.Time
+}
+
+// ValidateStorage checks if exactly one of backup storages is set
+func ValidateStorage(storage *regv1.BackupStorage) error {
+	return admission.ValidateBackupStorage(storage, field.NewPath("spec", "storage")).ToAggregate()
+}
+
+// GetOrCreateJob gets the registry job, or creates it owned by the owner if it does not exist
+func GetOrCreateJob(c client.Client, scheme *runtime.Scheme, owner metav1.Object, job *regv1.RegistryJob) (*regv1.RegistryJob, error) {
+	err := c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, job)
+	if err == nil {
+		return job, nil
+	}
+	if !k8serr.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err := controllerutil.SetControllerReference(owner, job, scheme); err != nil {
+		return nil, err
+	}
+	if err := c.Create(context.TODO(), job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// StatusOf returns the status which follows the registry job
+func StatusOf(job *regv1.RegistryJob) JobStatus {
+	status := JobStatus{
+		State:   job.Status.State,
+		Message: job.Status.Message,
+	}
+	if status.State == "" {
+		status.State = regv1.RegistryJobStatePending
+	}
+	if status.State == regv1.RegistryJobStateCompleted || status.State == regv1.RegistryJobStateFailed {
+		status.CompletionTime = job.Status.CompletionTime
+		if status.CompletionTime == nil {
+			now := metav1.Now()
+			status.CompletionTime = &now
+		}
+	}
+
+	return status
+}
+
+// IsFinished returns true if the state is completed or failed
+func IsFinished(state regv1.RegistryJobState) bool {
+	return state == regv1.RegistryJobStateCompleted || state == regv1.RegistryJobStateFailed
+}
@@ -0,0 +1,98 @@
+package certctl
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/regctl"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RootCARotator rolls the system root ca over before it expires.
+// Certificates signed by the replaced root ca are reissued by registry and notary controllers.
+type RootCARotator struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+// Start checks the system root ca every cert.check_period until stop is closed
+func (r *RootCARotator) Start(stop <-chan struct{}) error {
+	for {
+		if err := r.rotate(); err != nil {
+			r.Log.Error(err, "failed to rotate root ca")
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(config.Config.GetDuration(config.ConfigCertCheckPeriod)):
+		}
+	}
+}
+
+// NeedLeaderElection makes only the leader rotate root ca
+func (r *RootCARotator) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *RootCARotator) rotate() error {
+	secret, err := certs.GetSystemRootCASecret(r.Client)
+	if err != nil {
+		return err
+	}
+
+	original := secret.DeepCopy()
+	changed, err := certs.RotateRootCA(secret, time.Now(),
+		config.Config.GetDuration(config.ConfigRootCARenewBefore), config.Config.GetDuration(config.ConfigRootCAOverlap))
+	if err != nil {
+		return err
+	}
+	if changed {
+		r.Log.Info("root ca is rotated", "promoteAt", secret.Annotations[certs.NextRootCAPromoteAtAnnotation])
+		if err := r.Client.Patch(context.TODO(), secret, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+
+	// trust is propagated every time, in case the previous propagation failed
+	bundle, _ := certs.CAData(secret)
+	if err := certs.UpdateRootCABundles(r.Client, bundle); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	return r.refreshRegistries()
+}
+
+// refreshRegistries makes registries trust tokens signed by any root ca in the bundle
+func (r *RootCARotator) refreshRegistries() error {
+	regs := &regv1.RegistryList{}
+	if err := r.Client.List(context.TODO(), regs); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range regs.Items {
+		reg := &regs.Items[i]
+		if reg.Status.Phase != regv1.StatusRunning {
+			continue
+		}
+		logger := r.Log.WithValues("namespace", reg.Namespace, "name", reg.Name)
+		if err := auth.GetProvider().Prepare(context.TODO(), r.Client, reg); err != nil {
+			logger.Error(err, "failed to prepare token authentication")
+			continue
+		}
+		if err := regctl.RestartDeployment(r.Client, reg, now); err != nil {
+			logger.Error(err, "failed to restart registry")
+		}
+	}
+
+	return nil
+}
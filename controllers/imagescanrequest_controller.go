@@ -43,6 +43,7 @@ import (
 
 	tmaxiov1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/controllers/scanctl"
+	"github.com/tmax-cloud/registry-operator/internal/admission"
 	"github.com/tmax-cloud/registry-operator/internal/common/certs"
 	"github.com/tmax-cloud/registry-operator/internal/common/config"
 	"github.com/tmax-cloud/registry-operator/internal/utils/k8s/secrethelper"
@@ -423,20 +424,7 @@ func convertReport(reports *clair.VulnerabilityReport, threshold int) (ret tmaxi
 }
 
 func validate(instance *tmaxiov1.ImageScanRequest) error {
-	if instance.Spec.MaxFixable < 0 {
-		return fmt.Errorf(".Spec.MaxFixable cannot be negative")
-	}
-
-	for _, target := range instance.Spec.ScanTargets {
-		for _, image := range target.Images {
-			imagepath := path.Join(target.RegistryURL, image)
-			_, err := registry.ParseImage(imagepath)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return admission.ValidateImageScanRequest(instance).ToAggregate()
 }
 
 func (r *ImageScanRequestReconciler) mutate(o *tmaxiov1.ImageScanRequest) error {
@@ -62,6 +62,7 @@ type ImageSignRequestReconciler struct {
 // +kubebuilder:rbac:groups=tmax.io,resources=signerkeys/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apiregistration.k8s.io,resourceNames=v1.registry.tmax.io,resources=apiservices,verbs=get;update;patch
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resourceNames=registry-operator-webhook-cfg,resources=mutatingwebhookconfigurations,verbs=get;update;patch
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resourceNames=registry-operator-validating-webhook-cfg,resources=validatingwebhookconfigurations,verbs=get;update;patch
 // +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -44,6 +45,8 @@ type NotaryReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=tmax.io,resources=notaries,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=tmax.io,resources=notaries/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;tlsroutes,verbs=get;list;watch;create;update;patch;delete
 
 func (r *NotaryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
@@ -67,12 +70,13 @@ func (r *NotaryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return reconcile.Result{}, nil
 	}
 
-	if err = r.handleAllSubresources(notary); err != nil {
+	requeueAfter, err := r.handleAllSubresources(notary)
+	if err != nil {
 		r.Log.Error(err, "Subresource creation failed")
 		return reconcile.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *NotaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -86,12 +90,12 @@ func (r *NotaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *NotaryReconciler) handleAllSubresources(notary *regv1.Notary) error { // if want to requeue, return true
+func (r *NotaryReconciler) handleAllSubresources(notary *regv1.Notary) (time.Duration, error) { // returns when to check certificates again
 	subResourceLogger := r.Log.WithValues("SubResource.Namespace", notary.Namespace, "SubResource.Name", notary.Name)
 	subResourceLogger.Info("Creating all Subresources")
 
 	var requeueErr error = nil
-	collectSubController := collectNotarySubController(notary.Spec.ServiceType)
+	collectSubController := collectNotarySubController(notary)
 	patchNotary := notary.DeepCopy() // Target to Patch object
 
 	defer func() {
@@ -108,20 +112,25 @@ func (r *NotaryReconciler) handleAllSubresources(notary *regv1.Notary) error { /
 		// Check if subresource is handled.
 		if err := sctl.Handle(r.Client, notary, patchNotary, r.Scheme); err != nil {
 			subResourceLogger.Error(err, "Got an error in creating subresource ")
-			return err
+			return 0, err
 		}
 
 		// Check if subresource is ready.
 		if err := sctl.Ready(r.Client, notary, patchNotary, false); err != nil {
 			subResourceLogger.Error(err, "Got an error in checking ready")
-			return err
+			return 0, err
 		}
 	}
 	if requeueErr != nil {
-		return requeueErr
+		return 0, requeueErr
 	}
 
-	return nil
+	requeueAfter, err := notaryctl.RenewCertificates(r.Client, notary, patchNotary, time.Now())
+	if err != nil {
+		subResourceLogger.Error(err, "Got an error in renewing certificates")
+	}
+
+	return requeueAfter, nil
 }
 
 func (r *NotaryReconciler) update(origin, target *regv1.Notary) error {
@@ -164,20 +173,27 @@ func (r *NotaryReconciler) exceptStatus(origin, target *regv1.Notary) {
 	target.Status.SignerLoadBalancerIP = ""
 }
 
-func collectNotarySubController(serviceType regv1.NotaryServiceType) []notaryctl.NotarySubresource {
+func collectNotarySubController(notary *regv1.Notary) []notaryctl.NotarySubresource {
 	collection := []notaryctl.NotarySubresource{}
+	var serverSecret notaryctl.NotarySubresource = &notaryctl.NotaryServerSecret{}
+	if !notary.Spec.TLS.IsOperatorIssued() {
+		serverSecret = &notaryctl.NotaryServerExternalTLS{}
+	}
 	// [TODO] Add Subresources in here
 	collection = append(collection,
 		&notaryctl.NotaryDBPVC{}, &notaryctl.NotaryDBService{},
 		&notaryctl.NotaryServerService{}, &notaryctl.NotarySignerService{},
-		&notaryctl.NotaryServerSecret{},
+		serverSecret,
 		&notaryctl.NotarySignerSecret{},
 		&notaryctl.NotaryDBPod{},
 		&notaryctl.NotaryServerPod{},
 		&notaryctl.NotarySignerPod{},
 	)
-	if serviceType == "Ingress" {
+	switch notary.Spec.ServiceType {
+	case regv1.NotaryServiceTypeIngress:
 		collection = append(collection, &notaryctl.NotaryServerIngress{})
+	case regv1.NotaryServiceTypeGateway:
+		collection = append(collection, &notaryctl.NotaryServerRoute{})
 	}
 
 	return collection
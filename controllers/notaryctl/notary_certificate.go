@@ -0,0 +1,78 @@
+package notaryctl
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RenewCertificates reissues notary server and signer certificates before they expire or after root ca is replaced,
+// and records their expiry in patchNotary's status. Pods of reissued certificates are deleted to be recreated.
+// If notary server's certificate is not issued by the operator, its pod is recreated only when the certificate is replaced.
+// It returns when to check again.
+func RenewCertificates(c client.Client, notary, patchNotary *regv1.Notary, now time.Time) (time.Duration, error) {
+	logger := logf.Log.WithName("notaryctl_certificate").WithValues("Request.Namespace", notary.Namespace, "Request.Name", notary.Name)
+	targets := []struct {
+		secret   string
+		pod      schemes.SubresourceType
+		external bool
+		manifest func() (*corev1.Secret, error)
+	}{
+		{schemes.NotaryServerTLSSecretName(notary), schemes.SubTypeNotaryServerPod, !notary.Spec.TLS.IsOperatorIssued(),
+			func() (*corev1.Secret, error) { return schemes.NotaryServerSecret(notary, c) }},
+		{schemes.SubresourceName(notary, schemes.SubTypeNotarySignerSecret), schemes.SubTypeNotarySignerPod, false,
+			func() (*corev1.Secret, error) { return schemes.NotarySignerSecret(notary, c) }},
+	}
+	patchNotary.Status.Certificates = certs.RetainCertificateStatus(patchNotary.Status.Certificates, targets[0].secret, targets[1].secret)
+
+	var lastErr error
+	for _, t := range targets {
+		secret := &corev1.Secret{}
+		name := t.secret
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: notary.Namespace}, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				lastErr = err
+			}
+			continue
+		}
+
+		var crt *x509.Certificate
+		var renewed bool
+		var err error
+		if t.external {
+			crt, renewed, err = certs.ObserveTLSSecret(secret, patchNotary.Status.Certificates)
+		} else {
+			crt, renewed, err = certs.RenewTLSSecret(c, secret, t.manifest, now)
+		}
+		if crt != nil {
+			certs.SetCertificateStatus(&patchNotary.Status.Certificates, name, crt, renewed, now)
+		}
+		if err != nil {
+			logger.Error(err, "failed to renew certificate", "secret", name)
+			lastErr = err
+			continue
+		}
+		if !renewed {
+			continue
+		}
+
+		logger.Info("certificate is renewed", "secret", name, "notAfter", crt.NotAfter)
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: schemes.SubresourceName(notary, t.pod), Namespace: notary.Namespace}}
+		if err := c.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+			lastErr = err
+		}
+	}
+	patchNotary.Status.Conditions.SetCondition(certs.ExpiringCondition(patchNotary.Status.Certificates, now))
+
+	return certs.NextCheck(patchNotary.Status.Certificates, now), lastErr
+}
@@ -0,0 +1,122 @@
+package notaryctl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NotaryServerExternalTLS handles notary server's tls secret which is not issued by the operator.
+// It is the secret referred by spec.tls.secretRef, or the one issued by cert-manager Certificate for spec.tls.issuerRef.
+type NotaryServerExternalTLS struct {
+	cert   *unstructured.Unstructured
+	logger *utils.RegistryLogger
+}
+
+// Handle is to create or update cert-manager Certificate if issuerRef is set.
+func (nt *NotaryServerExternalTLS) Handle(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, scheme *runtime.Scheme) error {
+	nt.logger = utils.NewRegistryLogger(*nt, notary.Namespace, schemes.NotaryServerTLSSecretName(notary))
+	if notary.Spec.TLS.IssuerRef == nil {
+		return nil
+	}
+
+	if err := nt.get(c, notary); err != nil {
+		if errors.IsNotFound(err) {
+			if err := nt.create(c, notary, patchNotary, scheme); err != nil {
+				nt.logger.Error(err, "create certificate error")
+				return err
+			}
+			return nil
+		}
+		nt.logger.Error(err, "certificate error")
+		return err
+	}
+
+	manifest := schemes.NotaryServerCertificate(notary)
+	if !reflect.DeepEqual(nt.cert.Object["spec"], manifest.Object["spec"]) {
+		nt.logger.Info("Update notary server certificate")
+		origin := nt.cert.DeepCopy()
+		nt.cert.Object["spec"] = manifest.Object["spec"]
+		if err := c.Patch(context.TODO(), nt.cert, client.MergeFrom(origin)); err != nil {
+			nt.logger.Error(err, "update certificate error")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ready is to check if the certificate is issued and the secret is valid, and to set the condition
+func (nt *NotaryServerExternalTLS) Ready(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, useGet bool) error {
+	var err error = nil
+	condition := &status.Condition{
+		Status: corev1.ConditionFalse,
+		Type:   regv1.ConditionTypeNotaryServerSecret,
+	}
+
+	defer utils.SetCondition(err, patchNotary, condition)
+
+	if notary.Spec.TLS.IssuerRef != nil {
+		if err = nt.get(c, notary); err != nil {
+			condition.Message = err.Error()
+			return err
+		}
+		if ready, message := certs.CertificateReady(nt.cert); !ready {
+			err = fmt.Errorf("certificate is not ready: %s", message)
+			condition.Message = err.Error()
+			return err
+		}
+	}
+
+	secret := &corev1.Secret{}
+	if err = c.Get(context.TODO(), types.NamespacedName{Name: schemes.NotaryServerTLSSecretName(notary), Namespace: notary.Namespace}, secret); err != nil {
+		condition.Message = err.Error()
+		return err
+	}
+	if err = certs.ValidateTLSSecret(secret); err != nil {
+		condition.Message = err.Error()
+		return err
+	}
+
+	nt.logger.Info("Ready")
+	condition.Status = corev1.ConditionTrue
+	return nil
+}
+
+func (nt *NotaryServerExternalTLS) create(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, scheme *runtime.Scheme) error {
+	manifest := schemes.NotaryServerCertificate(notary)
+	if err := controllerutil.SetControllerReference(notary, manifest, scheme); err != nil {
+		nt.logger.Error(err, "SetOwnerReference Failed")
+		return err
+	}
+
+	nt.logger.Info("Create notary server certificate")
+	return c.Create(context.TODO(), manifest)
+}
+
+func (nt *NotaryServerExternalTLS) get(c client.Client, notary *regv1.Notary) error {
+	nt.logger = utils.NewRegistryLogger(*nt, notary.Namespace, schemes.NotaryServerTLSSecretName(notary))
+	nt.cert = schemes.NotaryServerCertificate(notary)
+
+	return c.Get(context.TODO(), types.NamespacedName{Name: nt.cert.GetName(), Namespace: nt.cert.GetNamespace()}, nt.cert)
+}
+
+func (nt *NotaryServerExternalTLS) delete(c client.Client, patchNotary *regv1.Notary) error {
+	if nt.cert == nil {
+		return nil
+	}
+	return c.Delete(context.TODO(), nt.cert)
+}
@@ -0,0 +1,121 @@
+package notaryctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NotaryServerRoute handles Gateway API route to notary server for Gateway service type
+type NotaryServerRoute struct {
+	route  *unstructured.Unstructured
+	logger *utils.RegistryLogger
+}
+
+// Handle is to create or update notary server route.
+func (nt *NotaryServerRoute) Handle(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, scheme *runtime.Scheme) error {
+	if err := nt.get(c, notary); err != nil {
+		if errors.IsNotFound(err) {
+			if err := nt.create(c, notary, patchNotary, scheme); err != nil {
+				nt.logger.Error(err, "create route error")
+				return err
+			}
+			return nil
+		}
+		nt.logger.Error(err, "route error")
+		return err
+	}
+
+	manifest := schemes.NotaryServerRoute(notary)
+	if ingress.RouteChanged(nt.route, manifest) {
+		nt.logger.Info("Update notary server route")
+		origin := nt.route.DeepCopy()
+		nt.route.Object["spec"] = manifest.Object["spec"]
+		if err := c.Patch(context.TODO(), nt.route, client.MergeFrom(origin)); err != nil {
+			nt.logger.Error(err, "update route error")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ready is to check if the route is accepted by the gateway and to set the condition
+func (nt *NotaryServerRoute) Ready(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, useGet bool) error {
+	var err error = nil
+	condition := &status.Condition{
+		Status: corev1.ConditionFalse,
+		Type:   regv1.ConditionTypeNotaryServerRoute,
+	}
+
+	defer utils.SetCondition(err, patchNotary, condition)
+
+	if useGet || nt.route == nil {
+		if err = nt.get(c, notary); err != nil {
+			condition.Message = err.Error()
+			return err
+		}
+	}
+
+	if accepted, message := ingress.RouteAccepted(nt.route); !accepted {
+		err = fmt.Errorf("route is not accepted: %s", message)
+		condition.Message = err.Error()
+		return err
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(nt.route.Object, "spec", "hostnames")
+	if len(hostnames) > 0 {
+		patchNotary.Status.NotaryURL = "https://" + hostnames[0]
+	}
+
+	nt.logger.Info("Ready")
+	condition.Status = corev1.ConditionTrue
+	return nil
+}
+
+func (nt *NotaryServerRoute) create(c client.Client, notary *regv1.Notary, patchNotary *regv1.Notary, scheme *runtime.Scheme) error {
+	manifest := schemes.NotaryServerRoute(notary)
+	if err := controllerutil.SetControllerReference(notary, manifest, scheme); err != nil {
+		nt.logger.Error(err, "SetOwnerReference Failed")
+		return err
+	}
+
+	nt.logger.Info("Create notary server route")
+	if err := c.Create(context.TODO(), manifest); err != nil {
+		return err
+	}
+	nt.route = manifest
+
+	return nil
+}
+
+func (nt *NotaryServerRoute) get(c client.Client, notary *regv1.Notary) error {
+	nt.logger = utils.NewRegistryLogger(*nt, notary.Namespace, schemes.SubresourceName(notary, schemes.SubTypeNotaryServerRoute))
+	manifest := schemes.NotaryServerRoute(notary)
+	if manifest == nil {
+		return fmt.Errorf("notary server's hostname is not known yet")
+	}
+
+	nt.route = &unstructured.Unstructured{}
+	nt.route.SetGroupVersionKind(manifest.GroupVersionKind())
+	return c.Get(context.TODO(), types.NamespacedName{Name: manifest.GetName(), Namespace: manifest.GetNamespace()}, nt.route)
+}
+
+func (nt *NotaryServerRoute) delete(c client.Client, patchNotary *regv1.Notary) error {
+	if nt.route == nil {
+		return nil
+	}
+	return c.Delete(context.TODO(), nt.route)
+}
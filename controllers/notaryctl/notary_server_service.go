@@ -7,6 +7,7 @@ import (
 
 	"github.com/operator-framework/operator-lib/status"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
 	"github.com/tmax-cloud/registry-operator/internal/schemes"
 	"github.com/tmax-cloud/registry-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
@@ -58,7 +59,8 @@ func (nt *NotaryServerService) Ready(c client.Client, notary *regv1.Notary, patc
 	}
 
 	notary.Status.ServerClusterIP = nt.svc.Spec.ClusterIP
-	if notary.Spec.ServiceType == regv1.NotaryServiceType("LoadBalancer") {
+	switch notary.Spec.ServiceType {
+	case regv1.NotaryServiceTypeLoadBalancer:
 		lb := nt.svc.Status.LoadBalancer
 		if len(lb.Ingress) == 0 {
 			return fmt.Errorf("loadbalancer ip is not assigned")
@@ -66,6 +68,18 @@ func (nt *NotaryServerService) Ready(c client.Client, notary *regv1.Notary, patc
 
 		notary.Status.ServerLoadBalancerIP = lb.Ingress[0].IP
 		patchNotary.Status.NotaryURL = "https://" + notary.Status.ServerLoadBalancerIP + ":" + strconv.Itoa(schemes.NotaryServerDefaultPort)
+	case regv1.NotaryServiceTypeNodePort:
+		var addr string
+		if addr, err = ingress.GetNodeAddress(c); err != nil {
+			return err
+		}
+		if len(nt.svc.Spec.Ports) == 0 || nt.svc.Spec.Ports[0].NodePort == 0 {
+			return fmt.Errorf("node port is not assigned")
+		}
+
+		// notary server certificate needs the url in the same reconciliation
+		notary.Status.NotaryURL = fmt.Sprintf("https://%s:%d", addr, nt.svc.Spec.Ports[0].NodePort)
+		patchNotary.Status.NotaryURL = notary.Status.NotaryURL
 	}
 
 	nt.logger.Info("Ready")
@@ -18,7 +18,7 @@ func UpdateNotaryStatus(c client.Client, not *regv1.Notary) (bool, error) {
 	falseTypes := []status.ConditionType{}
 	checkTypes := getCheckTypes(not)
 
-	if len(not.Status.Conditions) != len(checkTypes) {
+	if countCheckConditions(not.Status.Conditions) != len(checkTypes) {
 		if err := initNotaryStatus(c, not); err != nil {
 			return false, err
 		}
@@ -63,7 +63,7 @@ func initNotaryStatus(c client.Client, not *regv1.Notary) error {
 	}
 
 	for _, t := range not.Status.Conditions {
-		if !contains(checkTypes, t.Type) {
+		if !contains(checkTypes, t.Type) && !contains(informationalTypes, t.Type) {
 			not.Status.Conditions.RemoveCondition(t.Type)
 		}
 	}
@@ -77,6 +77,21 @@ func initNotaryStatus(c client.Client, not *regv1.Notary) error {
 	return nil
 }
 
+// informationalTypes are conditions which are not about subresources
+var informationalTypes = []status.ConditionType{
+	regv1.ConditionTypeCertificateExpiring,
+}
+
+func countCheckConditions(conditions status.Conditions) int {
+	n := 0
+	for _, c := range conditions {
+		if !contains(informationalTypes, c.Type) {
+			n++
+		}
+	}
+	return n
+}
+
 func getCheckTypes(not *regv1.Notary) []status.ConditionType {
 	checkTypes := []status.ConditionType{
 		regv1.ConditionTypeNotaryDBPod,
@@ -90,8 +105,11 @@ func getCheckTypes(not *regv1.Notary) []status.ConditionType {
 		regv1.ConditionTypeNotarySignerService,
 	}
 
-	if not.Spec.ServiceType == "Ingress" {
+	switch not.Spec.ServiceType {
+	case regv1.NotaryServiceTypeIngress:
 		checkTypes = append(checkTypes, regv1.ConditionTypeNotaryServerIngress)
+	case regv1.NotaryServiceTypeGateway:
+		checkTypes = append(checkTypes, regv1.ConditionTypeNotaryServerRoute)
 	}
 
 	return checkTypes
@@ -0,0 +1,81 @@
+package regctl
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonCertificateRenewed is the event reason when a certificate issued by the operator is reissued
+	EventReasonCertificateRenewed = "CertificateRenewed"
+	// EventReasonCertificateRenewalFailed is the event reason when a certificate cannot be reissued
+	EventReasonCertificateRenewalFailed = "CertificateRenewalFailed"
+)
+
+// RenewCertificate reissues registry's tls certificate before it expires or after root ca is replaced,
+// and records its expiry in registry status. If the certificate is not issued by the operator, it only restarts registry
+// when the certificate is replaced. It returns whether the certificate is reissued or replaced and when to check again.
+func RenewCertificate(c client.Client, reg *regv1.Registry, now time.Time) (bool, time.Duration, error) {
+	secret := &corev1.Secret{}
+	name := schemes.RegistryTLSSecretName(reg)
+	reg.Status.Certificates = certs.RetainCertificateStatus(reg.Status.Certificates, name)
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: reg.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+
+	var crt *x509.Certificate
+	var renewed bool
+	var err error
+	if reg.Spec.TLS.IsOperatorIssued() {
+		crt, renewed, err = certs.RenewTLSSecret(c, secret, func() (*corev1.Secret, error) {
+			return schemes.TlsSecret(reg, c)
+		}, now)
+	} else {
+		crt, renewed, err = certs.ObserveTLSSecret(secret, reg.Status.Certificates)
+	}
+	if crt != nil {
+		certs.SetCertificateStatus(&reg.Status.Certificates, name, crt, renewed, now)
+	}
+	reg.Status.Conditions.SetCondition(certs.ExpiringCondition(reg.Status.Certificates, now))
+	if err != nil {
+		return false, 0, err
+	}
+
+	if renewed {
+		if err := RestartDeployment(c, reg, now); err != nil {
+			return true, 0, err
+		}
+	}
+
+	return renewed, certs.NextCheck(reg.Status.Certificates, now), nil
+}
+
+// RestartDeployment rolls registry pods out to load reissued certificates or credentials
+func RestartDeployment(c client.Client, reg *regv1.Registry, now time.Time) error {
+	ctx := context.TODO()
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+		return err
+	}
+
+	origin := deploy.DeepCopy()
+	if deploy.Spec.Template.Annotations == nil {
+		deploy.Spec.Template.Annotations = map[string]string{}
+	}
+	deploy.Spec.Template.Annotations[certs.RenewedAtAnnotation] = now.UTC().Format(time.RFC3339)
+
+	return c.Patch(ctx, deploy, client.MergeFrom(origin))
+}
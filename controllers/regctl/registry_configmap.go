@@ -1,9 +1,14 @@
 package regctl
 
 import (
+	"bytes"
 	"context"
+	"reflect"
+
 	"github.com/go-logr/logr"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/operator-framework/operator-lib/status"
@@ -17,18 +22,21 @@ import (
 type RegistryConfigMap struct {
 	c            client.Client
 	manifest     func() (interface{}, error)
+	secretData   func() (map[string][]byte, error)
 	cond         status.ConditionType
 	requirements []status.ConditionType
 	logger       logr.Logger
 }
 
-// NewRegistryConfigMap creates new registry configmap controller
-func NewRegistryConfigMap(client client.Client, manifest func() (interface{}, error), cond status.ConditionType, logger logr.Logger) *RegistryConfigMap {
+// NewRegistryConfigMap creates new registry configmap controller.
+// secretData returns the settings of config.yml which are kept in credential secret instead of the configmap.
+func NewRegistryConfigMap(client client.Client, manifest func() (interface{}, error), secretData func() (map[string][]byte, error), cond status.ConditionType, logger logr.Logger) *RegistryConfigMap {
 	return &RegistryConfigMap{
-		c:        client,
-		manifest: manifest,
-		cond:     cond,
-		logger:   logger.WithName("Configmap"),
+		c:          client,
+		manifest:   manifest,
+		secretData: secretData,
+		cond:       cond,
+		logger:     logger.WithName("Configmap"),
 	}
 }
 
@@ -58,6 +66,14 @@ func (r *RegistryConfigMap) ReconcileByConditionStatus(reg *regv1.Registry) (boo
 		return false, err
 	}
 	manifest := m.(*corev1.ConfigMap)
+	data, err := r.secretData()
+	if err != nil {
+		return false, err
+	}
+	if err = syncConfigSecret(r.c, reg, data); err != nil {
+		return false, err
+	}
+
 	cm := &corev1.ConfigMap{}
 	if err = r.c.Get(ctx, types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, cm); err != nil {
 		if errors.IsNotFound(err) {
@@ -70,10 +86,14 @@ func (r *RegistryConfigMap) ReconcileByConditionStatus(reg *regv1.Registry) (boo
 		return false, err
 	}
 
+	if _, err = syncConfigMap(r.c, cm, manifest); err != nil {
+		return false, err
+	}
 	if _, exist := cm.Data["config.yml"]; !exist {
 		err = regv1.MakeRegistryError("NotReady")
 		return false, err
 	}
+	reg.Status.ConfigHash = schemes.ConfigHash(cm, data)
 
 	reg.Status.Conditions.SetCondition(
 		status.Condition{
@@ -85,6 +105,99 @@ func (r *RegistryConfigMap) ReconcileByConditionStatus(reg *regv1.Registry) (boo
 	return false, nil
 }
 
+// EventReasonConfigChanged is the event reason when registry's config.yml is changed and registry pods roll out
+const EventReasonConfigChanged = "ConfigChanged"
+
+// ApplyConfig updates registry's configmap to the manifest and the settings in credential secret to secretData,
+// and records the hash of them. If they are changed, registry pods roll out to load them. It returns whether the pods roll out.
+func ApplyConfig(c client.Client, reg *regv1.Registry, manifest *corev1.ConfigMap, secretData map[string][]byte) (bool, error) {
+	ctx := context.TODO()
+	if err := syncConfigSecret(c, reg, secretData); err != nil {
+		return false, err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, cm); err != nil {
+		return false, err
+	}
+	changed, err := syncConfigMap(c, cm, manifest)
+	if err != nil {
+		return false, err
+	}
+	hash := schemes.ConfigHash(cm, secretData)
+	reg.Status.ConfigHash = hash
+	if len(reg.Spec.CustomConfigYml) != 0 {
+		return false, nil
+	}
+
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+		return false, err
+	}
+	origin := deploy.DeepCopy()
+	// deployments created before the operator's CA was mounted are given it when the CA is set
+	mounted := len(secretData[schemes.CredentialSecretOperatorCA]) > 0 && schemes.MountOperatorCA(reg, &deploy.Spec.Template.Spec)
+	// pods created before the hash was recorded roll out only when config.yml is changed
+	current, ok := deploy.Spec.Template.Annotations[schemes.ConfigHashAnnotation]
+	if !mounted && (current == hash || (!ok && !changed)) {
+		return false, nil
+	}
+
+	if deploy.Spec.Template.Annotations == nil {
+		deploy.Spec.Template.Annotations = map[string]string{}
+	}
+	deploy.Spec.Template.Annotations[schemes.ConfigHashAnnotation] = hash
+	if err := c.Patch(ctx, deploy, client.MergeFrom(origin)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// syncConfigMap updates the data of configmap to the manifest's and returns whether it is changed
+func syncConfigMap(c client.Client, cm, manifest *corev1.ConfigMap) (bool, error) {
+	if reflect.DeepEqual(cm.Data, manifest.Data) {
+		return false, nil
+	}
+
+	origin := cm.DeepCopy()
+	cm.Data = manifest.Data
+	if err := c.Patch(context.TODO(), cm, client.MergeFrom(origin)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// syncConfigSecret updates the settings of config.yml in credential secret to data. Keys missing in data are removed.
+func syncConfigSecret(c client.Client, reg *regv1.Registry, data map[string][]byte) error {
+	secret, err := getCredentialSecret(c, reg)
+	if err != nil {
+		return err
+	}
+
+	original := secret.DeepCopy()
+	changed := false
+	for _, key := range schemes.ConfigSecretKeys {
+		value, ok := data[key]
+		current, exist := secret.Data[key]
+		switch {
+		case ok && (!exist || !bytes.Equal(current, value)):
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[key] = value
+			changed = true
+		case !ok && exist:
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return c.Patch(context.TODO(), secret, client.MergeFrom(original))
+}
+
 func (r *RegistryConfigMap) Require(cond status.ConditionType) ResourceController {
 	r.requirements = append(r.requirements, cond)
 	return r
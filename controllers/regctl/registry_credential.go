@@ -0,0 +1,238 @@
+package regctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonCredentialRotated is the event reason when login password is rotated by schedule
+	EventReasonCredentialRotated = "CredentialRotated"
+	// EventReasonCredentialChanged is the event reason when login password is changed to the given one
+	EventReasonCredentialChanged = "CredentialChanged"
+
+	credentialPasswordSize       = 16
+	defaultCredentialGracePeriod = time.Hour
+)
+
+// LoginPassword returns registry's login password given by passwordSecretRef or loginPassword
+func LoginPassword(c client.Client, reg *regv1.Registry) (string, error) {
+	ref := reg.Spec.PasswordSecretRef
+	if ref == nil {
+		if reg.Spec.LoginPassword == "" {
+			return "", fmt.Errorf("registry's loginPassword or passwordSecretRef field missing")
+		}
+		return reg.Spec.LoginPassword, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: reg.Namespace}, secret); err != nil {
+		return "", err
+	}
+	password := secret.Data[ref.Key]
+	if len(password) == 0 {
+		return "", fmt.Errorf("secret %s has no %s", ref.Name, ref.Key)
+	}
+
+	return string(password), nil
+}
+
+// CurrentPassword returns registry's login password in use, which is in the credential secret.
+// If the credential secret is not created yet, the given password is returned.
+func CurrentPassword(c client.Client, reg *regv1.Registry) (string, error) {
+	secret, err := getCredentialSecret(c, reg)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return LoginPassword(c, reg)
+		}
+		return "", err
+	}
+
+	return string(secret.Data[schemes.CredentialSecretPassword]), nil
+}
+
+// NotificationToken returns the token which registry sends events to the operator with.
+// Credential secrets created before the token was introduced are given a new one.
+func NotificationToken(c client.Client, reg *regv1.Registry) (string, error) {
+	secret, err := getCredentialSecret(c, reg)
+	if err != nil {
+		return "", err
+	}
+	if token := secret.Data[schemes.CredentialSecretNotificationToken]; len(token) > 0 {
+		return string(token), nil
+	}
+
+	token, err := schemes.NotificationToken(reg)
+	if err != nil {
+		return "", err
+	}
+	original := secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[schemes.CredentialSecretNotificationToken] = []byte(token)
+	if err := c.Patch(context.TODO(), secret, client.MergeFrom(original)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// BackfillHTTPSecret gives credential secrets created before the shared http secret was introduced a new one.
+// It returns whether the secret is added, since registry pods generated their own and need to roll out to share it.
+func BackfillHTTPSecret(c client.Client, reg *regv1.Registry) (bool, error) {
+	secret, err := getCredentialSecret(c, reg)
+	if err != nil {
+		return false, err
+	}
+	if len(secret.Data[schemes.CredentialSecretHTTPSecret]) > 0 {
+		return false, nil
+	}
+
+	httpSecret, err := utils.RandomSecret(32)
+	if err != nil {
+		return false, err
+	}
+	original := secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[schemes.CredentialSecretHTTPSecret] = []byte(httpSecret)
+	if err := c.Patch(context.TODO(), secret, client.MergeFrom(original)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CredentialRotationSchedule returns whether login password rotation is due and the next rotation time
+func CredentialRotationSchedule(reg *regv1.Registry, now time.Time) (bool, time.Time) {
+	period := reg.Spec.CredentialRotation.Period.Duration
+	last := reg.CreationTimestamp.Time
+	if isCredentialRotated(reg) {
+		last = reg.Status.CredentialRotation.LastRotation.Time
+	}
+
+	next := last.Add(period)
+	if next.After(now) {
+		return false, next
+	}
+
+	return true, now.Add(period)
+}
+
+// ReconcileCredential rotates login password if rotation is due, or changes it to the given one if it is modified.
+// Once rotated, the generated password replaces the given one. It returns the event reason if the password is changed,
+// and the duration until the next rotation(0 if rotation is disabled).
+func ReconcileCredential(c client.Client, reg *regv1.Registry, now time.Time) (string, time.Duration, error) {
+	var after time.Duration
+	if reg.Spec.CredentialRotation != nil {
+		// pods using the previous password would break in the middle of rotation
+		if !auth.KeepsPreviousPassword() {
+			return "", 0, fmt.Errorf("credential rotation requires builtin token provider")
+		}
+		due, next := CredentialRotationSchedule(reg, now)
+		after = next.Sub(now)
+		if due {
+			password, err := utils.RandomSecret(credentialPasswordSize)
+			if err != nil {
+				return "", after, err
+			}
+			if err := RollCredential(c, reg, password); err != nil {
+				return "", after, err
+			}
+			if reg.Status.CredentialRotation == nil {
+				reg.Status.CredentialRotation = &regv1.CredentialRotationStatus{}
+			}
+			reg.Status.CredentialRotation.LastRotation = &metav1.Time{Time: now}
+			return EventReasonCredentialRotated, after, nil
+		}
+		if isCredentialRotated(reg) {
+			return "", after, nil
+		}
+	}
+
+	password, err := LoginPassword(c, reg)
+	if err != nil {
+		return "", after, err
+	}
+	current, err := CurrentPassword(c, reg)
+	if err != nil {
+		return "", after, err
+	}
+	if password == current {
+		return "", after, nil
+	}
+	if err := RollCredential(c, reg, password); err != nil {
+		return "", after, err
+	}
+
+	return EventReasonCredentialChanged, after, nil
+}
+
+// RollCredential changes login password of the registry. The auth backend is updated first, and then
+// the credential and dockerconfigjson secrets. The previous password is kept in the credential secret during
+// grace period, so that clients which are still using it keep working through the changeover.
+func RollCredential(c client.Client, reg *regv1.Registry, password string) error {
+	ctx := context.TODO()
+	secret, err := getCredentialSecret(c, reg)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.GetProvider().ProvisionUser(ctx, reg, reg.Spec.LoginID, password); err != nil {
+		return err
+	}
+
+	grace := defaultCredentialGracePeriod
+	if rotation := reg.Spec.CredentialRotation; rotation != nil && rotation.GracePeriod != nil {
+		grace = rotation.GracePeriod.Duration
+	}
+	original := secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[schemes.CredentialSecretPreviousPassword] = secret.Data[schemes.CredentialSecretPassword]
+	secret.Data[schemes.CredentialSecretPreviousPasswordExpiry] = []byte(time.Now().Add(grace).Format(time.RFC3339))
+	secret.Data[schemes.CredentialSecretPassword] = []byte(password)
+	if err := c.Patch(ctx, secret, client.MergeFrom(original)); err != nil {
+		return err
+	}
+
+	dcj := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDCJSecret), Namespace: reg.Namespace}, dcj); err != nil {
+		if errors.IsNotFound(err) {
+			// created with the current password later
+			return nil
+		}
+		return err
+	}
+	originalDCJ := dcj.DeepCopy()
+	dcj.Data = schemes.DCJSecret(reg, password).Data
+
+	return c.Patch(ctx, dcj, client.MergeFrom(originalDCJ))
+}
+
+func isCredentialRotated(reg *regv1.Registry) bool {
+	return reg.Status.CredentialRotation != nil && reg.Status.CredentialRotation.LastRotation != nil
+}
+
+func getCredentialSecret(c client.Client, reg *regv1.Registry) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryOpaqueSecret), Namespace: reg.Namespace}, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
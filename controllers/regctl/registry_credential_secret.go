@@ -2,9 +2,13 @@ package regctl
 
 import (
 	"context"
+	"time"
+
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/operator-lib/status"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	corev1 "k8s.io/api/core/v1"
@@ -59,6 +63,11 @@ func (r *RegistryCrendentialSecret) ReconcileByConditionStatus(reg *regv1.Regist
 	if err = r.c.Get(ctx, types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, secretOpaque); err != nil {
 		if errors.IsNotFound(err) {
 			r.logger.Info("not found. create new one.")
+			// login user is provisioned in the auth backend before its credential is created
+			password := string(manifest.Data[schemes.CredentialSecretPassword])
+			if err = auth.GetProvider().ProvisionUser(ctx, reg, reg.Spec.LoginID, password); err != nil {
+				return false, err
+			}
 			if err = r.c.Create(ctx, manifest); err != nil {
 				return false, err
 			}
@@ -66,6 +75,17 @@ func (r *RegistryCrendentialSecret) ReconcileByConditionStatus(reg *regv1.Regist
 		}
 		return false, err
 	}
+	backfilled, err := BackfillHTTPSecret(r.c, reg)
+	if err != nil {
+		return false, err
+	}
+	if backfilled {
+		r.logger.Info("http secret added. restart registry pods to share it.")
+		if err = RestartDeployment(r.c, reg, time.Now()); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		err = nil
+	}
 	reg.Status.Conditions.SetCondition(
 		status.Condition{
 			Type:    r.cond,
@@ -0,0 +1,115 @@
+package regctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCredentialRotationSchedule(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	reg := &regv1.Registry{}
+	reg.CreationTimestamp = metav1.Time{Time: created}
+	reg.Spec.CredentialRotation = &regv1.RegistryCredentialRotation{Period: metav1.Duration{Duration: 24 * time.Hour}}
+
+	// the first rotation is scheduled from creation
+	due, next := CredentialRotationSchedule(reg, created.Add(time.Hour))
+	assert.Equal(t, false, due)
+	assert.Equal(t, created.Add(24*time.Hour), next)
+
+	now := created.Add(25 * time.Hour)
+	due, next = CredentialRotationSchedule(reg, now)
+	assert.Equal(t, true, due)
+	assert.Equal(t, now.Add(24*time.Hour), next)
+
+	// and later ones from the last rotation
+	reg.Status.CredentialRotation = &regv1.CredentialRotationStatus{LastRotation: &metav1.Time{Time: now}}
+	due, next = CredentialRotationSchedule(reg, now.Add(time.Hour))
+	assert.Equal(t, false, due)
+	assert.Equal(t, now.Add(24*time.Hour), next)
+}
+
+func TestReconcileCredential(t *testing.T) {
+	provider := config.Config.GetString(config.ConfigTokenServiceProvider)
+	defer config.Config.Set(config.ConfigTokenServiceProvider, provider)
+	config.Config.Set(config.ConfigTokenServiceProvider, "builtin")
+
+	now := time.Now()
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	reg.CreationTimestamp = metav1.Time{Time: now.Add(-48 * time.Hour)}
+	reg.Spec.LoginID = "admin"
+	reg.Spec.LoginPassword = "given"
+	reg.Spec.CredentialRotation = &regv1.RegistryCredentialRotation{Period: metav1.Duration{Duration: 24 * time.Hour}}
+
+	secret, err := schemes.CredentialSecret(reg, "given")
+	assert.Equal(t, nil, err)
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, corev1.AddToScheme(scheme))
+	assert.Equal(t, nil, regv1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, secret)
+	key := types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}
+
+	// rotation is due, so a new password is generated and the previous one is kept during grace period
+	reason, after, err := ReconcileCredential(c, reg, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, EventReasonCredentialRotated, reason)
+	assert.Equal(t, 24*time.Hour, after)
+	assert.Equal(t, now, reg.Status.CredentialRotation.LastRotation.Time)
+
+	rotated := &corev1.Secret{}
+	assert.Equal(t, nil, c.Get(context.TODO(), key, rotated))
+	assert.Equal(t, "given", string(rotated.Data[schemes.CredentialSecretPreviousPassword]))
+	assert.NotEqual(t, "given", string(rotated.Data[schemes.CredentialSecretPassword]))
+
+	// the rotated password is not replaced by the given one
+	reason, _, err = ReconcileCredential(c, reg, now.Add(time.Hour))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", reason)
+
+	// rotation is refused with keycloak, which does not accept the previous password
+	config.Config.Set(config.ConfigTokenServiceProvider, "keycloak")
+	_, _, err = ReconcileCredential(c, reg, now.Add(48*time.Hour))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestBackfillHTTPSecret(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	reg.Spec.LoginID = "admin"
+
+	// credential secret created before the http secret was introduced
+	secret, err := schemes.CredentialSecret(reg, "given")
+	assert.Equal(t, nil, err)
+	delete(secret.Data, schemes.CredentialSecretHTTPSecret)
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, corev1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, secret)
+	key := types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}
+
+	backfilled, err := BackfillHTTPSecret(c, reg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, backfilled)
+	updated := &corev1.Secret{}
+	assert.Equal(t, nil, c.Get(context.TODO(), key, updated))
+	httpSecret := updated.Data[schemes.CredentialSecretHTTPSecret]
+	assert.NotEqual(t, 0, len(httpSecret))
+	assert.Equal(t, "given", string(updated.Data[schemes.CredentialSecretPassword]))
+
+	// the added one is kept
+	backfilled, err = BackfillHTTPSecret(c, reg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, backfilled)
+	assert.Equal(t, nil, c.Get(context.TODO(), key, updated))
+	assert.Equal(t, httpSecret, updated.Data[schemes.CredentialSecretHTTPSecret])
+}
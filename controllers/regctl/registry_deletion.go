@@ -0,0 +1,127 @@
+package regctl
+
+import (
+	"context"
+	"strings"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonVolumeSnapshotted is the event reason when registry's pvc is snapshotted before registry is deleted
+	EventReasonVolumeSnapshotted = "VolumeSnapshotted"
+	// EventReasonVolumeRetained is the event reason when registry's pvc is kept after registry is deleted
+	EventReasonVolumeRetained = "VolumeRetained"
+)
+
+// ReleaseData handles registry's data by its deletion policy before registry is deleted.
+// Repositories are deleted first while registry still exists so that their images are not swept,
+// and then registry's pvc is kept, snapshotted or left to be deleted with registry.
+// It returns a message of what is waited for if it is not done yet, and the reason of event to record when it is done.
+func ReleaseData(c client.Client, reg *regv1.Registry) (string, string, error) {
+	if waiting, err := deleteRepositories(c, reg); err != nil || waiting != "" {
+		return waiting, "", err
+	}
+
+	if !reg.Spec.Storage.UsePVC() {
+		return "", "", nil
+	}
+
+	switch reg.Spec.GetDeletionPolicy() {
+	case regv1.DeletionPolicyRetain:
+		if err := orphanPVC(c, reg); err != nil {
+			return "", "", err
+		}
+		return "", EventReasonVolumeRetained, nil
+	case regv1.DeletionPolicySnapshot:
+		ready, err := snapshotPVC(c, reg)
+		if err != nil {
+			return "", "", err
+		}
+		if !ready {
+			return "waiting for volume snapshot to be ready", "", nil
+		}
+		return "", EventReasonVolumeSnapshotted, nil
+	}
+
+	return "", "", nil
+}
+
+// deleteRepositories deletes registry's repositories and returns a message if some of them are not deleted yet
+func deleteRepositories(c client.Client, reg *regv1.Registry) (string, error) {
+	repos := &regv1.RepositoryList{}
+	if err := c.List(context.TODO(), repos, client.InNamespace(reg.Namespace), client.MatchingLabels{"registry": reg.Name}); err != nil {
+		return "", err
+	}
+
+	remaining := 0
+	for i := range repos.Items {
+		repo := &repos.Items[i]
+		if strings.HasPrefix(repo.Name, schemes.ExternalRegistryPrefix) {
+			continue
+		}
+		remaining++
+		if repo.DeletionTimestamp != nil {
+			continue
+		}
+		if err := c.Delete(context.TODO(), repo); err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+	}
+	if remaining > 0 {
+		return "waiting for repositories to be deleted", nil
+	}
+
+	return "", nil
+}
+
+// orphanPVC removes registry's owner reference from the pvc not to be deleted with registry
+func orphanPVC(c client.Client, reg *regv1.Registry) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.RegistryPVCName(reg), Namespace: reg.Namespace}, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	refs := []metav1.OwnerReference{}
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID != reg.UID {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == len(pvc.OwnerReferences) {
+		return nil
+	}
+
+	origin := pvc.DeepCopy()
+	pvc.OwnerReferences = refs
+	return c.Patch(context.TODO(), pvc, client.MergeFrom(origin))
+}
+
+// snapshotPVC takes a snapshot of registry's pvc and returns whether it is ready to use
+func snapshotPVC(c client.Client, reg *regv1.Registry) (bool, error) {
+	manifest := schemes.VolumeSnapshot(reg)
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(manifest.GroupVersionKind())
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: manifest.GetName(), Namespace: manifest.GetNamespace()}, snapshot); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		return false, c.Create(context.TODO(), manifest)
+	}
+
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found && message != "" {
+		return false, regv1.MakeRegistryError("volume snapshot failed: " + message)
+	}
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/operator-lib/status"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
 	appsv1 "k8s.io/api/apps/v1"
 
 	corev1 "k8s.io/api/core/v1"
@@ -68,6 +69,13 @@ func (r *RegistryDeployment) ReconcileByConditionStatus(reg *regv1.Registry) (bo
 		return false, err
 	}
 
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	setComponentReplicas(reg, ComponentRegistry, desired, deployment.Status.ReadyReplicas)
+	reg.Status.ReadOnly = reg.Spec.Proxy != nil || isReadOnlyDeployment(deployment)
+
 	reg.Status.Conditions.SetCondition(
 		status.Condition{
 			Type:    r.cond,
@@ -82,3 +90,14 @@ func (r *RegistryDeployment) Require(cond status.ConditionType) ResourceControll
 	r.requirements = append(r.requirements, cond)
 	return r
 }
+
+// isReadOnlyDeployment returns true if registry is running in read-only maintenance mode
+// by spec, quota or garbage collection
+func isReadOnlyDeployment(deployment *appsv1.Deployment) bool {
+	for _, e := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == schemes.RegistryEnvKeyStorageMaintenance {
+			return true
+		}
+	}
+	return false
+}
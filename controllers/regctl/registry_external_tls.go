@@ -0,0 +1,131 @@
+package regctl
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryExternalTLS contains things to handle tls secret which is not issued by the operator.
+// It is the secret referred by spec.tls.secretRef, or the one issued by cert-manager Certificate for spec.tls.issuerRef.
+type RegistryExternalTLS struct {
+	c client.Client
+	// manifest returns cert-manager Certificate. It is nil if the secret is given.
+	manifest     func() (interface{}, error)
+	cond         status.ConditionType
+	requirements []status.ConditionType
+	logger       logr.Logger
+}
+
+func NewRegistryExternalTLS(client client.Client, manifest func() (interface{}, error), cond status.ConditionType, logger logr.Logger) *RegistryExternalTLS {
+	return &RegistryExternalTLS{
+		c:        client,
+		manifest: manifest,
+		cond:     cond,
+		logger:   logger.WithName("ExternalTLS"),
+	}
+}
+
+func (r *RegistryExternalTLS) ReconcileByConditionStatus(reg *regv1.Registry) (bool, error) {
+	var err error
+	message := ""
+	defer func() {
+		if err != nil {
+			message = err.Error()
+		}
+		if message != "" {
+			reg.Status.Conditions.SetCondition(
+				status.Condition{
+					Type:    r.cond,
+					Status:  corev1.ConditionFalse,
+					Message: message,
+				})
+		}
+	}()
+
+	for _, dep := range r.requirements {
+		if !reg.Status.Conditions.GetCondition(dep).IsTrue() {
+			r.logger.Info(string(r.cond) + " needs " + string(dep))
+			return true, nil
+		}
+	}
+
+	ctx := context.TODO()
+	if r.manifest != nil {
+		var ready bool
+		if ready, message, err = r.reconcileCertificate(ctx); err != nil || !ready {
+			return true, err
+		}
+		message = ""
+	}
+
+	secret := &corev1.Secret{}
+	if err = r.c.Get(ctx, types.NamespacedName{Name: schemes.RegistryTLSSecretName(reg), Namespace: reg.Namespace}, secret); err != nil {
+		return false, err
+	}
+	if err = certs.ValidateTLSSecret(secret); err != nil {
+		return false, err
+	}
+
+	reg.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    r.cond,
+			Status:  corev1.ConditionTrue,
+			Message: "Success",
+		})
+
+	return false, nil
+}
+
+// reconcileCertificate creates or updates cert-manager Certificate and returns whether it is ready
+func (r *RegistryExternalTLS) reconcileCertificate(ctx context.Context) (bool, string, error) {
+	m, err := r.manifest()
+	if err != nil {
+		return false, "", err
+	}
+	manifest := m.(*unstructured.Unstructured)
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(manifest.GroupVersionKind())
+	if err := r.c.Get(ctx, types.NamespacedName{Name: manifest.GetName(), Namespace: manifest.GetNamespace()}, cert); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, "", err
+		}
+		r.logger.Info("certificate not found. create new one.")
+		if err := r.c.Create(ctx, manifest); err != nil {
+			return false, "", err
+		}
+		return false, "waiting for certificate to be issued", nil
+	}
+
+	if !reflect.DeepEqual(cert.Object["spec"], manifest.Object["spec"]) {
+		r.logger.Info("certificate is changed. update it.")
+		origin := cert.DeepCopy()
+		cert.Object["spec"] = manifest.Object["spec"]
+		if err := r.c.Patch(ctx, cert, client.MergeFrom(origin)); err != nil {
+			return false, "", err
+		}
+		return false, "waiting for certificate to be reissued", nil
+	}
+
+	ready, message := certs.CertificateReady(cert)
+	if !ready {
+		return false, "certificate is not ready: " + message, nil
+	}
+	return true, "", nil
+}
+
+func (r *RegistryExternalTLS) Require(cond status.ConditionType) ResourceController {
+	r.requirements = append(r.requirements, cond)
+	return r
+}
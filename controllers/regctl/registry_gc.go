@@ -0,0 +1,286 @@
+package regctl
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonGarbageCollected is the event reason when scheduled garbage collection is succeeded
+	EventReasonGarbageCollected = "GarbageCollected"
+	// EventReasonGarbageCollectionFailed is the event reason when scheduled garbage collection is failed
+	EventReasonGarbageCollectionFailed = "GarbageCollectionFailed"
+
+	// blobsPerDiskUsage is the number of blob paths passed to a du command
+	blobsPerDiskUsage = 100
+)
+
+// GarbageCollectionSchedule returns whether garbage collection is due and the next scheduled time
+func GarbageCollectionSchedule(reg *regv1.Registry, now time.Time) (bool, time.Time, error) {
+	schedule, err := cron.ParseStandard(reg.Spec.GarbageCollection.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	last := reg.CreationTimestamp.Time
+	if reg.Status.GarbageCollection != nil && reg.Status.GarbageCollection.LastScheduledTime != nil {
+		last = reg.Status.GarbageCollection.LastScheduledTime.Time
+	}
+
+	next := schedule.Next(last)
+	if next.After(now) {
+		return false, next, nil
+	}
+
+	return true, schedule.Next(now), nil
+}
+
+// GarbageCollect runs garbage collection in registry pod and returns its report.
+// Unless dry-run, registry is switched to read-only until garbage collection is finished.
+func GarbageCollect(c client.Client, reg *regv1.Registry) *regv1.GarbageCollectionReport {
+	report := &regv1.GarbageCollectionReport{
+		StartTime: metav1.Now(),
+		DryRun:    reg.Spec.GarbageCollection.DryRun,
+	}
+
+	if err := garbageCollect(c, reg, report); err != nil {
+		log.Error(err, "garbage collection failed", "namespace", reg.Namespace, "name", reg.Name)
+		report.Error = err.Error()
+	}
+
+	report.CompletionTime = metav1.Now()
+	report.Duration = report.CompletionTime.Sub(report.StartTime.Time).Round(time.Second).String()
+
+	return report
+}
+
+func garbageCollect(c client.Client, reg *regv1.Registry, report *regv1.GarbageCollectionReport) error {
+	gc := reg.Spec.GarbageCollection
+	if !gc.DryRun {
+		defer leaveMaintenance(c, reg)
+		if err := enterMaintenance(c, reg); err != nil {
+			return err
+		}
+	}
+
+	podName, err := readyPodName(c, reg)
+	if err != nil {
+		return err
+	}
+	cmder := inter.NewCommander(podName, reg.Namespace)
+
+	// dry-run first to find out blobs to be deleted
+	out, err := cmder.GarbageCollect(true, gc.DeleteUntagged)
+	if err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), out.Errbuf.String())
+	}
+	res, err := inter.ParseGarbageCollect(out.Outbuf.String())
+	if err != nil {
+		return err
+	}
+	report.BlobsMarked = int32(res.BlobsMarked)
+	report.BlobsDeleted = int32(res.BlobsEligible)
+	report.ManifestsDeleted = int32(res.ManifestsEligible)
+
+	// size of blobs in other storages is unknown without listing them through the storage's api
+	if reg.Spec.Storage.UsePVC() {
+		size, err := blobsSize(cmder, storageRootDirectory(c, reg), res.EligibleBlobs)
+		if err != nil {
+			log.Error(err, "failed to get size of blobs")
+		} else {
+			report.ReclaimedBytes = &size
+		}
+	}
+
+	if gc.DryRun {
+		return nil
+	}
+
+	out, err = cmder.GarbageCollect(false, gc.DeleteUntagged)
+	if err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), out.Errbuf.String())
+	}
+
+	return nil
+}
+
+// CollectDeletedBlobs runs garbage collection in registry's read-only window to delete blobs of deleted images
+func CollectDeletedBlobs(c client.Client, reg *regv1.Registry) error {
+	defer leaveMaintenance(c, reg)
+	if err := enterMaintenance(c, reg); err != nil {
+		return err
+	}
+
+	podName, err := readyPodName(c, reg)
+	if err != nil {
+		return err
+	}
+	out, err := inter.NewCommander(podName, reg.Namespace).GarbageCollect(false, false)
+	if err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), out.Errbuf.String())
+	}
+
+	return nil
+}
+
+// enterMaintenance switches registry to read-only and waits until all registry pods are read-only,
+// since blobs uploaded during garbage collection may be deleted
+func enterMaintenance(c client.Client, reg *regv1.Registry) error {
+	log.Info("enter read-only maintenance window", "namespace", reg.Namespace, "name", reg.Name)
+	if err := SetDeploymentReadOnly(c, reg, true); err != nil {
+		return err
+	}
+
+	return waitForRollout(c, reg, config.Config.GetDuration(config.ConfigRegistryGCRolloutTimeout))
+}
+
+// leaveMaintenance restores read-only mode by the latest registry spec and quota
+func leaveMaintenance(c client.Client, reg *regv1.Registry) {
+	latest := &regv1.Registry{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace}, latest); err != nil {
+		log.Error(err, "failed to get registry")
+		latest = reg
+	}
+
+	log.Info("leave read-only maintenance window", "namespace", reg.Namespace, "name", reg.Name)
+	if err := SetDeploymentReadOnly(c, latest, latest.Spec.ReadOnly || latest.Status.Quota.IsExceeded()); err != nil {
+		log.Error(err, "failed to switch registry back from read-only")
+	}
+}
+
+// EndInterruptedGarbageCollection ends the read-only window of garbage collection which is recorded in status
+// but not running, e.g. since the operator is restarted in the middle of it. The report of it is recorded as failed.
+func EndInterruptedGarbageCollection(c client.Client, reg *regv1.Registry) error {
+	gc := reg.Status.GarbageCollection
+	log.Info("end read-only maintenance window of interrupted garbage collection", "namespace", reg.Namespace, "name", reg.Name)
+	if err := SetDeploymentReadOnly(c, reg, reg.Spec.ReadOnly || reg.Status.Quota.IsExceeded()); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	gc.LastReport = &regv1.GarbageCollectionReport{
+		StartTime:      *gc.StartTime,
+		CompletionTime: now,
+		Duration:       now.Sub(gc.StartTime.Time).Round(time.Second).String(),
+		DryRun:         reg.Spec.GarbageCollection != nil && reg.Spec.GarbageCollection.DryRun,
+		Error:          "garbage collection is interrupted",
+	}
+	gc.StartTime = nil
+
+	return nil
+}
+
+// waitForRollout waits until all registry pods are replaced with the latest deployment spec
+func waitForRollout(c client.Client, reg *regv1.Registry, timeout time.Duration) error {
+	return wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+			return false, err
+		}
+
+		desired := int32(1)
+		if deploy.Spec.Replicas != nil {
+			desired = *deploy.Spec.Replicas
+		}
+
+		return deploy.Status.ObservedGeneration >= deploy.Generation &&
+			deploy.Status.Replicas == desired &&
+			deploy.Status.UpdatedReplicas == desired &&
+			deploy.Status.AvailableReplicas == desired, nil
+	})
+}
+
+// readyPodName returns name of a ready registry pod which is not terminating
+func readyPodName(c client.Client, reg *regv1.Registry) (string, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(context.TODO(), podList, &client.ListOptions{
+		Namespace: reg.Namespace,
+		LabelSelector: labels.SelectorFromSet(labels.Set(map[string]string{
+			"app":  "registry",
+			"apps": schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment),
+		})),
+	}); err != nil {
+		return "", err
+	}
+
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil && isPodReady(&pod) {
+			return pod.Name, nil
+		}
+	}
+
+	return "", regv1.MakeRegistryError(regv1.PodNotFound)
+}
+
+// storageRootDirectory returns the root directory of registry's filesystem storage in its configmap,
+// or the mount path of the pvc if it is not found
+func storageRootDirectory(c client.Client, reg *regv1.Registry) string {
+	name := schemes.SubresourceName(reg, schemes.SubTypeRegistryConfigmap)
+	if reg.Spec.CustomConfigYml != "" {
+		name = reg.Spec.CustomConfigYml
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: reg.Namespace}, cm); err == nil {
+		if root := schemes.FilesystemRootDirectory(cm); root != "" {
+			return root
+		}
+	}
+
+	return schemes.RegistryMountPath(reg)
+}
+
+// blobsSize returns the total size of blobs stored in filesystem storage
+func blobsSize(cmder *inter.Commander, root string, digests []string) (int64, error) {
+	var total int64
+	for i := 0; i < len(digests); i += blobsPerDiskUsage {
+		paths := []string{}
+		for _, digest := range digests[i:min(i+blobsPerDiskUsage, len(digests))] {
+			paths = append(paths, blobPath(root, digest))
+		}
+
+		out, err := cmder.DiskUsage(paths)
+		if err != nil {
+			return total, err
+		}
+		size, err := inter.ParseDiskUsage(out.Outbuf.String())
+		if err != nil {
+			return total, err
+		}
+		total += size
+	}
+
+	return total, nil
+}
+
+// blobPath returns where the blob is stored in registry's filesystem storage
+func blobPath(root, digest string) string {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || len(parts[1]) < 2 {
+		return path.Join(root, "docker/registry/v2/blobs", digest)
+	}
+
+	return path.Join(root, "docker/registry/v2/blobs", parts[0], parts[1][:2], parts[1], "data")
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package regctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEndInterruptedGarbageCollection(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	start := metav1.NewTime(time.Now().Add(-time.Hour))
+	reg.Status.GarbageCollection = &regv1.GarbageCollectionStatus{StartTime: &start}
+
+	// registry is left read-only by the interrupted garbage collection
+	deploy := &appsv1.Deployment{}
+	deploy.Name, deploy.Namespace = schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), reg.Namespace
+	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "registry",
+		Env:  []corev1.EnvVar{{Name: schemes.RegistryEnvKeyStorageMaintenance, Value: schemes.RegistryEnvValueStorageMaintenance}},
+	}}
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, appsv1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, deploy)
+
+	assert.Equal(t, nil, EndInterruptedGarbageCollection(c, reg))
+	assert.Equal(t, (*metav1.Time)(nil), reg.Status.GarbageCollection.StartTime)
+	assert.Equal(t, start, reg.Status.GarbageCollection.LastReport.StartTime)
+	assert.NotEqual(t, "", reg.Status.GarbageCollection.LastReport.Error)
+
+	latest := &appsv1.Deployment{}
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: deploy.Name, Namespace: deploy.Namespace}, latest))
+	assert.Equal(t, 0, len(latest.Spec.Template.Spec.Containers[0].Env))
+}
@@ -0,0 +1,143 @@
+package regctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	cmhttp "github.com/tmax-cloud/registry-operator/internal/common/http"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonHealthCheckPassed is the event reason when a health condition becomes true
+	EventReasonHealthCheckPassed = "HealthCheckPassed"
+	// EventReasonHealthCheckFailed is the event reason when a health condition becomes false
+	EventReasonHealthCheckFailed = "HealthCheckFailed"
+
+	notaryHealthPath = "/_notary_server/health"
+)
+
+// HealthConditionTypes are informational conditions set by health probes
+var HealthConditionTypes = []status.ConditionType{
+	regv1.ConditionTypeAPIReachable,
+	regv1.ConditionTypeAuthWorking,
+	regv1.ConditionTypeStorageWritable,
+	regv1.ConditionTypeNotaryHealthy,
+}
+
+// IsHealthCondition returns true if the condition is set by health probes
+func IsHealthCondition(t status.ConditionType) bool {
+	for _, h := range HealthConditionTypes {
+		if h == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeHealth checks running registry serves its api, issues tokens, writes to its storage and its notary is healthy,
+// and sets the results as conditions. Storage is not probed while registry is read-only.
+// It returns the conditions whose status is changed.
+func ProbeHealth(c client.Client, reg *regv1.Registry, readOnly bool) []status.Condition {
+	results := map[status.ConditionType]*status.Condition{}
+	timeout := config.Config.GetDuration(config.ConfigRegistryHealthProbeTimeout)
+
+	regClient, err := inter.GetClient(c, reg, nil)
+	if err == nil && regClient == nil {
+		err = fmt.Errorf("failed to create registry client")
+	}
+	if err != nil {
+		results[regv1.ConditionTypeAPIReachable] = probeResult(regv1.ConditionTypeAPIReachable, err)
+	} else {
+		regClient.SetTimeout(timeout)
+		results[regv1.ConditionTypeAPIReachable] = probeResult(regv1.ConditionTypeAPIReachable, regClient.Ping())
+	}
+
+	if !results[regv1.ConditionTypeAPIReachable].IsTrue() {
+		results[regv1.ConditionTypeAuthWorking] = unknownResult(regv1.ConditionTypeAuthWorking, "APIUnreachable", "registry api is not reachable")
+		results[regv1.ConditionTypeStorageWritable] = unknownResult(regv1.ConditionTypeStorageWritable, "APIUnreachable", "registry api is not reachable")
+	} else {
+		results[regv1.ConditionTypeAuthWorking] = probeResult(regv1.ConditionTypeAuthWorking, regClient.CheckAuth())
+		switch {
+		case readOnly:
+			results[regv1.ConditionTypeStorageWritable] = unknownResult(regv1.ConditionTypeStorageWritable, "ReadOnly", "registry is read-only")
+		case !results[regv1.ConditionTypeAuthWorking].IsTrue():
+			results[regv1.ConditionTypeStorageWritable] = unknownResult(regv1.ConditionTypeStorageWritable, "AuthNotWorking", "token cannot be acquired")
+		default:
+			results[regv1.ConditionTypeStorageWritable] = probeResult(regv1.ConditionTypeStorageWritable, regClient.CheckStorage())
+		}
+	}
+
+	if reg.Spec.Notary.Enabled {
+		results[regv1.ConditionTypeNotaryHealthy] = probeResult(regv1.ConditionTypeNotaryHealthy, probeNotary(c, reg, timeout))
+	} else {
+		reg.Status.Conditions.RemoveCondition(regv1.ConditionTypeNotaryHealthy)
+	}
+
+	transitioned := []status.Condition{}
+	for _, t := range HealthConditionTypes {
+		cond, ok := results[t]
+		if !ok {
+			continue
+		}
+		prev := reg.Status.Conditions.GetCondition(t)
+		reg.Status.Conditions.SetCondition(*cond)
+		if prev == nil || prev.Status != cond.Status {
+			transitioned = append(transitioned, *cond)
+		}
+	}
+
+	return transitioned
+}
+
+// probeNotary checks notary server's health api
+func probeNotary(c client.Client, reg *regv1.Registry, timeout time.Duration) error {
+	notary := &regv1.Notary{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryNotary), Namespace: reg.Namespace}, notary); err != nil {
+		return err
+	}
+	if notary.Status.NotaryURL == "" {
+		return fmt.Errorf("notary url is not known yet")
+	}
+
+	caSecret, err := certs.GetRootCert(reg.Namespace)
+	if err != nil {
+		return err
+	}
+	ca, _ := certs.CAData(caSecret)
+	ca = append(ca, certs.ExternalCAData(c, notary.Spec.TLS, types.NamespacedName{Name: schemes.NotaryServerTLSSecretName(notary), Namespace: notary.Namespace})...)
+
+	httpClient := cmhttp.NewHTTPClient(notary.Status.NotaryURL, "", "", ca, false)
+	httpClient.Timeout = timeout
+	res, err := httpClient.Get(httpClient.URL + notaryHealthPath)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("notary server responded with %s", res.Status)
+	}
+
+	return nil
+}
+
+func probeResult(t status.ConditionType, err error) *status.Condition {
+	if err != nil {
+		return &status.Condition{Type: t, Status: corev1.ConditionFalse, Reason: "ProbeFailed", Message: err.Error()}
+	}
+	return &status.Condition{Type: t, Status: corev1.ConditionTrue, Reason: "ProbeSucceeded"}
+}
+
+func unknownResult(t status.ConditionType, reason status.ConditionReason, message string) *status.Condition {
+	return &status.Condition{Type: t, Status: corev1.ConditionUnknown, Reason: reason, Message: message}
+}
@@ -0,0 +1,87 @@
+package regctl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryHPA contains things to handle horizontal pod autoscaler resource
+type RegistryHPA struct {
+	c            client.Client
+	manifest     func() (interface{}, error)
+	cond         status.ConditionType
+	requirements []status.ConditionType
+	logger       logr.Logger
+}
+
+// NewRegistryHPA creates new registry horizontal pod autoscaler controller
+// deps: deployment
+func NewRegistryHPA(client client.Client, manifest func() (interface{}, error), cond status.ConditionType, logger logr.Logger) *RegistryHPA {
+	return &RegistryHPA{
+		c:        client,
+		manifest: manifest,
+		cond:     cond,
+		logger:   logger.WithName("HorizontalPodAutoscaler"),
+	}
+}
+
+func (r *RegistryHPA) ReconcileByConditionStatus(reg *regv1.Registry) (bool, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			reg.Status.Conditions.SetCondition(
+				status.Condition{
+					Type:    r.cond,
+					Status:  corev1.ConditionFalse,
+					Message: err.Error(),
+				})
+		}
+	}()
+
+	for _, dep := range r.requirements {
+		if !reg.Status.Conditions.GetCondition(dep).IsTrue() {
+			r.logger.Info(string(r.cond) + " needs " + string(dep))
+			return true, nil
+		}
+	}
+
+	ctx := context.TODO()
+	m, err := r.manifest()
+	if err != nil {
+		return false, err
+	}
+	manifest := m.(*autoscalingv1.HorizontalPodAutoscaler)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = r.c.Get(ctx, types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, hpa); err != nil {
+		if errors.IsNotFound(err) {
+			r.logger.Info("not found. create new one.")
+			if err = r.c.Create(ctx, manifest); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+		return false, err
+	}
+
+	reg.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    r.cond,
+			Status:  corev1.ConditionTrue,
+			Message: "Success",
+		})
+
+	return false, nil
+}
+
+func (r *RegistryHPA) Require(cond status.ConditionType) ResourceController {
+	r.requirements = append(r.requirements, cond)
+	return r
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/operator-lib/status"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -68,6 +69,8 @@ func (r *RegistryNotary) ReconcileByConditionStatus(reg *regv1.Registry) (bool,
 		}
 		return true, err
 	}
+	setNotaryReplicas(r.c, reg, notary, r.logger)
+
 	if notary.Status.NotaryURL == "" {
 		err = regv1.MakeRegistryError("NotReady")
 		return true, err
@@ -88,3 +91,28 @@ func (r *RegistryNotary) Require(cond status.ConditionType) ResourceController {
 	r.requirements = append(r.requirements, cond)
 	return r
 }
+
+func setNotaryReplicas(c client.Client, reg *regv1.Registry, notary *regv1.Notary, logger logr.Logger) {
+	components := []struct {
+		name    string
+		subType schemes.SubresourceType
+	}{
+		{ComponentNotaryServer, schemes.SubTypeNotaryServerPod},
+		{ComponentNotarySigner, schemes.SubTypeNotarySignerPod},
+		{ComponentNotaryDB, schemes.SubTypeNotaryDBPod},
+	}
+
+	for _, component := range components {
+		name, subType := component.name, component.subType
+		ready := int32(0)
+		pod := &corev1.Pod{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(notary, subType), Namespace: notary.Namespace}, pod); err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Error(err, "failed to get notary pod", "component", name)
+			}
+		} else if isPodReady(pod) {
+			ready = 1
+		}
+		setComponentReplicas(reg, name, 1, ready)
+	}
+}
@@ -0,0 +1,87 @@
+package regctl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryPDB contains things to handle pod disruption budget resource
+type RegistryPDB struct {
+	c            client.Client
+	manifest     func() (interface{}, error)
+	cond         status.ConditionType
+	requirements []status.ConditionType
+	logger       logr.Logger
+}
+
+// NewRegistryPDB creates new registry pod disruption budget controller
+// deps: deployment
+func NewRegistryPDB(client client.Client, manifest func() (interface{}, error), cond status.ConditionType, logger logr.Logger) *RegistryPDB {
+	return &RegistryPDB{
+		c:        client,
+		manifest: manifest,
+		cond:     cond,
+		logger:   logger.WithName("PodDisruptionBudget"),
+	}
+}
+
+func (r *RegistryPDB) ReconcileByConditionStatus(reg *regv1.Registry) (bool, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			reg.Status.Conditions.SetCondition(
+				status.Condition{
+					Type:    r.cond,
+					Status:  corev1.ConditionFalse,
+					Message: err.Error(),
+				})
+		}
+	}()
+
+	for _, dep := range r.requirements {
+		if !reg.Status.Conditions.GetCondition(dep).IsTrue() {
+			r.logger.Info(string(r.cond) + " needs " + string(dep))
+			return true, nil
+		}
+	}
+
+	ctx := context.TODO()
+	m, err := r.manifest()
+	if err != nil {
+		return false, err
+	}
+	manifest := m.(*policyv1beta1.PodDisruptionBudget)
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	if err = r.c.Get(ctx, types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, pdb); err != nil {
+		if errors.IsNotFound(err) {
+			r.logger.Info("not found. create new one.")
+			if err = r.c.Create(ctx, manifest); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+		return false, err
+	}
+
+	reg.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    r.cond,
+			Status:  corev1.ConditionTrue,
+			Message: "Success",
+		})
+
+	return false, nil
+}
+
+func (r *RegistryPDB) Require(cond status.ConditionType) ResourceController {
+	r.requirements = append(r.requirements, cond)
+	return r
+}
@@ -0,0 +1,94 @@
+package regctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// proxyMetrics is cache metrics which registry's debug server exposes in /debug/vars
+type proxyMetrics struct {
+	Requests    int64
+	Hits        int64
+	Misses      int64
+	BytesPulled int64
+	BytesPushed int64
+}
+
+type debugVars struct {
+	Registry struct {
+		Proxy struct {
+			Blobs     proxyMetrics `json:"blobs"`
+			Manifests proxyMetrics `json:"manifests"`
+		} `json:"proxy"`
+	} `json:"registry"`
+}
+
+var proxyStatsClient = &http.Client{Timeout: 5 * time.Second}
+
+// UpdateProxyStatus collects pull-through cache statistics from all running registry pods
+func UpdateProxyStatus(c client.Client, reg *regv1.Registry, remoteURL string) error {
+	podList := &corev1.PodList{}
+	if err := c.List(context.TODO(), podList, &client.ListOptions{
+		Namespace: reg.Namespace,
+		LabelSelector: labels.SelectorFromSet(labels.Set(map[string]string{
+			"app":  "registry",
+			"apps": schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment),
+		})),
+	}); err != nil {
+		return err
+	}
+
+	stat := &regv1.ProxyStatus{RemoteURL: remoteURL}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		vars, err := getDebugVars(pod.Status.PodIP)
+		if err != nil {
+			return err
+		}
+		addProxyMetrics(&stat.Blobs, vars.Registry.Proxy.Blobs)
+		addProxyMetrics(&stat.Manifests, vars.Registry.Proxy.Manifests)
+	}
+	stat.LastUpdated = metav1.Now()
+	reg.Status.Proxy = stat
+
+	return nil
+}
+
+func getDebugVars(podIP string) (*debugVars, error) {
+	resp, err := proxyStatsClient.Get(fmt.Sprintf("http://%s:%d/debug/vars", podIP, schemes.RegistryProxyDebugPort))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get debug vars from %s: %s", podIP, resp.Status)
+	}
+
+	vars := &debugVars{}
+	if err := json.NewDecoder(resp.Body).Decode(vars); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+func addProxyMetrics(stat *regv1.ProxyCacheStats, m proxyMetrics) {
+	stat.Hits += m.Hits
+	stat.Misses += m.Misses
+	stat.BytesPulled += m.BytesPulled
+	stat.BytesServed += m.BytesPushed
+}
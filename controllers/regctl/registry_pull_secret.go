@@ -0,0 +1,247 @@
+package regctl
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ManagedPullSecretsAnnotation is the annotation of service accounts which has comma separated names of
+// image pull secrets added by the operator. Only those are removed from the service accounts on clean up.
+const ManagedPullSecretsAnnotation = "tmax.io/managed-image-pull-secrets"
+
+// DistributePullSecret keeps a synchronized copy of registry's image pull secret in the selected namespaces
+// which accept it by ImagePullSecretDistributionLabel, and refers to it from their service accounts. Copies in namespaces which are not selected anymore are cleaned up.
+// The result is recorded in registry's status.
+func DistributePullSecret(c client.Client, reg *regv1.Registry) error {
+	if reg.Status.ImagePullSecretDistribution == nil {
+		reg.Status.ImagePullSecretDistribution = &regv1.ImagePullSecretDistributionStatus{}
+	}
+	status := reg.Status.ImagePullSecretDistribution
+
+	namespaces, err := distributePullSecret(c, reg)
+	if err != nil {
+		status.Message = err.Error()
+		return err
+	}
+	status.Namespaces = namespaces
+	status.Message = ""
+
+	return nil
+}
+
+// distributePullSecret returns namespaces where the image pull secret is distributed
+func distributePullSecret(c client.Client, reg *regv1.Registry) ([]string, error) {
+	ctx := context.TODO()
+	dist := reg.Spec.ImagePullSecretDistribution
+	source := &corev1.Secret{}
+	sourceName := schemes.SubresourceName(reg, schemes.SubTypeRegistryDCJSecret)
+	if err := c.Get(ctx, types.NamespacedName{Name: sourceName, Namespace: reg.Namespace}, source); err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&dist.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		if ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		// the credential is copied only to namespaces which accept it, e.g. not to kube-system by an empty selector
+		if ns.Name != reg.Namespace && ns.Labels[regv1.ImagePullSecretDistributionLabel] != "true" {
+			continue
+		}
+		name := sourceName
+		if ns.Name != reg.Namespace {
+			copied := schemes.DistributedDCJSecret(reg, source, ns.Name)
+			if err := syncPullSecretCopy(c, copied); err != nil {
+				return nil, err
+			}
+			name = copied.Name
+		}
+		if err := syncServiceAccounts(c, ns.Name, name, dist.ServiceAccounts); err != nil {
+			return nil, err
+		}
+		selected[ns.Name] = true
+	}
+
+	if err := CleanupPullSecret(c, reg.Namespace, reg.Name, selected); err != nil {
+		return nil, err
+	}
+
+	distributed := []string{}
+	for ns := range selected {
+		distributed = append(distributed, ns)
+	}
+	sort.Strings(distributed)
+
+	return distributed, nil
+}
+
+// CleanupPullSecret deletes copies of registry's image pull secret and removes them from service accounts,
+// except in the namespaces to keep
+func CleanupPullSecret(c client.Client, namespace, name string, keep map[string]bool) error {
+	copies := &corev1.SecretList{}
+	if err := c.List(context.TODO(), copies, client.MatchingLabels(schemes.DistributedDCJSecretLabels(namespace, name))); err != nil {
+		return err
+	}
+	for _, copied := range copies.Items {
+		if keep[copied.Namespace] {
+			continue
+		}
+		if err := syncServiceAccounts(c, copied.Namespace, copied.Name, nil); err != nil {
+			return err
+		}
+		if err := c.Delete(context.TODO(), &copied); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if keep[namespace] {
+		return nil
+	}
+	// the source secret itself is owned by the registry, so only references to it are removed
+	reg := &regv1.Registry{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return syncServiceAccounts(c, namespace, schemes.SubresourceName(reg, schemes.SubTypeRegistryDCJSecret), nil)
+}
+
+func syncPullSecretCopy(c client.Client, desired *corev1.Secret) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return c.Create(context.TODO(), desired)
+		}
+		return err
+	}
+
+	if secret.Type != desired.Type {
+		// type of a secret is immutable
+		if err := c.Delete(context.TODO(), secret); err != nil {
+			return err
+		}
+		return c.Create(context.TODO(), desired)
+	}
+	if reflect.DeepEqual(secret.Data, desired.Data) && reflect.DeepEqual(secret.Labels, desired.Labels) {
+		return nil
+	}
+
+	original := secret.DeepCopy()
+	secret.Data = desired.Data
+	secret.Labels = desired.Labels
+	return c.Patch(context.TODO(), secret, client.MergeFrom(original))
+}
+
+// syncServiceAccounts makes the listed service accounts in the namespace refer to the secret,
+// and removes the reference added by the operator from the others
+func syncServiceAccounts(c client.Client, namespace, secret string, names []string) error {
+	sas := &corev1.ServiceAccountList{}
+	if err := c.List(context.TODO(), sas, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	for i := range sas.Items {
+		want := utils.Contains(names, sas.Items[i].Name)
+		if err := syncServiceAccount(c, &sas.Items[i], secret, want); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncServiceAccount adds the reference to the secret to the service account if wanted, or removes it if it is added
+// by the operator. It is patched with optimistic lock, so that image pull secrets added meanwhile are not lost.
+func syncServiceAccount(c client.Client, sa *corev1.ServiceAccount, secret string, want bool) error {
+	latest := sa
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// the service account is got again if it is changed after it is listed
+		if latest == nil {
+			latest = &corev1.ServiceAccount{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}, latest); err != nil {
+				return err
+			}
+		}
+
+		managed := managedPullSecrets(latest)
+		referred := false
+		for _, ref := range latest.ImagePullSecrets {
+			if ref.Name == secret {
+				referred = true
+				break
+			}
+		}
+
+		original := latest.DeepCopy()
+		switch {
+		case want && !referred:
+			latest.ImagePullSecrets = append(latest.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+			setManagedPullSecrets(latest, append(managed, secret))
+		case !want && referred && utils.Contains(managed, secret):
+			refs := []corev1.LocalObjectReference{}
+			for _, ref := range latest.ImagePullSecrets {
+				if ref.Name != secret {
+					refs = append(refs, ref)
+				}
+			}
+			latest.ImagePullSecrets = refs
+			rest := []string{}
+			for _, m := range managed {
+				if m != secret {
+					rest = append(rest, m)
+				}
+			}
+			setManagedPullSecrets(latest, rest)
+		default:
+			return nil
+		}
+
+		if err := c.Patch(context.TODO(), latest, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+			latest = nil
+			return err
+		}
+		return nil
+	})
+	if errors.IsNotFound(err) {
+		// deleted meanwhile
+		return nil
+	}
+
+	return err
+}
+
+func managedPullSecrets(sa *corev1.ServiceAccount) []string {
+	value := sa.Annotations[ManagedPullSecretsAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func setManagedPullSecrets(sa *corev1.ServiceAccount, secrets []string) {
+	if len(secrets) == 0 {
+		delete(sa.Annotations, ManagedPullSecretsAnnotation)
+		return
+	}
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	sa.Annotations[ManagedPullSecretsAnnotation] = strings.Join(secrets, ",")
+}
@@ -0,0 +1,68 @@
+package regctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDistributePullSecret(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	reg.Spec.ImagePullSecretDistribution = &regv1.ImagePullSecretDistribution{ServiceAccounts: []string{"default"}}
+
+	source := schemes.DCJSecret(reg, "passwd")
+	objs := []runtime.Object{source}
+	for _, ns := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "ns"},
+		{name: "kube-system"},
+		{name: "team", labels: map[string]string{regv1.ImagePullSecretDistributionLabel: "true"}},
+	} {
+		objs = append(objs,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns.name, Labels: ns.labels}},
+			&corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: ns.name, ResourceVersion: "1"},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "own"}},
+			},
+		)
+	}
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, corev1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, objs...)
+
+	// an empty selector matches all namespaces, but only those which accept it are selected
+	assert.Equal(t, nil, DistributePullSecret(c, reg))
+	assert.Equal(t, []string{"ns", "team"}, reg.Status.ImagePullSecretDistribution.Namespaces)
+
+	copied := schemes.SubresourceName(reg, schemes.SubTypeRegistryDistributedDCJSecret)
+	assert.NotEqual(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: copied, Namespace: "kube-system"}, &corev1.Secret{}))
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: copied, Namespace: "team"}, &corev1.Secret{}))
+
+	sa := &corev1.ServiceAccount{}
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: "default", Namespace: "kube-system"}, sa))
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "own"}}, sa.ImagePullSecrets)
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: "default", Namespace: "team"}, sa))
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "own"}, {Name: copied}}, sa.ImagePullSecrets)
+	assert.Equal(t, copied, sa.Annotations[ManagedPullSecretsAnnotation])
+
+	// a service account changed after it is listed is patched again, keeping references added meanwhile
+	stale := sa.DeepCopy()
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: "added"})
+	assert.Equal(t, nil, c.Update(context.TODO(), sa))
+	assert.Equal(t, nil, syncServiceAccount(c, stale, copied, false))
+	sa = &corev1.ServiceAccount{}
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: "default", Namespace: "team"}, sa))
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "own"}, {Name: "added"}}, sa.ImagePullSecrets)
+	assert.Equal(t, "", sa.Annotations[ManagedPullSecretsAnnotation])
+}
@@ -0,0 +1,215 @@
+package regctl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/repoctl"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonQuotaExceeded is the event reason when a pushed image is rejected or registry becomes read-only by quota
+	EventReasonQuotaExceeded = "QuotaExceeded"
+	// EventReasonQuotaRecovered is the event reason when registry is switched back from read-only by quota
+	EventReasonQuotaRecovered = "QuotaRecovered"
+)
+
+// maxCachedManifests is the number of manifests whose blobs are cached to sum up usage of registry
+const maxCachedManifests = 10000
+
+// manifestBlobs are blobs referenced by manifests, by the digests of the manifests. They never change for a digest.
+var manifestBlobs = struct {
+	sync.Mutex
+	blobs map[string]map[string]int64
+}{blobs: map[string]map[string]int64{}}
+
+// ImageDetailsGetter gets details of images in the registry
+type ImageDetailsGetter interface {
+	GetImageDetails(image string) (*image.ImageDetails, error)
+}
+
+// RepositoryUsage returns the size of unique manifests, configs and layers referenced by the tags,
+// and details of each tag
+func RepositoryUsage(regClient ImageDetailsGetter, repoName string, tags []string) (int64, map[string]*image.ImageDetails, error) {
+	details := map[string]*image.ImageDetails{}
+	images := []*image.ImageDetails{}
+	for _, tag := range tags {
+		d, err := regClient.GetImageDetails(fmt.Sprintf("%s:%s", repoName, tag))
+		if err != nil {
+			return 0, nil, err
+		}
+		cacheManifestBlobs(d)
+		details[tag] = d
+		images = append(images, d)
+	}
+
+	return image.TotalSize(images...), details, nil
+}
+
+// RegistryUsage returns the size of unique manifests, configs and layers referenced by the versions of the repositories.
+// Blobs shared by repositories, e.g. layers of a base image, are counted once, as the registry stores them once.
+// Versions whose digests are recorded in status are got from cache after their first time.
+func RegistryUsage(regClient ImageDetailsGetter, repos []regv1.Repository) (int64, error) {
+	blobs := map[string]int64{}
+	for _, repo := range repos {
+		digests := map[string]string{}
+		for _, s := range repo.Status.Versions {
+			digests[s.Version] = s.Digest
+		}
+		for _, v := range repo.Spec.Versions {
+			vb, err := versionBlobs(regClient, repo.Spec.Name, v.Version, digests[v.Version])
+			if err != nil {
+				return 0, err
+			}
+			for d, size := range vb {
+				blobs[d] = size
+			}
+		}
+	}
+
+	var used int64
+	for _, size := range blobs {
+		used += size
+	}
+	return used, nil
+}
+
+func versionBlobs(regClient ImageDetailsGetter, repoName, tag, digest string) (map[string]int64, error) {
+	ref := fmt.Sprintf("%s:%s", repoName, tag)
+	if digest != "" {
+		manifestBlobs.Lock()
+		blobs, ok := manifestBlobs.blobs[digest]
+		manifestBlobs.Unlock()
+		if ok {
+			return blobs, nil
+		}
+		ref = fmt.Sprintf("%s@%s", repoName, digest)
+	}
+
+	d, err := regClient.GetImageDetails(ref)
+	if err != nil {
+		return nil, err
+	}
+	cacheManifestBlobs(d)
+	return d.Blobs, nil
+}
+
+func cacheManifestBlobs(d *image.ImageDetails) {
+	manifestBlobs.Lock()
+	defer manifestBlobs.Unlock()
+
+	if len(manifestBlobs.blobs) >= maxCachedManifests {
+		manifestBlobs.blobs = map[string]map[string]int64{}
+	}
+	manifestBlobs.blobs[d.Digest] = d.Blobs
+}
+
+// CheckRepositoryQuota returns the reason if the repository exceeds quota of itself or registry
+func CheckRepositoryQuota(c client.Client, reg *regv1.Registry, repo *regv1.Repository) (string, error) {
+	var maxTags *int32
+	if reg.Spec.Quota != nil {
+		maxTags = reg.Spec.Quota.MaxTagsPerRepository
+	}
+
+	if repo.Spec.Quota != nil {
+		if repo.Spec.Quota.MaxTags != nil {
+			maxTags = repo.Spec.Quota.MaxTags
+		}
+		if repo.Spec.Quota.MaxSize != "" {
+			maxSize, err := resource.ParseQuantity(repo.Spec.Quota.MaxSize)
+			if err != nil {
+				return "", err
+			}
+			if repo.Status.UsedBytes > maxSize.Value() {
+				return fmt.Sprintf("repository size %d exceeds quota %s", repo.Status.UsedBytes, repo.Spec.Quota.MaxSize), nil
+			}
+		}
+	}
+
+	if maxTags != nil && int32(len(repo.Spec.Versions)) > *maxTags {
+		return fmt.Sprintf("number of tags %d exceeds quota %d", len(repo.Spec.Versions), *maxTags), nil
+	}
+
+	if reg.Spec.Quota != nil && reg.Spec.Quota.MaxRepositories != nil {
+		repoList, err := repoctl.New().List(c, reg)
+		if err != nil {
+			return "", err
+		}
+		if int32(len(repoList.Items)) > *reg.Spec.Quota.MaxRepositories {
+			return fmt.Sprintf("number of repositories %d exceeds quota %d", len(repoList.Items), *reg.Spec.Quota.MaxRepositories), nil
+		}
+	}
+
+	return "", nil
+}
+
+// UpdateRegistryQuota sums up usage of all repositories and sets quota status of registry.
+// It returns true if the registry should be switched to or from read-only mode.
+func UpdateRegistryQuota(c client.Client, reg *regv1.Registry, regClient ImageDetailsGetter) (bool, error) {
+	wasExceeded := reg.Status.Quota.IsExceeded()
+	if reg.Spec.Quota == nil {
+		reg.Status.Quota = nil
+		return wasExceeded, nil
+	}
+
+	repoList, err := repoctl.New().List(c, reg)
+	if err != nil {
+		return false, err
+	}
+
+	used, err := RegistryUsage(regClient, repoList.Items)
+	if err != nil {
+		return false, err
+	}
+	stat := &regv1.QuotaStatus{Repositories: int32(len(repoList.Items)), UsedBytes: used}
+
+	if reg.Spec.Quota.MaxSize != "" {
+		maxSize, err := resource.ParseQuantity(reg.Spec.Quota.MaxSize)
+		if err != nil {
+			return false, err
+		}
+		if stat.UsedBytes > maxSize.Value() {
+			stat.Exceeded = true
+			stat.Message = fmt.Sprintf("registry size %d exceeds quota %s", stat.UsedBytes, reg.Spec.Quota.MaxSize)
+		}
+	}
+	reg.Status.Quota = stat
+
+	return wasExceeded != stat.Exceeded, nil
+}
+
+// SetDeploymentReadOnly switches registry deployment to or from read-only maintenance mode
+func SetDeploymentReadOnly(c client.Client, reg *regv1.Registry, readOnly bool) error {
+	ctx := context.TODO()
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+		return err
+	}
+
+	origin := deploy.DeepCopy()
+	container := &deploy.Spec.Template.Spec.Containers[0]
+	env := []corev1.EnvVar{}
+	for _, e := range container.Env {
+		if e.Name != schemes.RegistryEnvKeyStorageMaintenance {
+			env = append(env, e)
+		}
+	}
+	if readOnly {
+		env = append(env, corev1.EnvVar{
+			Name:  schemes.RegistryEnvKeyStorageMaintenance,
+			Value: schemes.RegistryEnvValueStorageMaintenance,
+		})
+	}
+	container.Env = env
+
+	return c.Patch(ctx, deploy, client.MergeFrom(origin))
+}
@@ -0,0 +1,69 @@
+package regctl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+)
+
+// fakeImageDetailsGetter returns details of images by their references, and counts the requests
+type fakeImageDetailsGetter struct {
+	details  map[string]*image.ImageDetails
+	requests int
+}
+
+func (f *fakeImageDetailsGetter) GetImageDetails(ref string) (*image.ImageDetails, error) {
+	f.requests++
+	d, ok := f.details[ref]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", ref)
+	}
+	return d, nil
+}
+
+func TestRegistryUsage(t *testing.T) {
+	base := map[string]int64{"sha256:base-layer": 100}
+	blobs := func(digest string, size int64) map[string]int64 {
+		b := map[string]int64{digest: size}
+		for d, s := range base {
+			b[d] = s
+		}
+		return b
+	}
+	getter := &fakeImageDetailsGetter{details: map[string]*image.ImageDetails{
+		"app@sha256:usage-app": {Digest: "sha256:usage-app", Blobs: blobs("sha256:usage-app", 10)},
+		"web@sha256:usage-web": {Digest: "sha256:usage-web", Blobs: blobs("sha256:usage-web", 20)},
+		"web:dev":              {Digest: "sha256:usage-dev", Blobs: blobs("sha256:usage-dev", 30)},
+	}}
+
+	repo := func(name string, digests map[string]string) regv1.Repository {
+		r := regv1.Repository{}
+		r.Spec.Name = name
+		for tag, digest := range digests {
+			r.Spec.Versions = append(r.Spec.Versions, regv1.ImageVersion{Version: tag})
+			if digest != "" {
+				r.Status.Versions = append(r.Status.Versions, regv1.ImageVersionStatus{Version: tag, Digest: digest})
+			}
+		}
+		return r
+	}
+	repos := []regv1.Repository{
+		repo("app", map[string]string{"v1": "sha256:usage-app"}),
+		repo("web", map[string]string{"v1": "sha256:usage-web", "dev": ""}),
+	}
+
+	// the base layer shared by repositories is counted once
+	used, err := RegistryUsage(getter, repos)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(160), used)
+	assert.Equal(t, 3, getter.requests)
+
+	// blobs of recorded digests are cached, but a version without digest is got again
+	used, err = RegistryUsage(getter, repos)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(160), used)
+	assert.Equal(t, 4, getter.requests)
+}
@@ -0,0 +1,43 @@
+package regctl
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ComponentRegistry is the component name of registry server
+	ComponentRegistry = "registry"
+	// ComponentNotaryServer is the component name of notary server
+	ComponentNotaryServer = "notary-server"
+	// ComponentNotarySigner is the component name of notary signer
+	ComponentNotarySigner = "notary-signer"
+	// ComponentNotaryDB is the component name of notary db
+	ComponentNotaryDB = "notary-db"
+)
+
+// setComponentReplicas sets ready and desired replicas of the component in registry status
+func setComponentReplicas(reg *regv1.Registry, name string, desired, ready int32) {
+	for i := range reg.Status.Replicas {
+		if reg.Status.Replicas[i].Name == name {
+			reg.Status.Replicas[i].Desired = desired
+			reg.Status.Replicas[i].Ready = ready
+			return
+		}
+	}
+
+	reg.Status.Replicas = append(reg.Status.Replicas, regv1.ComponentReplicas{
+		Name:    name,
+		Desired: desired,
+		Ready:   ready,
+	})
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
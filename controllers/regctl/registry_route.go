@@ -0,0 +1,113 @@
+package regctl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryRoute contains things to handle Gateway API route resource
+type RegistryRoute struct {
+	c            client.Client
+	manifest     func() (interface{}, error)
+	cond         status.ConditionType
+	requirements []status.ConditionType
+	logger       logr.Logger
+}
+
+// NewRegistryRoute creates new registry route controller
+// deps: cert
+func NewRegistryRoute(client client.Client, manifest func() (interface{}, error), cond status.ConditionType, logger logr.Logger) *RegistryRoute {
+	return &RegistryRoute{
+		c:        client,
+		manifest: manifest,
+		cond:     cond,
+		logger:   logger.WithName("Route"),
+	}
+}
+
+func (r *RegistryRoute) ReconcileByConditionStatus(reg *regv1.Registry) (bool, error) {
+	var err error
+	message := ""
+	defer func() {
+		if err != nil {
+			message = err.Error()
+		}
+		if message != "" {
+			reg.Status.Conditions.SetCondition(
+				status.Condition{
+					Type:    r.cond,
+					Status:  corev1.ConditionFalse,
+					Message: message,
+				})
+		}
+	}()
+
+	for _, dep := range r.requirements {
+		if !reg.Status.Conditions.GetCondition(dep).IsTrue() {
+			r.logger.Info(string(r.cond) + " needs " + string(dep))
+			return true, nil
+		}
+	}
+
+	ctx := context.TODO()
+	m, err := r.manifest()
+	if err != nil {
+		return false, err
+	}
+	manifest := m.(*unstructured.Unstructured)
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(manifest.GroupVersionKind())
+	if err = r.c.Get(ctx, types.NamespacedName{Name: manifest.GetName(), Namespace: manifest.GetNamespace()}, route); err != nil {
+		if errors.IsNotFound(err) {
+			r.logger.Info("not found. create new one.")
+			if err = r.c.Create(ctx, manifest); err != nil {
+				return false, err
+			}
+			message = "waiting for route to be accepted"
+			return true, nil
+		}
+		return false, err
+	}
+
+	if ingress.RouteChanged(route, manifest) {
+		r.logger.Info("route is changed. update it.")
+		origin := route.DeepCopy()
+		route.Object["spec"] = manifest.Object["spec"]
+		if err = r.c.Patch(ctx, route, client.MergeFrom(origin)); err != nil {
+			return false, err
+		}
+	}
+
+	accepted := false
+	if accepted, message = ingress.RouteAccepted(route); !accepted {
+		return true, nil
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) > 0 {
+		reg.Status.ServerURL = "https://" + hostnames[0]
+	}
+
+	reg.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    r.cond,
+			Status:  corev1.ConditionTrue,
+			Message: "Success",
+		})
+
+	return false, nil
+}
+
+func (r *RegistryRoute) Require(cond status.ConditionType) ResourceController {
+	r.requirements = append(r.requirements, cond)
+	return r
+}
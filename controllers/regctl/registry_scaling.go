@@ -0,0 +1,113 @@
+package regctl
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyScaling applies replicas, autoscaling and anti-affinity of a running registry.
+// The deployment is patched, and the pdb and the hpa are created, updated or deleted to follow the manifests.
+// A nil manifest means the resource is not needed.
+func ApplyScaling(c client.Client, reg *regv1.Registry, pdb *policyv1beta1.PodDisruptionBudget, hpa *autoscalingv1.HorizontalPodAutoscaler) error {
+	ctx := context.TODO()
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+		return err
+	}
+
+	origin := deploy.DeepCopy()
+	// replicas are left to the hpa while autoscaling is enabled
+	if reg.Spec.RegistryDeployment.Autoscaling == nil {
+		replicas := reg.Spec.RegistryDeployment.DesiredReplicas()
+		deploy.Spec.Replicas = &replicas
+	}
+	deploy.Spec.Template.Spec.Affinity = schemes.RegistryAntiAffinity(reg.Spec.RegistryDeployment.AntiAffinity, deploy.Spec.Selector.MatchLabels)
+	if !reflect.DeepEqual(origin.Spec, deploy.Spec) {
+		if err := c.Patch(ctx, deploy, client.MergeFrom(origin)); err != nil {
+			return err
+		}
+	}
+
+	name := types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryPDB), Namespace: reg.Namespace}
+	curPDB := &policyv1beta1.PodDisruptionBudget{}
+	if err := applyScalingResource(c, name, curPDB, pdb, func() bool {
+		if reflect.DeepEqual(curPDB.Spec, pdb.Spec) {
+			return false
+		}
+		curPDB.Spec = pdb.Spec
+		return true
+	}); err != nil {
+		return err
+	}
+
+	name = types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryHPA), Namespace: reg.Namespace}
+	curHPA := &autoscalingv1.HorizontalPodAutoscaler{}
+	return applyScalingResource(c, name, curHPA, hpa, func() bool {
+		if reflect.DeepEqual(curHPA.Spec, hpa.Spec) {
+			return false
+		}
+		curHPA.Spec = hpa.Spec
+		return true
+	})
+}
+
+// applyScalingResource creates the manifest if the resource does not exist, deletes the resource if the manifest is nil,
+// and otherwise patches the resource if update changes it
+func applyScalingResource(c client.Client, name types.NamespacedName, current, manifest runtime.Object, update func() bool) error {
+	ctx := context.TODO()
+	err := c.Get(ctx, name, current)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if reflect.ValueOf(manifest).IsNil() {
+		if !exists {
+			return nil
+		}
+		return client.IgnoreNotFound(c.Delete(ctx, current))
+	}
+	if !exists {
+		return c.Create(ctx, manifest)
+	}
+
+	origin := current.DeepCopyObject()
+	if !update() {
+		return nil
+	}
+	return c.Patch(ctx, current, client.MergeFrom(origin))
+}
+
+// RefreshReplicas updates ready and desired replicas of registry components in registry status
+func RefreshReplicas(c client.Client, reg *regv1.Registry, logger logr.Logger) error {
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryDeployment), Namespace: reg.Namespace}, deploy); err != nil {
+		return err
+	}
+	desired := int32(1)
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	setComponentReplicas(reg, ComponentRegistry, desired, deploy.Status.ReadyReplicas)
+
+	if !reg.Spec.Notary.Enabled {
+		return nil
+	}
+	notary := &regv1.Notary{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryNotary), Namespace: reg.Namespace}, notary); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	setNotaryReplicas(c, reg, notary, logger)
+	return nil
+}
@@ -2,8 +2,11 @@ package regctl
 
 import (
 	"context"
+	"fmt"
+
 	"github.com/go-logr/logr"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -91,6 +94,17 @@ func (r *RegistryService) ReconcileByConditionStatus(reg *regv1.Registry) (bool,
 		reg.Status.LoadBalancerIP = lbIP
 		reg.Status.ServerURL = "https://" + lbIP
 		logger.Info("LoadBalancer info", "LoadBalancer IP", lbIP)
+	case corev1.ServiceTypeNodePort:
+		var addr string
+		if addr, err = ingress.GetNodeAddress(r.c); err != nil {
+			return true, err
+		}
+		if len(svc.Spec.Ports) == 0 || svc.Spec.Ports[0].NodePort == 0 {
+			err = regv1.MakeRegistryError("NotReady")
+			return true, err
+		}
+		reg.Status.ServerURL = fmt.Sprintf("https://%s:%d", addr, svc.Spec.Ports[0].NodePort)
+		logger.Info("NodePort info", "Server URL", reg.Status.ServerURL)
 	case corev1.ServiceTypeClusterIP:
 		if svc.Spec.ClusterIP == "" {
 			err = regv1.MakeRegistryError("NotReady")
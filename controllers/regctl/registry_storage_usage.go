@@ -0,0 +1,100 @@
+package regctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExpandPVC expands registry's pvc if requested storage size grows and its storage class allows volume expansion
+func ExpandPVC(c client.Client, reg *regv1.Registry) error {
+	pvcSpec := reg.Spec.PersistentVolumeClaim.Create
+	if pvcSpec == nil {
+		return nil
+	}
+
+	requested, err := resource.ParseQuantity(pvcSpec.StorageSize)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryPVC), Namespace: reg.Namespace}, pvc); err != nil {
+		return err
+	}
+	reg.Status.Capacity = pvc.Status.Capacity.Storage().String()
+
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requested.Cmp(current) <= 0 {
+		return nil
+	}
+
+	if pvc.Spec.StorageClassName == nil {
+		return fmt.Errorf("pvc %s has no storage class to expand", pvc.Name)
+	}
+	sc := &storagev1.StorageClass{}
+	if err := c.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+		return err
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return fmt.Errorf("storage class %s does not allow volume expansion", sc.Name)
+	}
+
+	log.Info("expand pvc", "namespace", pvc.Namespace, "name", pvc.Name, "from", current.String(), "to", requested.String())
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+	if pvc.Spec.Resources.Limits != nil {
+		pvc.Spec.Resources.Limits[corev1.ResourceStorage] = requested
+	}
+
+	return c.Update(ctx, pvc)
+}
+
+// UpdateStorageUsage collects used and free bytes of registry's pvc and sets StorageNearlyFull condition
+func UpdateStorageUsage(c client.Client, reg *regv1.Registry) error {
+	podName, err := PodName(c, reg)
+	if err != nil {
+		return err
+	}
+
+	out, err := inter.NewCommander(podName, reg.Namespace).DiskFree(schemes.RegistryMountPath(reg))
+	if err != nil {
+		return err
+	}
+
+	total, used, avail, err := inter.ParseDiskFree(out.Outbuf.String())
+	if err != nil {
+		return err
+	}
+
+	reg.Status.StorageUsage = &regv1.StorageUsage{
+		UsedBytes:   used,
+		FreeBytes:   avail,
+		LastUpdated: metav1.Now(),
+	}
+
+	threshold := config.Config.GetInt64(config.ConfigRegistryStorageNearlyFullPercent)
+	cond := status.Condition{
+		Type:    regv1.ConditionTypeStorageNearlyFull,
+		Status:  corev1.ConditionFalse,
+		Message: fmt.Sprintf("%d/%d bytes used", used, total),
+	}
+	if total > 0 && used*100 >= total*threshold {
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = "UsageExceedsThreshold"
+	}
+	reg.Status.Conditions.SetCondition(cond)
+
+	return nil
+}
@@ -18,72 +18,44 @@ package controllers
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
-	"github.com/Nerzal/gocloak/v7"
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/operator-lib/status"
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/controllers/regctl"
+	"github.com/tmax-cloud/registry-operator/internal/admission"
 	"github.com/tmax-cloud/registry-operator/internal/common/config"
 	"github.com/tmax-cloud/registry-operator/internal/schemes"
-	"io/ioutil"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"net/http"
-	"os"
-	"path"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"strings"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sync"
+	"time"
 )
 
 // RegistryReconciler reconciles a Registry object
 type RegistryReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-}
-
-var keycloak gocloak.GoCloak
-
-func init() {
-	address := config.Config.GetString(config.ConfigTokenServiceAddr)
-	insecure := config.Config.GetBool(config.ConfigTokenServiceInsecure)
-	debug := config.Config.GetBool(config.ConfigTokenServiceDebug)
-
-	keycloak = gocloak.NewClient(address)
-	// FIXME: load value from manager_config.
-	restyKeycloak := keycloak.RestyClient()
-	restyKeycloak.SetDebug(debug)
-
-	tlscfg := &tls.Config{
-		InsecureSkipVerify: insecure,
-	}
-	if rootCAPath := os.Getenv("ROOTCA_PATH"); len(rootCAPath) > 0 {
-		rootCA, err := ioutil.ReadFile(rootCAPath)
-		if err != nil {
-			fmt.Println("failed to load root CA")
-			os.Exit(1)
-		}
-		certpool, err := x509.SystemCertPool()
-		if err != nil {
-			fmt.Println("failed to load system cert pool")
-			os.Exit(1)
-		}
-		if ok := certpool.AppendCertsFromPEM(rootCA); !ok {
-			fmt.Println("failed to add cert to pool")
-			os.Exit(1)
-		}
-		tlscfg.RootCAs = certpool
-	}
-	restyKeycloak.SetTLSClientConfig(tlscfg)
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=tmax.io,resources=registries,verbs=get;list;watch;create;update;patch;delete
@@ -94,6 +66,16 @@ func init() {
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;tlsroutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
 
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=get;list;watch;create;update;patch;delete
@@ -106,179 +88,29 @@ func (r *RegistryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	err := r.Get(ctx, req.NamespacedName, o)
 	if err != nil {
 		if k8serr.IsNotFound(err) {
-			username := config.Config.GetString("keycloak.username")
-			password := config.Config.GetString("keycloak.password")
-			token, kerr := keycloak.LoginAdmin(ctx, username, password, "master")
-			if kerr != nil {
-				logger.Error(kerr, "failed to login keycloak")
-				return reconcile.Result{}, kerr
-			}
-
-			realmName := req.Namespace
-			clientId := req.Name
-			clients, kerr := keycloak.GetClients(ctx, token.AccessToken, realmName, gocloak.GetClientsParams{})
-			if kerr != nil {
-				logger.Error(kerr, "failed to get clients")
-				return ctrl.Result{}, kerr
-			}
-			for _, c := range clients {
-				if *c.ClientID == clientId {
-					if kerr = keycloak.DeleteClient(ctx, token.AccessToken, realmName, *c.ID); kerr != nil {
-						logger.Error(kerr, "failed to delete client")
-						return ctrl.Result{}, kerr
-					}
-				}
-			}
-
-			// TODO: 더 이상 사용되지 않는 realm(namespace) 정리: 일정 주기로 realm을 감시하는 백그라운드 잡?
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
 	}
 
-	// FIXME: move to validating webhook
+	if handled, result, err := r.handleFinalizer(o); handled || err != nil {
+		if err != nil {
+			logger.Error(err, "failed to handle finalizer")
+		}
+		return result, err
+	}
+
 	if err = r.validate(o); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	switch o.Status.Phase {
 	case "":
-		username := config.Config.GetString("keycloak.username")
-		password := config.Config.GetString("keycloak.password")
-
-		token, kerr := keycloak.LoginAdmin(ctx, username, password, "master")
-		if kerr != nil {
-			logger.Error(kerr, "failed to login keycloak")
+		if err := auth.GetProvider().Prepare(ctx, r.Client, o); err != nil {
+			logger.Error(err, "failed to prepare auth provider")
 			return reconcile.Result{}, err
 		}
 
-		enabled := true
-		realmName := o.Namespace
-		realm, kerr := keycloak.GetRealm(ctx, token.AccessToken, realmName)
-		if kerr != nil {
-			if apiError := kerr.(*gocloak.APIError); apiError.Code == http.StatusNotFound {
-				_, kerr = keycloak.CreateRealm(ctx, token.AccessToken, gocloak.RealmRepresentation{
-					ID:      &realmName,
-					Realm:   &realmName,
-					Enabled: &enabled,
-				})
-				if kerr != nil {
-					logger.Error(kerr, "failed to create realm")
-					return reconcile.Result{}, kerr
-				}
-			} else {
-				return ctrl.Result{}, err
-			}
-		}
-		if realm == nil {
-			return ctrl.Result{}, fmt.Errorf("nil realm")
-		}
-		logger.Info("found realm", "realmID", realm.ID, "realm", realm.Realm)
-
-		clientName := o.Name
-		protocol := "docker-v2"
-		isClientExist, kerr := func() (bool, error) {
-			clients, _kerr := keycloak.GetClients(ctx, token.AccessToken, *realm.Realm, gocloak.GetClientsParams{})
-			if _kerr != nil {
-				return false, _kerr
-			}
-
-			for _, c := range clients {
-				if *c.ClientID == clientName {
-					return true, nil
-				}
-			}
-			return false, nil
-		}()
-		if kerr != nil {
-			return reconcile.Result{}, err
-		}
-
-		if !isClientExist {
-			created, err := keycloak.CreateClient(ctx, token.AccessToken, realmName, gocloak.Client{
-				ClientID: &clientName,
-				Protocol: &protocol,
-			})
-			if err != nil {
-				logger.Error(err, "failed to create docker client")
-				return reconcile.Result{}, err
-			}
-			logger.Info("client created: " + created)
-		}
-
-		secret := &corev1.Secret{}
-		err = r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: "registry-token-key"}, secret)
-		if err != nil {
-			if k8serr.IsNotFound(err) {
-				storeConfig, _kerr := keycloak.GetKeyStoreConfig(ctx, token.AccessToken, realmName)
-				if _kerr != nil {
-					logger.Error(_kerr, "failed to get keystoreconfig")
-					return ctrl.Result{}, _kerr
-				}
-
-				var tokenCACrt string
-				for _, k := range *storeConfig.Key {
-					if *k.Kid == *storeConfig.ActiveKeys.RS256 {
-						tokenCACrt = *k.Certificate
-					}
-				}
-				if len(tokenCACrt) == 0 {
-					_kerr = fmt.Errorf("no key found")
-					logger.Error(_kerr, "failed to get realm key")
-					return ctrl.Result{}, _kerr
-				}
-				if _kerr = r.Create(ctx, &corev1.Secret{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: req.Namespace,
-						Name:      "registry-token-key",
-					},
-					Type: corev1.SecretTypeOpaque,
-					Data: map[string][]byte{
-						"ca.crt": []byte(
-							strings.Join([]string{
-								"-----BEGIN CERTIFICATE-----",
-								tokenCACrt,
-								"-----END CERTIFICATE-----"}, "\n"),
-						),
-					},
-				}); _kerr != nil {
-					logger.Error(_kerr, "failed to create registry token key secret")
-					return ctrl.Result{}, _kerr
-				}
-			} else {
-				logger.Error(err, "failed to get registry token key secret")
-				return ctrl.Result{}, err
-			}
-		}
-
-		// FIXME: DO NOT CREATE USER
-		if _, kerr = keycloak.GetUsers(ctx, token.AccessToken, realmName, gocloak.GetUsersParams{
-			Username: &o.Spec.LoginID,
-		}); kerr != nil {
-			apiError := kerr.(*gocloak.APIError)
-			switch apiError.Code {
-			case http.StatusNotFound:
-				created, _kerr := keycloak.CreateUser(ctx, token.AccessToken, realmName, gocloak.User{
-					Username: &o.Spec.LoginID,
-					Enabled:  &enabled,
-				})
-				if _kerr != nil {
-					logger.Error(_kerr, "failed to create user")
-					return reconcile.Result{}, _kerr
-				}
-				_kerr = keycloak.SetPassword(ctx, token.AccessToken, created, realmName, o.Spec.LoginPassword, false)
-				if _kerr != nil {
-					logger.Error(_kerr, "failed to set password")
-					return reconcile.Result{}, _kerr
-				}
-			case http.StatusConflict:
-				logger.Error(kerr, "failed to get user")
-			default:
-				logger.Error(kerr, "failed to get user")
-				return ctrl.Result{}, kerr
-			}
-		}
-
 		typesToManage := []status.ConditionType{
 			regv1.ConditionTypeConfigMap,
 			regv1.ConditionTypeDeployment,
@@ -287,13 +119,24 @@ func (r *RegistryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			regv1.ConditionTypeSecretTLS,
 			regv1.ConditionTypeSecretOpaque,
 			regv1.ConditionTypePod,
-			regv1.ConditionTypePvc,
+		}
+		if o.Spec.Storage.UsePVC() {
+			typesToManage = append(typesToManage, regv1.ConditionTypePvc)
 		}
 		if o.Spec.Notary.Enabled {
 			typesToManage = append(typesToManage, regv1.ConditionTypeNotary)
 		}
-		if o.Spec.RegistryService.ServiceType == "Ingress" {
+		switch o.Spec.RegistryService.ServiceType {
+		case "Ingress":
 			typesToManage = append(typesToManage, regv1.ConditionTypeIngress)
+		case regv1.RegServiceTypeGateway:
+			typesToManage = append(typesToManage, regv1.ConditionTypeRoute)
+		}
+		if o.Spec.RegistryDeployment.MaxReplicas() > 1 {
+			typesToManage = append(typesToManage, regv1.ConditionTypePodDisruptionBudget)
+		}
+		if o.Spec.RegistryDeployment.Autoscaling != nil {
+			typesToManage = append(typesToManage, regv1.ConditionTypeHorizontalPodAutoscaler)
 		}
 
 		conds := status.Conditions{}
@@ -329,7 +172,7 @@ func (r *RegistryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{Requeue: requeue}, nil
 	case regv1.StatusRunning:
 		// TODO: if spec modified, set phase empty to re-configure
-		return reconcile.Result{}, nil
+		return r.reconcileRunning(o)
 	case regv1.StatusError:
 		return reconcile.Result{}, nil
 	default:
@@ -341,6 +184,10 @@ func (r *RegistryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 func (r *RegistryReconciler) setPhaseByCondition(reg *regv1.Registry) {
 	badConditions := []status.ConditionType{}
 	for _, cond := range reg.Status.Conditions {
+		if cond.Type == regv1.ConditionTypeStorageNearlyFull || cond.Type == regv1.ConditionTypeCertificateExpiring ||
+			regctl.IsHealthCondition(cond.Type) {
+			continue
+		}
 		if reg.Status.Conditions.IsFalseFor(cond.Type) {
 			badConditions = append(badConditions, cond.Type)
 		}
@@ -366,37 +213,173 @@ func (r *RegistryReconciler) setPhaseByCondition(reg *regv1.Registry) {
 func (r *RegistryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&regv1.Registry{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.registriesOfSecret),
+		}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.registriesOfNamespace),
+		}).
+		Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.registriesOfServiceAccount),
+		}).
 		Complete(r)
 }
 
+// registriesOfSecret returns requests of registries which use the secret as login password or as tls secret
+// not issued by the operator, or whose image pull secret is the secret or its distributed copy
+func (r *RegistryReconciler) registriesOfSecret(obj handler.MapObject) []reconcile.Request {
+	labels := obj.Meta.GetLabels()
+	if name, ok := labels["registry"]; ok && labels["registry-namespace"] != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: labels["registry-namespace"]}}}
+	}
+
+	return r.registryRequests(client.InNamespace(obj.Meta.GetNamespace()), func(reg *regv1.Registry) bool {
+		if ref := reg.Spec.PasswordSecretRef; ref != nil && ref.Name == obj.Meta.GetName() {
+			return true
+		}
+		if !reg.Spec.TLS.IsOperatorIssued() && schemes.RegistryTLSSecretName(reg) == obj.Meta.GetName() {
+			return true
+		}
+		return reg.Spec.ImagePullSecretDistribution != nil &&
+			schemes.SubresourceName(reg, schemes.SubTypeRegistryDCJSecret) == obj.Meta.GetName()
+	})
+}
+
+// registriesOfNamespace returns requests of registries which distribute image pull secret
+func (r *RegistryReconciler) registriesOfNamespace(obj handler.MapObject) []reconcile.Request {
+	return r.registryRequests(&client.ListOptions{}, func(reg *regv1.Registry) bool {
+		return reg.Spec.ImagePullSecretDistribution != nil
+	})
+}
+
+// registriesOfServiceAccount returns requests of registries which distribute image pull secret to the service account
+func (r *RegistryReconciler) registriesOfServiceAccount(obj handler.MapObject) []reconcile.Request {
+	return r.registryRequests(&client.ListOptions{}, func(reg *regv1.Registry) bool {
+		dist := reg.Spec.ImagePullSecretDistribution
+		return dist != nil && utils.Contains(dist.ServiceAccounts, obj.Meta.GetName())
+	})
+}
+
+func (r *RegistryReconciler) registryRequests(opt client.ListOption, match func(reg *regv1.Registry) bool) []reconcile.Request {
+	regs := &regv1.RegistryList{}
+	if err := r.List(context.TODO(), regs, opt); err != nil {
+		r.Log.Error(err, "failed to list registries")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for i := range regs.Items {
+		if match(&regs.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: regs.Items[i].Name, Namespace: regs.Items[i].Namespace}})
+		}
+	}
+
+	return requests
+}
+
+// handleFinalizer adds the finalizer to the registry, or releases registry's data by its deletion policy,
+// cleans up the auth provider and distributed image pull secrets and removes the finalizer if it is being deleted.
+// It returns true if the registry is patched or being deleted and nothing is left to reconcile.
+func (r *RegistryReconciler) handleFinalizer(reg *regv1.Registry) (bool, ctrl.Result, error) {
+	ctx := context.TODO()
+	original := reg.DeepCopy()
+	if reg.DeletionTimestamp == nil {
+		if utils.Contains(reg.Finalizers, finalizer) {
+			return false, ctrl.Result{}, nil
+		}
+		reg.Finalizers = append(reg.Finalizers, finalizer)
+		return true, ctrl.Result{}, r.Patch(ctx, reg, client.MergeFrom(original))
+	}
+
+	if !utils.Contains(reg.Finalizers, finalizer) {
+		return true, ctrl.Result{}, nil
+	}
+	waiting, reason, err := regctl.ReleaseData(r.Client, reg)
+	if err != nil {
+		return true, ctrl.Result{}, err
+	}
+	if waiting != "" {
+		r.Log.Info(waiting, "namespace", reg.Namespace, "name", reg.Name)
+		return true, ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	switch reason {
+	case regctl.EventReasonVolumeRetained:
+		r.Recorder.Event(reg, corev1.EventTypeNormal, reason, "pvc "+schemes.RegistryPVCName(reg)+" is retained")
+	case regctl.EventReasonVolumeSnapshotted:
+		r.Recorder.Event(reg, corev1.EventTypeNormal, reason, "pvc "+schemes.RegistryPVCName(reg)+" is snapshotted to "+schemes.VolumeSnapshot(reg).GetName())
+	}
+
+	if err := auth.GetProvider().Cleanup(ctx, r.Client, reg.Namespace, reg.Name); err != nil {
+		return true, ctrl.Result{}, err
+	}
+	if err := regctl.CleanupPullSecret(r.Client, reg.Namespace, reg.Name, nil); err != nil {
+		return true, ctrl.Result{}, err
+	}
+
+	finalizers := []string{}
+	for _, f := range reg.Finalizers {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	reg.Finalizers = finalizers
+	return true, ctrl.Result{}, r.Patch(ctx, reg, client.MergeFrom(original))
+}
+
+// validate checks registry's spec in case the validating webhook is not configured,
+// and also checks the storage can be shared if registry is scaled
 func (r *RegistryReconciler) validate(reg *regv1.Registry) error {
-	// this is for checking if field is empty
-	emptyPvc := regv1.NotaryPVC{}
-	if reg.Spec.Notary.Enabled &&
-		(len(reg.Spec.Notary.ServiceType) == 0 || reg.Spec.Notary.PersistentVolumeClaim == emptyPvc) {
-		return fmt.Errorf("notary's service type or pvc field missing")
+	if errs := admission.ValidateRegistry(reg); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	if reg.Spec.RegistryDeployment.MaxReplicas() > 1 {
+		shared, err := r.isSharedStorage(reg)
+		if err != nil {
+			return err
+		}
+		if !shared {
+			return fmt.Errorf("registry can be scaled only with shared storage (S3 or ReadWriteMany pvc)")
+		}
 	}
 	return nil
 }
 
+// isSharedStorage returns true if registry's storage can be used by multiple registry pods at the same time
+func (r *RegistryReconciler) isSharedStorage(reg *regv1.Registry) (bool, error) {
+	if !reg.Spec.Storage.UsePVC() {
+		return true, nil
+	}
+
+	accessModes := []corev1.PersistentVolumeAccessMode{}
+	if reg.Spec.PersistentVolumeClaim.Exist != nil {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: reg.Spec.PersistentVolumeClaim.Exist.PvcName, Namespace: reg.Namespace}, pvc); err != nil {
+			return false, err
+		}
+		accessModes = pvc.Spec.AccessModes
+	} else if reg.Spec.PersistentVolumeClaim.Create != nil {
+		for _, mode := range reg.Spec.PersistentVolumeClaim.Create.AccessModes {
+			accessModes = append(accessModes, corev1.PersistentVolumeAccessMode(mode))
+		}
+	}
+
+	for _, mode := range accessModes {
+		if mode == corev1.ReadWriteMany {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []regctl.ResourceController {
 	logger := r.Log.WithValues("namespace", reg.Namespace, "name", reg.Name)
-	realmName := reg.Namespace
-	clientName := reg.Name
-	base := config.Config.GetString(config.ConfigTokenServiceAddr)
-	realmPath := path.Join("auth", "realms", realmName, "protocol", "docker-v2", "auth", "/")
-	issuerPath := path.Join("auth", "realms", realmName, "/")
-	authcfg := &regv1.AuthConfig{
-		Realm:   fmt.Sprintf("%s/%s", strings.TrimRight(base, "/"), strings.TrimLeft(realmPath, "/")),
-		Service: clientName,
-		Issuer:  fmt.Sprintf("%s/%s", strings.TrimRight(base, "/"), strings.TrimLeft(issuerPath, "/")),
-	}
+	authcfg := auth.GetProvider().AuthConfig(reg)
 
 	collection := []regctl.ResourceController{}
 	for _, cond := range reg.Status.Conditions {
 		switch cond.Type {
 		case regv1.ConditionTypeDeployment:
-			collection = append(collection, regctl.NewRegistryDeployment(r.Client, func() (interface{}, error) {
+			deployCtl := regctl.NewRegistryDeployment(r.Client, func() (interface{}, error) {
 				manifest, err := schemes.Deployment(reg, authcfg)
 				if err != nil {
 					return nil, err
@@ -405,7 +388,11 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 					return nil, err
 				}
 				return manifest, nil
-			}, cond.Type, logger).Require(regv1.ConditionTypeService).Require(regv1.ConditionTypePvc).Require(regv1.ConditionTypeConfigMap))
+			}, cond.Type, logger).Require(regv1.ConditionTypeService).Require(regv1.ConditionTypeConfigMap).Require(regv1.ConditionTypeSecretOpaque)
+			if reg.Spec.Storage.UsePVC() {
+				deployCtl = deployCtl.Require(regv1.ConditionTypePvc)
+			}
+			collection = append(collection, deployCtl)
 		case regv1.ConditionTypePod:
 			collection = append(collection, regctl.NewRegistryPod(r.Client, func() (interface{}, error) {
 				return nil, nil
@@ -419,6 +406,20 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 				return manifest, nil
 			}, cond.Type, logger))
 		case regv1.ConditionTypeSecretTLS:
+			if tls := reg.Spec.TLS; !tls.IsOperatorIssued() {
+				var manifest func() (interface{}, error)
+				if tls.SecretRef == nil {
+					manifest = func() (interface{}, error) {
+						manifest := schemes.RegistryCertificate(reg)
+						if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
+							return nil, err
+						}
+						return manifest, nil
+					}
+				}
+				collection = append(collection, regctl.NewRegistryExternalTLS(r.Client, manifest, cond.Type, logger).Require(regv1.ConditionTypeService))
+				break
+			}
 			collection = append(collection, regctl.NewRegistryTlsCertSecret(r.Client, func() (interface{}, error) {
 				manifest, err := schemes.TlsSecret(reg, r.Client)
 				if err != nil {
@@ -431,15 +432,26 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 			}, cond.Type, logger).Require(regv1.ConditionTypeService))
 		case regv1.ConditionTypeSecretOpaque:
 			collection = append(collection, regctl.NewRegistryCrendentialSecret(r.Client, func() (interface{}, error) {
-				manifest := schemes.CredentialSecret(reg)
-				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
+				password, err := regctl.CurrentPassword(r.Client, reg)
+				if err != nil {
+					return nil, err
+				}
+				manifest, err := schemes.CredentialSecret(reg, password)
+				if err != nil {
+					return nil, err
+				}
+				if err = controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
 					return nil, err
 				}
 				return manifest, nil
 			}, cond.Type, logger).Require(regv1.ConditionTypeService))
 		case regv1.ConditionTypeSecretDockerConfigJSON:
 			collection = append(collection, regctl.NewRegistryDCJSecret(r.Client, func() (interface{}, error) {
-				manifest := schemes.DCJSecret(reg)
+				password, err := regctl.CurrentPassword(r.Client, reg)
+				if err != nil {
+					return nil, err
+				}
+				manifest := schemes.DCJSecret(reg, password)
 				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
 					return nil, err
 				}
@@ -455,17 +467,26 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 			}, cond.Type, logger))
 		case regv1.ConditionTypeConfigMap:
 			collection = append(collection, regctl.NewRegistryConfigMap(r.Client, func() (interface{}, error) {
-				ctx := context.TODO()
-				base := &corev1.ConfigMap{}
-				if err := r.Get(ctx, types.NamespacedName{Namespace: regv1.OperatorNamespace, Name: "registry-config"}, base); err != nil {
+				return r.configMapManifest(reg)
+			}, func() (map[string][]byte, error) {
+				return r.configSecretData(reg)
+			}, cond.Type, logger).Require(regv1.ConditionTypeSecretOpaque))
+		case regv1.ConditionTypePodDisruptionBudget:
+			collection = append(collection, regctl.NewRegistryPDB(r.Client, func() (interface{}, error) {
+				manifest := schemes.PodDisruptionBudget(reg)
+				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
 					return nil, err
 				}
-				manifest := schemes.ConfigMap(reg, base.Data)
+				return manifest, nil
+			}, cond.Type, logger).Require(regv1.ConditionTypeDeployment))
+		case regv1.ConditionTypeHorizontalPodAutoscaler:
+			collection = append(collection, regctl.NewRegistryHPA(r.Client, func() (interface{}, error) {
+				manifest := schemes.HorizontalPodAutoscaler(reg)
 				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
 					return nil, err
 				}
 				return manifest, nil
-			}, cond.Type, logger))
+			}, cond.Type, logger).Require(regv1.ConditionTypeDeployment))
 		case regv1.ConditionTypeNotary:
 			collection = append(collection, regctl.NewRegistryNotary(r.Client, func() (interface{}, error) {
 				manifest, err := schemes.Notary(reg, authcfg)
@@ -480,11 +501,28 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 		case regv1.ConditionTypeIngress:
 			collection = append(collection, regctl.NewRegistryIngress(r.Client, func() (interface{}, error) {
 				manifest := schemes.Ingress(reg)
+				if manifest == nil {
+					return nil, fmt.Errorf("registry's hostname is not known yet")
+				}
+				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
+					return nil, err
+				}
+				return manifest, nil
+			}, cond.Type, logger).Require(regv1.ConditionTypeSecretTLS))
+		case regv1.ConditionTypeRoute:
+			collection = append(collection, regctl.NewRegistryRoute(r.Client, func() (interface{}, error) {
+				manifest := schemes.RegistryRoute(reg)
+				if manifest == nil {
+					return nil, fmt.Errorf("registry's hostname is not known yet")
+				}
 				if err := controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
 					return nil, err
 				}
 				return manifest, nil
 			}, cond.Type, logger).Require(regv1.ConditionTypeSecretTLS))
+		case regv1.ConditionTypeStorageNearlyFull, regv1.ConditionTypeCertificateExpiring,
+			regv1.ConditionTypeAPIReachable, regv1.ConditionTypeAuthWorking, regv1.ConditionTypeStorageWritable, regv1.ConditionTypeNotaryHealthy:
+			// informational condition updated by reconcileRunning
 		default:
 			logger.Info("[WARN] Unknown condition: " + string(cond.Type))
 		}
@@ -492,3 +530,449 @@ func (r *RegistryReconciler) getComponentControllerList(reg *regv1.Registry) []r
 
 	return collection
 }
+
+// getProxyConfig returns remote registry settings of pull-through cache from the referenced ExternalRegistry
+func (r *RegistryReconciler) getProxyConfig(reg *regv1.Registry) (*schemes.RegistryProxyConfig, error) {
+	if reg.Spec.Proxy == nil {
+		return nil, nil
+	}
+
+	exreg := &regv1.ExternalRegistry{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: reg.Spec.Proxy.ExternalRegistry, Namespace: reg.Namespace}, exreg); err != nil {
+		return nil, err
+	}
+
+	proxy := &schemes.RegistryProxyConfig{RemoteURL: exreg.Spec.RegistryURL}
+	if exreg.Spec.RegistryType == regv1.RegistryTypeDockerHub {
+		proxy.RemoteURL = image.DefaultServer
+	}
+
+	if exreg.Spec.LoginID == "" && exreg.Status.LoginSecret == "" {
+		return proxy, nil
+	}
+	if exreg.Status.LoginSecret == "" {
+		return nil, regv1.MakeRegistryError("ExternalRegistry's login secret is not ready")
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: exreg.Status.LoginSecret, Namespace: exreg.Namespace}, secret); err != nil {
+		return nil, err
+	}
+	basic, err := utils.ParseBasicAuth(secret, proxy.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+	proxy.Username, proxy.Password = utils.DecodeBasicAuth(basic)
+
+	return proxy, nil
+}
+
+// getNotificationHeaders returns the authorization header of the operator's notification endpoint,
+// and reads header values of user-defined notification endpoints from secrets
+func (r *RegistryReconciler) getNotificationHeaders(reg *regv1.Registry) (schemes.RegistryNotificationHeaders, error) {
+	token, err := regctl.NotificationToken(r.Client, reg)
+	if err != nil {
+		return nil, err
+	}
+	headers := schemes.RegistryNotificationHeaders{
+		regv1.OperatorNotificationEndpoint: {"Authorization": "Bearer " + token},
+	}
+	if reg.Spec.Notifications == nil {
+		return headers, nil
+	}
+
+	for _, e := range reg.Spec.Notifications.Endpoints {
+		for _, h := range e.Headers {
+			if h.ValueFrom == nil {
+				continue
+			}
+			secret := &corev1.Secret{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: h.ValueFrom.Name, Namespace: reg.Namespace}, secret); err != nil {
+				return nil, err
+			}
+			value, ok := secret.Data[h.ValueFrom.Key]
+			if !ok {
+				return nil, regv1.MakeRegistryError(fmt.Sprintf("key %s is not found in secret %s", h.ValueFrom.Key, h.ValueFrom.Name))
+			}
+			if headers[e.Name] == nil {
+				headers[e.Name] = map[string]string{}
+			}
+			headers[e.Name][h.Name] = string(value)
+		}
+	}
+
+	return headers, nil
+}
+
+// baseConfigMap returns the operator's configmap which has the base config.yml of registries
+func (r *RegistryReconciler) baseConfigMap() (*corev1.ConfigMap, error) {
+	base := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: regv1.OperatorNamespace, Name: schemes.DefaultConfigMapName}, base); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// registryConfigMap returns registry's configmap in use, or nil if it is not created yet
+func (r *RegistryReconciler) registryConfigMap(reg *regv1.Registry) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: reg.Namespace, Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryConfigmap)}, cm); err != nil {
+		if k8serr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cm, nil
+}
+
+// configMapManifest returns registry's configmap generated from the base config and registry's spec
+func (r *RegistryReconciler) configMapManifest(reg *regv1.Registry) (*corev1.ConfigMap, error) {
+	base, err := r.baseConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := r.getProxyConfig(reg)
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.registryConfigMap(reg)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := schemes.ConfigMap(reg, base.Data, proxy, current)
+	if err != nil {
+		return nil, err
+	}
+	if err = controllerutil.SetControllerReference(reg, manifest, r.Scheme); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// configSecretData returns the settings of registry's config.yml which are kept in credential secret
+func (r *RegistryReconciler) configSecretData(reg *regv1.Registry) (map[string][]byte, error) {
+	base, err := r.baseConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := r.getProxyConfig(reg)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := r.getNotificationHeaders(reg)
+	if err != nil {
+		return nil, err
+	}
+	return schemes.ConfigSecretData(reg, base.Data, proxy, headers)
+}
+
+// reconcileRunning handles running registry: expands pvc, rotates login password, applies config changes and reports storage usage and cache statistics periodically
+func (r *RegistryReconciler) reconcileRunning(reg *regv1.Registry) (ctrl.Result, error) {
+	logger := r.Log.WithValues("namespace", reg.Namespace, "name", reg.Name)
+	origin := reg.Status.DeepCopy()
+	requeueAfter := time.Duration(0)
+	requeue := func(after time.Duration) {
+		if requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+	// status update triggers reconcile again, so collect statistics only when the period has passed
+	schedule := func(last *metav1.Time, period time.Duration) bool {
+		next := period
+		if last != nil {
+			if elapsed := time.Since(last.Time); elapsed < period {
+				next = period - elapsed
+			}
+		}
+		requeue(next)
+		return last == nil || time.Since(last.Time) >= period
+	}
+
+	if err := r.applyScaling(reg); err != nil {
+		logger.Error(err, "failed to apply replicas and autoscaling")
+	}
+	if err := regctl.RefreshReplicas(r.Client, reg, logger); err != nil {
+		logger.Error(err, "failed to refresh replicas")
+	}
+
+	if reg.Spec.Storage.UsePVC() {
+		if err := regctl.ExpandPVC(r.Client, reg); err != nil {
+			logger.Error(err, "failed to expand pvc")
+		}
+
+		var last *metav1.Time
+		if reg.Status.StorageUsage != nil {
+			last = &reg.Status.StorageUsage.LastUpdated
+		}
+		if schedule(last, config.Config.GetDuration(config.ConfigRegistryStorageUsagePeriod)) {
+			if err := regctl.UpdateStorageUsage(r.Client, reg); err != nil {
+				logger.Error(err, "failed to collect storage usage")
+			}
+		}
+	}
+
+	if reg.Spec.Proxy != nil {
+		var last *metav1.Time
+		if reg.Status.Proxy != nil {
+			last = &reg.Status.Proxy.LastUpdated
+		}
+		if schedule(last, config.Config.GetDuration(config.ConfigRegistryProxyStatsPeriod)) {
+			if err := r.updateProxyStatus(reg); err != nil {
+				logger.Error(err, "failed to collect proxy cache statistics")
+			}
+		}
+	}
+
+	// garbage collection is not running in this operator anymore, e.g. since the operator is restarted
+	if gc := reg.Status.GarbageCollection; gc != nil && gc.StartTime != nil && !isGarbageCollecting(reg) {
+		if err := regctl.EndInterruptedGarbageCollection(r.Client, reg); err != nil {
+			logger.Error(err, "failed to end read-only window of interrupted garbage collection")
+		} else {
+			r.Recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonGarbageCollectionFailed, reg.Status.GarbageCollection.LastReport.Error)
+		}
+	}
+
+	if reg.Spec.GarbageCollection != nil {
+		due, next, err := regctl.GarbageCollectionSchedule(reg, time.Now())
+		if err != nil {
+			logger.Error(err, "failed to schedule garbage collection")
+		} else {
+			if due {
+				if err := r.scheduleGarbageCollection(reg); err != nil {
+					return ctrl.Result{}, err
+				}
+				r.startGarbageCollection(reg.DeepCopy())
+			}
+			requeue(time.Until(next))
+		}
+	}
+
+	reason, next, err := regctl.ReconcileCredential(r.Client, reg, time.Now())
+	if err != nil {
+		logger.Error(err, "failed to reconcile login credential")
+	} else if reason != "" {
+		r.Recorder.Event(reg, corev1.EventTypeNormal, reason, "login password of registry is changed")
+	}
+	if next > 0 {
+		requeue(next)
+	}
+
+	if len(reg.Spec.CustomConfigYml) == 0 {
+		manifest, err := r.configMapManifest(reg)
+		var data map[string][]byte
+		if err == nil {
+			data, err = r.configSecretData(reg)
+		}
+		if err == nil {
+			var restarted bool
+			if restarted, err = regctl.ApplyConfig(r.Client, reg, manifest, data); restarted {
+				r.Recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonConfigChanged, "registry config is changed and registry is restarted")
+			}
+		}
+		if err != nil {
+			logger.Error(err, "failed to apply registry config")
+		}
+	}
+
+	renewed, next, err := regctl.RenewCertificate(r.Client, reg, time.Now())
+	if err != nil {
+		logger.Error(err, "failed to renew certificate")
+		r.Recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonCertificateRenewalFailed, "failed to renew tls certificate: "+err.Error())
+	} else if renewed {
+		r.Recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonCertificateRenewed, "tls certificate is renewed and registry is restarted")
+	}
+	if next > 0 {
+		requeue(next)
+	}
+
+	if reg.Spec.ImagePullSecretDistribution != nil {
+		if err := regctl.DistributePullSecret(r.Client, reg); err != nil {
+			logger.Error(err, "failed to distribute image pull secret")
+		}
+	} else if reg.Status.ImagePullSecretDistribution != nil {
+		if err := regctl.CleanupPullSecret(r.Client, reg.Namespace, reg.Name, nil); err != nil {
+			logger.Error(err, "failed to clean up distributed image pull secrets")
+		} else {
+			reg.Status.ImagePullSecretDistribution = nil
+		}
+	}
+
+	var lastProbe *metav1.Time
+	if reg.Status.HealthProbe != nil {
+		lastProbe = &reg.Status.HealthProbe.LastUpdated
+	}
+	if schedule(lastProbe, config.Config.GetDuration(config.ConfigRegistryHealthProbePeriod)) {
+		// status includes proxy mode which rejects uploads, and spec and quota are checked since status may not be updated yet
+		readOnly := reg.Status.ReadOnly || reg.Spec.ReadOnly || reg.Status.Quota.IsExceeded() || isGarbageCollecting(reg)
+		for _, cond := range regctl.ProbeHealth(r.Client, reg, readOnly) {
+			switch cond.Status {
+			case corev1.ConditionTrue:
+				r.Recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonHealthCheckPassed, string(cond.Type)+" is true")
+			case corev1.ConditionFalse:
+				r.Recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonHealthCheckFailed, string(cond.Type)+" is false: "+cond.Message)
+			}
+		}
+		reg.Status.HealthProbe = &regv1.HealthProbeStatus{LastUpdated: metav1.Now()}
+	}
+
+	// registry quota can be lifted by deleting images or raising quota
+	changed, err := r.updateRegistryQuota(reg)
+	if err != nil {
+		logger.Error(err, "failed to update registry quota")
+	} else if changed {
+		readOnly := reg.Spec.ReadOnly || reg.Status.Quota.IsExceeded()
+		// garbage collection restores read-only mode by itself when it is finished
+		if !isGarbageCollecting(reg) {
+			if err := regctl.SetDeploymentReadOnly(r.Client, reg, readOnly); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if readOnly {
+			r.Recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonQuotaExceeded, reg.Status.Quota.Message+", registry is switched to read-only")
+		} else {
+			r.Recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonQuotaRecovered, "registry is switched back from read-only")
+		}
+	}
+
+	if !reflect.DeepEqual(origin, &reg.Status) {
+		if err := r.Status().Update(context.TODO(), reg); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// applyScaling applies replicas, autoscaling and anti-affinity changed while the registry is running,
+// and keeps conditions of the pdb and the hpa only while they are needed
+func (r *RegistryReconciler) applyScaling(reg *regv1.Registry) error {
+	var pdb *policyv1beta1.PodDisruptionBudget
+	if reg.Spec.RegistryDeployment.MaxReplicas() > 1 {
+		pdb = schemes.PodDisruptionBudget(reg)
+		if err := controllerutil.SetControllerReference(reg, pdb, r.Scheme); err != nil {
+			return err
+		}
+	}
+	var hpa *autoscalingv1.HorizontalPodAutoscaler
+	if reg.Spec.RegistryDeployment.Autoscaling != nil {
+		hpa = schemes.HorizontalPodAutoscaler(reg)
+		if err := controllerutil.SetControllerReference(reg, hpa, r.Scheme); err != nil {
+			return err
+		}
+	}
+	if err := regctl.ApplyScaling(r.Client, reg, pdb, hpa); err != nil {
+		return err
+	}
+
+	for condType, needed := range map[status.ConditionType]bool{
+		regv1.ConditionTypePodDisruptionBudget:     pdb != nil,
+		regv1.ConditionTypeHorizontalPodAutoscaler: hpa != nil,
+	} {
+		if needed {
+			reg.Status.Conditions.SetCondition(status.Condition{Type: condType, Status: corev1.ConditionTrue, Message: "Success"})
+		} else {
+			reg.Status.Conditions.RemoveCondition(condType)
+		}
+	}
+	return nil
+}
+
+// updateRegistryQuota sets quota status of the registry, and returns true if it should be switched to or from read-only mode
+func (r *RegistryReconciler) updateRegistryQuota(reg *regv1.Registry) (bool, error) {
+	var regClient regctl.ImageDetailsGetter
+	if reg.Spec.Quota != nil {
+		c, err := inter.GetClient(r.Client, reg, r.Scheme)
+		if err != nil {
+			return false, err
+		}
+		regClient = c
+	}
+
+	return regctl.UpdateRegistryQuota(r.Client, reg, regClient)
+}
+
+// updateProxyStatus updates cache statistics of pull-through cache
+func (r *RegistryReconciler) updateProxyStatus(reg *regv1.Registry) error {
+	proxy, err := r.getProxyConfig(reg)
+	if err != nil {
+		return err
+	}
+
+	return regctl.UpdateProxyStatus(r.Client, reg, proxy.RemoteURL)
+}
+
+// garbageCollecting holds registries whose garbage collection is running
+var garbageCollecting sync.Map
+
+func isGarbageCollecting(reg *regv1.Registry) bool {
+	_, ok := garbageCollecting.Load(types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace})
+	return ok
+}
+
+// scheduleGarbageCollection records the scheduled time of garbage collection before it is started.
+// The status is patched on its own with optimistic lock, so that garbage collection is not started twice
+// by a stale registry even if the status update at the end of reconcile fails.
+func (r *RegistryReconciler) scheduleGarbageCollection(reg *regv1.Registry) error {
+	origin := reg.DeepCopy()
+	now := metav1.Now()
+	if reg.Status.GarbageCollection == nil {
+		reg.Status.GarbageCollection = &regv1.GarbageCollectionStatus{}
+	}
+	reg.Status.GarbageCollection.LastScheduledTime = &now
+	if !isGarbageCollecting(reg) {
+		reg.Status.GarbageCollection.StartTime = &now
+	}
+	if err := r.Status().Patch(context.TODO(), reg, client.MergeFromWithOptions(origin, client.MergeFromWithOptimisticLock{})); err != nil {
+		reg.Status.GarbageCollection = origin.Status.GarbageCollection
+		return err
+	}
+
+	return nil
+}
+
+// startGarbageCollection runs garbage collection in background and records its report in registry status
+func (r *RegistryReconciler) startGarbageCollection(reg *regv1.Registry) {
+	key := types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace}
+	logger := r.Log.WithValues("namespace", reg.Namespace, "name", reg.Name)
+	if _, running := garbageCollecting.LoadOrStore(key, true); running {
+		logger.Info("garbage collection is already running")
+		return
+	}
+
+	go func() {
+		defer garbageCollecting.Delete(key)
+
+		logger.Info("start garbage collection")
+		report := regctl.GarbageCollect(r.Client, reg)
+		if report.Error != "" {
+			r.Recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonGarbageCollectionFailed, report.Error)
+		} else {
+			r.Recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonGarbageCollected,
+				fmt.Sprintf("%d blobs and %d manifests are deleted, %s reclaimed (dry-run: %t)",
+					report.BlobsDeleted, report.ManifestsDeleted, reclaimedBytes(report), report.DryRun))
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latest := &regv1.Registry{}
+			if err := r.Get(context.TODO(), key, latest); err != nil {
+				return err
+			}
+			if latest.Status.GarbageCollection == nil {
+				latest.Status.GarbageCollection = &regv1.GarbageCollectionStatus{}
+			}
+			latest.Status.GarbageCollection.LastReport = report
+			latest.Status.GarbageCollection.StartTime = nil
+			return r.Status().Update(context.TODO(), latest)
+		}); err != nil {
+			logger.Error(err, "failed to update garbage collection report")
+		}
+	}()
+}
+
+// reclaimedBytes returns the reclaimed size in the report, which is unknown for storages other than filesystem
+func reclaimedBytes(report *regv1.GarbageCollectionReport) string {
+	if report.ReclaimedBytes == nil {
+		return "unknown bytes"
+	}
+	return fmt.Sprintf("%d bytes", *report.ReclaimedBytes)
+}
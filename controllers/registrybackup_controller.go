@@ -0,0 +1,113 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/backupctl"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+)
+
+// RegistryBackupReconciler reconciles a RegistryBackup object
+type RegistryBackupReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=tmax.io,resources=registrybackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tmax.io,resources=registrybackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=tmax.io,resources=signerkeys,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;delete
+
+func (r *RegistryBackupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("registrybackup", req.NamespacedName)
+
+	backup := &regv1.RegistryBackup{}
+	if err := r.Get(context.TODO(), req.NamespacedName, backup); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "")
+		return ctrl.Result{}, err
+	}
+
+	if backupctl.IsFinished(backup.Status.State) {
+		return ctrl.Result{}, nil
+	}
+
+	original := backup.DeepCopy()
+	if err := r.reconcileJob(backup); err != nil {
+		reqLogger.Error(err, "failed to back up registry")
+		now := metav1.Now()
+		backup.Status.State = regv1.RegistryJobStateFailed
+		backup.Status.Message = err.Error()
+		backup.Status.CompletionTime = &now
+	}
+
+	if err := r.Status().Patch(context.TODO(), backup, client.MergeFrom(original)); err != nil {
+		reqLogger.Error(err, "failed to patch status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileJob creates the registry job which backs up the registry and syncs its state
+func (r *RegistryBackupReconciler) reconcileJob(backup *regv1.RegistryBackup) error {
+	if err := backupctl.ValidateStorage(&backup.Spec.Storage); err != nil {
+		return err
+	}
+
+	reg := &regv1.Registry{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: backup.Spec.Registry, Namespace: backup.Namespace}, reg); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("registry %s is not found", backup.Spec.Registry)
+		}
+		return err
+	}
+
+	job, err := backupctl.GetOrCreateJob(r.Client, r.Scheme, backup, schemes.RegistryBackupJob(backup))
+	if err != nil {
+		return err
+	}
+
+	status := backupctl.StatusOf(job)
+	backup.Status.State = status.State
+	backup.Status.Message = status.Message
+	backup.Status.CompletionTime = status.CompletionTime
+
+	return nil
+}
+
+func (r *RegistryBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&regv1.RegistryBackup{}).
+		Owns(&regv1.RegistryJob{}).
+		Complete(r)
+}
@@ -0,0 +1,102 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/backupctl"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+)
+
+// RegistryRestoreReconciler reconciles a RegistryRestore object
+type RegistryRestoreReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=tmax.io,resources=registryrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tmax.io,resources=registryrestores/status,verbs=get;update;patch
+
+func (r *RegistryRestoreReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("registryrestore", req.NamespacedName)
+
+	restore := &regv1.RegistryRestore{}
+	if err := r.Get(context.TODO(), req.NamespacedName, restore); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "")
+		return ctrl.Result{}, err
+	}
+
+	if backupctl.IsFinished(restore.Status.State) {
+		return ctrl.Result{}, nil
+	}
+
+	original := restore.DeepCopy()
+	if err := r.reconcileJob(restore); err != nil {
+		reqLogger.Error(err, "failed to restore registry")
+		now := metav1.Now()
+		restore.Status.State = regv1.RegistryJobStateFailed
+		restore.Status.Message = err.Error()
+		restore.Status.CompletionTime = &now
+	}
+
+	if err := r.Status().Patch(context.TODO(), restore, client.MergeFrom(original)); err != nil {
+		reqLogger.Error(err, "failed to patch status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileJob creates the registry job which restores the registry and syncs its state.
+// The registry may not exist yet, since the job creates it from the backup.
+func (r *RegistryRestoreReconciler) reconcileJob(restore *regv1.RegistryRestore) error {
+	if err := backupctl.ValidateStorage(&restore.Spec.Storage); err != nil {
+		return err
+	}
+
+	job, err := backupctl.GetOrCreateJob(r.Client, r.Scheme, restore, schemes.RegistryRestoreJob(restore))
+	if err != nil {
+		return err
+	}
+
+	status := backupctl.StatusOf(job)
+	restore.Status.State = status.State
+	restore.Status.Message = status.Message
+	restore.Status.CompletionTime = status.CompletionTime
+
+	return nil
+}
+
+func (r *RegistryRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&regv1.RegistryRestore{}).
+		Owns(&regv1.RegistryJob{}).
+		Complete(r)
+}
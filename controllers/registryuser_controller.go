@@ -0,0 +1,286 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+)
+
+const (
+	registryUserPasswordSize = 16
+	registryUserPendingRetry = 10 * time.Second
+)
+
+// RegistryUserReconciler reconciles a RegistryUser object
+type RegistryUserReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=tmax.io,resources=registryusers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tmax.io,resources=registryusers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+func (r *RegistryUserReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("registryuser", req.NamespacedName)
+
+	user := &regv1.RegistryUser{}
+	if err := r.Get(context.TODO(), req.NamespacedName, user); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "")
+		return ctrl.Result{}, err
+	}
+
+	if handled, err := r.handleFinalizer(user); handled || err != nil {
+		if err != nil {
+			reqLogger.Error(err, "failed to handle finalizer")
+		}
+		return ctrl.Result{}, err
+	}
+
+	original := user.DeepCopy()
+	result, err := r.reconcileUser(user)
+	if err != nil {
+		reqLogger.Error(err, "failed to reconcile registry user")
+		user.Status.State = regv1.RegistryUserFailed
+		user.Status.Message = err.Error()
+	}
+
+	if err := r.Status().Patch(context.TODO(), user, client.MergeFrom(original)); err != nil {
+		reqLogger.Error(err, "failed to patch status")
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+// handleFinalizer adds the finalizer to the user, or revokes the user and removes the finalizer if it is being deleted.
+// It returns true if the user is patched and nothing is left to reconcile.
+func (r *RegistryUserReconciler) handleFinalizer(user *regv1.RegistryUser) (bool, error) {
+	original := user.DeepCopy()
+	if user.DeletionTimestamp == nil {
+		if utils.Contains(user.Finalizers, finalizer) {
+			return false, nil
+		}
+		user.Finalizers = append(user.Finalizers, finalizer)
+		return true, r.Patch(context.TODO(), user, client.MergeFrom(original))
+	}
+
+	if !utils.Contains(user.Finalizers, finalizer) {
+		return true, nil
+	}
+	if err := r.revoke(user); err != nil {
+		return true, err
+	}
+
+	finalizers := []string{}
+	for _, f := range user.Finalizers {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	user.Finalizers = finalizers
+	return true, r.Patch(context.TODO(), user, client.MergeFrom(original))
+}
+
+// reconcileUser provisions the user with the generated password and its dockerconfigjson secret,
+// or revokes the user if it is expired
+func (r *RegistryUserReconciler) reconcileUser(user *regv1.RegistryUser) (ctrl.Result, error) {
+	user.Status.PasswordSecret = schemes.SubresourceName(user, schemes.SubTypeRegistryUserSecret)
+	user.Status.DockerConfigSecret = schemes.SubresourceName(user, schemes.SubTypeRegistryUserDCJSecret)
+
+	if user.IsExpired(metav1.Now()) {
+		if user.Status.State != regv1.RegistryUserExpired {
+			if err := r.revoke(user); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		user.Status.State = regv1.RegistryUserExpired
+		user.Status.Message = fmt.Sprintf("expired at %s", user.Spec.ExpiresAt.Format(time.RFC3339))
+		return ctrl.Result{}, nil
+	}
+
+	reg := &regv1.Registry{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: user.Spec.Registry, Namespace: user.Namespace}, reg); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("registry %s is not found", user.Spec.Registry)
+		}
+		return ctrl.Result{}, err
+	}
+	if err := r.validateUsername(user, reg); err != nil {
+		return ctrl.Result{}, err
+	}
+	if reg.Status.Phase != string(regv1.StatusRunning) {
+		user.Status.State = regv1.RegistryUserPending
+		user.Status.Message = fmt.Sprintf("registry %s is not running", reg.Name)
+		return ctrl.Result{RequeueAfter: registryUserPendingRetry}, nil
+	}
+
+	password, created, err := r.getOrCreatePassword(user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if created || user.Status.State != regv1.RegistryUserActive {
+		if err := auth.GetProvider().ProvisionUser(context.TODO(), reg, user.GetUsername(), password); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.syncDockerConfig(user, reg, password); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	user.Status.State = regv1.RegistryUserActive
+	user.Status.Message = ""
+
+	if user.Spec.ExpiresAt != nil {
+		return ctrl.Result{RequeueAfter: time.Until(user.Spec.ExpiresAt.Time)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// validateUsername checks the username is neither the registry's login id nor used by another registry user in the namespace,
+// since users of a namespace share the realm of the auth backend
+func (r *RegistryUserReconciler) validateUsername(user *regv1.RegistryUser, reg *regv1.Registry) error {
+	username := user.GetUsername()
+	if username == reg.Spec.LoginID {
+		return fmt.Errorf("username %s is the login id of registry %s", username, reg.Name)
+	}
+
+	users := &regv1.RegistryUserList{}
+	if err := r.List(context.TODO(), users, client.InNamespace(user.Namespace)); err != nil {
+		return err
+	}
+	for _, u := range users.Items {
+		if u.Name != user.Name && u.GetUsername() == username && isOlderUser(&u, user) {
+			return fmt.Errorf("username %s is already used by registry user %s", username, u.Name)
+		}
+	}
+
+	return nil
+}
+
+// revoke deletes the user from the auth backend, unless the username belongs to another registry user
+func (r *RegistryUserReconciler) revoke(user *regv1.RegistryUser) error {
+	owned, err := r.ownsUsername(user)
+	if err != nil || !owned {
+		return err
+	}
+
+	return auth.GetProvider().RevokeUser(context.TODO(), user.Namespace, user.Spec.Registry, user.GetUsername())
+}
+
+// ownsUsername returns true if no older registry user in the namespace has the same username,
+// so that revoking the user does not revoke another one's account
+func (r *RegistryUserReconciler) ownsUsername(user *regv1.RegistryUser) (bool, error) {
+	users := &regv1.RegistryUserList{}
+	if err := r.List(context.TODO(), users, client.InNamespace(user.Namespace)); err != nil {
+		return false, err
+	}
+	for _, u := range users.Items {
+		if u.Name != user.Name && u.GetUsername() == user.GetUsername() && isOlderUser(&u, user) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// isOlderUser returns true if a is created before b. The older one owns the username.
+func isOlderUser(a, b *regv1.RegistryUser) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name < b.Name
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
+
+// getOrCreatePassword returns the password in the user's secret. If the secret is not found, a new password is generated.
+func (r *RegistryUserReconciler) getOrCreatePassword(user *regv1.RegistryUser) (string, bool, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: user.Status.PasswordSecret, Namespace: user.Namespace}, secret)
+	if err == nil {
+		return string(secret.Data[regv1.RegistryUserPasswordKey]), false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", false, err
+	}
+
+	password, err := utils.RandomSecret(registryUserPasswordSize)
+	if err != nil {
+		return "", false, err
+	}
+	secret = schemes.RegistryUserSecret(user, password)
+	if err := controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
+		return "", false, err
+	}
+	if err := r.Create(context.TODO(), secret); err != nil {
+		return "", false, err
+	}
+
+	return password, true, nil
+}
+
+// syncDockerConfig creates or updates the dockerconfigjson secret of the user
+func (r *RegistryUserReconciler) syncDockerConfig(user *regv1.RegistryUser, reg *regv1.Registry, password string) error {
+	desired := schemes.RegistryUserDCJSecret(user, reg, password)
+	if err := controllerutil.SetControllerReference(user, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return r.Create(context.TODO(), desired)
+		}
+		return err
+	}
+
+	if bytes.Equal(secret.Data[corev1.DockerConfigJsonKey], desired.Data[corev1.DockerConfigJsonKey]) {
+		return nil
+	}
+	original := secret.DeepCopy()
+	secret.Data = desired.Data
+	return r.Patch(context.TODO(), secret, client.MergeFrom(original))
+}
+
+func (r *RegistryUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&regv1.RegistryUser{}).
+		Owns(&corev1.Secret{}).
+		Complete(r)
+}
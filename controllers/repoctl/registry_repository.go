@@ -2,11 +2,15 @@ package repoctl
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -88,8 +92,10 @@ func (r *RegistryRepository) List(c client.Client, reg *regv1.Registry) (*regv1.
 	return repoList, nil
 }
 
-func (r *RegistryRepository) Patch(c client.Client, repo *regv1.Repository, patchRepo *regv1.Repository) error {
-	originObject := client.MergeFrom(repo)
+// Patch patches the repository to patchRepo. With client.MergeFromWithOptimisticLock{}, it fails with conflict
+// if the repository is changed after it is got, so that versions changed concurrently are not overwritten.
+func (r *RegistryRepository) Patch(c client.Client, repo *regv1.Repository, patchRepo *regv1.Repository, opts ...client.MergeFromOption) error {
+	originObject := client.MergeFromWithOptions(repo, opts...)
 
 	// Patch
 	if err := c.Patch(context.TODO(), patchRepo, originObject); err != nil {
@@ -133,3 +139,81 @@ func (r *RegistryRepository) ExtDelete(c client.Client, reg *regv1.ExternalRegis
 	logger.Info("Deleted", "ExternalRegistry", reg.Name, "Repository", repo.Name, "Namespace", reg.Namespace)
 	return nil
 }
+
+// UpdateVersionStatus records details of the versions to repository status.
+// Details of versions not given are kept, and details of versions which no longer exist in spec are removed.
+func (r *RegistryRepository) UpdateVersionStatus(c client.Client, repo *regv1.Repository, details map[string]*image.ImageDetails, opts ...client.MergeFromOption) error {
+	return r.patchVersionStatus(c, repo, func(s *regv1.ImageVersionStatus) {
+		d, ok := details[s.Version]
+		if !ok {
+			return
+		}
+		pulls, lastPulledAt := s.Pulls, s.LastPulledAt
+		*s = VersionStatus(s.Version, d)
+		s.Pulls, s.LastPulledAt = pulls, lastPulledAt
+	}, opts...)
+}
+
+// AddPulls adds pull counts of the versions and updates the last time they are pulled.
+// It fails with conflict if the repository is changed after it is got, so that counts are not lost.
+func (r *RegistryRepository) AddPulls(c client.Client, repo *regv1.Repository, pulls map[string]int64, pulledAt map[string]time.Time) error {
+	return r.patchVersionStatus(c, repo, func(s *regv1.ImageVersionStatus) {
+		n, ok := pulls[s.Version]
+		if !ok {
+			return
+		}
+		s.Pulls += n
+		if t := pulledAt[s.Version]; s.LastPulledAt == nil || t.After(s.LastPulledAt.Time) {
+			s.LastPulledAt = &metav1.Time{Time: t}
+		}
+	}, client.MergeFromWithOptimisticLock{})
+}
+
+// patchVersionStatus patches status of the versions in spec order, changed by the mutate function
+func (r *RegistryRepository) patchVersionStatus(c client.Client, repo *regv1.Repository, mutate func(*regv1.ImageVersionStatus), opts ...client.MergeFromOption) error {
+	statuses := map[string]regv1.ImageVersionStatus{}
+	for _, s := range repo.Status.Versions {
+		statuses[s.Version] = s
+	}
+
+	versions := []regv1.ImageVersionStatus{}
+	for _, v := range repo.Spec.Versions {
+		s, ok := statuses[v.Version]
+		if !ok {
+			s = regv1.ImageVersionStatus{Version: v.Version}
+		}
+		mutate(&s)
+		if s.Digest == "" && s.Pulls == 0 && s.LastPulledAt == nil {
+			continue
+		}
+		versions = append(versions, s)
+	}
+	if reflect.DeepEqual(versions, repo.Status.Versions) || (len(versions) == 0 && len(repo.Status.Versions) == 0) {
+		return nil
+	}
+
+	original := repo.DeepCopy()
+	repo.Status.Versions = versions
+	if err := c.Status().Patch(context.TODO(), repo, client.MergeFromWithOptions(original, opts...)); err != nil {
+		logger.Error(err, "Unknown error patching repository status")
+		return err
+	}
+
+	logger.Info("Patched status", "Repository", repo.Name+"/"+repo.Namespace)
+	return nil
+}
+
+// VersionStatus converts image details to status of the version
+func VersionStatus(tag string, details *image.ImageDetails) regv1.ImageVersionStatus {
+	status := regv1.ImageVersionStatus{
+		Version:   tag,
+		Digest:    details.Digest,
+		MediaType: details.MediaType,
+		Size:      details.Size,
+		Labels:    details.Labels,
+	}
+	for _, p := range details.Platforms {
+		status.Platforms = append(status.Platforms, regv1.ImagePlatform{OS: p.OS, Architecture: p.Architecture, Variant: p.Variant})
+	}
+	return status
+}
@@ -61,6 +61,10 @@ func (r *RepositoryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 				return reconcile.Result{}, err
 			}
 			r.Log.Info("get_registry", "namespace", reg.Namespace, "name", reg.Name)
+			// repositories are deleted by registry's finalizer, and the images are kept or deleted by its deletion policy
+			if reg.DeletionTimestamp != nil {
+				return reconcile.Result{}, nil
+			}
 
 			repoName, _ := splitRepoCRName(req.Name)
 			r.Log.Info("repository", "name", repoName)
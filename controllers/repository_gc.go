@@ -7,6 +7,7 @@ import (
 	"github.com/tmax-cloud/registry-operator/internal/utils"
 	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/controllers/regctl"
@@ -43,9 +44,7 @@ func sweepImages(c client.Client, reg *regv1.Registry, scheme *runtime.Scheme, r
 	}
 
 	logz.Info("garbage_collect")
-	if err := garbageCollect(c, reg); err != nil {
-		return err
-	}
+	garbageCollect(c, reg)
 
 	return nil
 }
@@ -76,9 +75,7 @@ func sweepRegistryRepo(c client.Client, reg *regv1.Registry, scheme *runtime.Sch
 	}
 
 	log.Info("garbage_collect")
-	if err := garbageCollect(c, reg); err != nil {
-		return err
-	}
+	garbageCollect(c, reg)
 
 	log.Info("sweep_repo_success")
 
@@ -117,21 +114,25 @@ func deleteImagesInRepo(c client.Client, reg *regv1.Registry, scheme *runtime.Sc
 	return deletedTags, nil
 }
 
-func garbageCollect(c client.Client, reg *regv1.Registry) error {
-	podName, err := regctl.PodName(c, reg)
-	if err != nil {
-		return err
+// garbageCollect deletes blobs of deleted images in background, in registry's read-only window.
+// It is left to the scheduled garbage collection if any, and skipped if garbage collection is already running.
+func garbageCollect(c client.Client, reg *regv1.Registry) {
+	if gc := reg.Spec.GarbageCollection; gc != nil && !gc.DryRun {
+		log.Info("left to scheduled garbage collection")
+		return
 	}
-
-	cmder := inter.NewCommander(podName, reg.Namespace)
-	out, err := cmder.GarbageCollect()
-	if err != nil {
-		log.Error(err, "exec")
-		return err
+	key := types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace}
+	if _, running := garbageCollecting.LoadOrStore(key, true); running {
+		log.Info("garbage collection is already running")
+		return
 	}
 
-	log.Info("exec", "stdout", out.Outbuf.String(), "stderr", out.Errbuf.String())
-	return nil
+	go func() {
+		defer garbageCollecting.Delete(key)
+		if err := regctl.CollectDeletedBlobs(c, reg); err != nil {
+			log.Error(err, "garbage collection failed")
+		}
+	}()
 }
 
 func patchRepo(c client.Client, reg *regv1.Registry, repo *regv1.Repository, deletedTags []string) error {
@@ -150,5 +151,6 @@ func patchRepo(c client.Client, reg *regv1.Registry, repo *regv1.Repository, del
 		return err
 	}
 
-	return nil
+	// remove details of deleted versions
+	return repoctl.UpdateVersionStatus(c, patchRepo, nil)
 }
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/retention"
+	"github.com/tmax-cloud/registry-operator/pkg/scheduler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = log.Log.WithName("retention-handler")
+
+func RegisterHandler(mgr ctrl.Manager, s *scheduler.Scheduler) error {
+	h := NewRetentionHandler(mgr.GetClient(), mgr.GetScheme())
+	if err := s.RegisterHandler(v1.JobTypeRetentionPolicy, h); err != nil {
+		logger.Error(err, "unable to register handler", "type", v1.JobTypeRetentionPolicy)
+		return err
+	}
+	return nil
+}
+
+// NewRetentionHandler returns a new handler to run retention policy
+func NewRetentionHandler(k8sClient client.Client, scheme *runtime.Scheme) *RetentionHandler {
+	return &RetentionHandler{
+		k8sClient: k8sClient,
+		scheme:    scheme,
+	}
+}
+
+// RetentionHandler contains objects to use in handle function
+type RetentionHandler struct {
+	k8sClient client.Client
+	scheme    *runtime.Scheme
+}
+
+// Handle selects tags of the registry by the retention policy and marks them to be deleted, unless it is dry-run.
+// Marked tags are deleted by the repository controller.
+func (h *RetentionHandler) Handle(object types.NamespacedName) error {
+	policy := &v1.RetentionPolicy{}
+	if err := h.k8sClient.Get(context.TODO(), object, policy); err != nil {
+		logger.Error(err, "failed to get retention policy")
+		return err
+	}
+
+	reg := &v1.Registry{}
+	if err := h.k8sClient.Get(context.TODO(), types.NamespacedName{Name: policy.Spec.Registry, Namespace: object.Namespace}, reg); err != nil {
+		logger.Error(err, "failed to get registry")
+		return err
+	}
+	if reg.Status.Phase != string(v1.StatusRunning) {
+		return fmt.Errorf("registry %s is not running", reg.Name)
+	}
+
+	rules, err := retention.NewRules(&policy.Spec)
+	if err != nil {
+		logger.Error(err, "invalid retention policy")
+		return err
+	}
+
+	repoList := &v1.RepositoryList{}
+	if err := h.k8sClient.List(context.TODO(), repoList, client.InNamespace(reg.Namespace), client.MatchingLabels{"registry": reg.Name}); err != nil {
+		logger.Error(err, "failed to list repositories")
+		return err
+	}
+
+	// images of pods in all namespaces, since images are pulled from other namespaces too
+	podList := &corev1.PodList{}
+	if err := h.k8sClient.List(context.TODO(), podList); err != nil {
+		logger.Error(err, "failed to list pods")
+		return err
+	}
+	images := retention.ImagesInUse(podList.Items)
+
+	start := metav1.Now()
+	report := &v1.RetentionReport{
+		StartTime:  &start,
+		DryRun:     policy.Spec.DryRun,
+		Candidates: []v1.RetentionCandidate{},
+	}
+	host := serverHost(reg)
+	for i := range repoList.Items {
+		repo := &repoList.Items[i]
+		if strings.HasPrefix(repo.Name, schemes.ExternalRegistryPrefix) || repo.DeletionTimestamp != nil || !rules.MatchRepository(repo.Spec.Name) {
+			continue
+		}
+		report.Repositories++
+
+		digests := map[string]string{}
+		for _, v := range repo.Status.Versions {
+			digests[v.Version] = v.Digest
+		}
+		inUse := func(tag string) bool {
+			if digest := digests[tag]; digest != "" && images[digest] {
+				return true
+			}
+			return images[retention.ImageName(host, repo.Spec.Name, tag)]
+		}
+		candidates, protected := rules.Select(repo.Spec.Versions, start.Time, inUse)
+		report.Protected += protected
+		for _, c := range candidates {
+			report.Candidates = append(report.Candidates, v1.RetentionCandidate{
+				Repository: repo.Spec.Name,
+				Tag:        c.Version,
+				CreatedAt:  c.CreatedAt,
+			})
+		}
+
+		if policy.Spec.DryRun || len(candidates) == 0 {
+			continue
+		}
+		if err := h.markDeleted(repo, candidates); err != nil {
+			logger.Error(err, "failed to mark tags to be deleted", "repository", repo.Spec.Name)
+			return err
+		}
+	}
+
+	original := policy.DeepCopy()
+	now := metav1.Now()
+	report.CompletionTime = &now
+	policy.Status.LastReport = report
+	if err := h.k8sClient.Status().Patch(context.TODO(), policy, client.MergeFrom(original)); err != nil {
+		logger.Error(err, "failed to patch status", "name", policy.Name)
+		return err
+	}
+
+	logger.Info("retention policy is run", "name", policy.Name, "candidates", len(report.Candidates), "dryRun", report.DryRun)
+	return nil
+}
+
+// markDeleted sets delete flag of the versions of the repository.
+// It patches with optimistic lock, so that versions pushed after the repository is listed are not removed.
+func (h *RetentionHandler) markDeleted(repo *v1.Repository, versions []v1.ImageVersion) error {
+	tags := map[string]bool{}
+	for _, v := range versions {
+		tags[v.Version] = true
+	}
+
+	latest := repo
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// the repository is got again if it is changed after it is listed
+		if latest == nil {
+			latest = &v1.Repository{}
+			if err := h.k8sClient.Get(context.TODO(), types.NamespacedName{Name: repo.Name, Namespace: repo.Namespace}, latest); err != nil {
+				return err
+			}
+		}
+
+		original := latest.DeepCopy()
+		for i := range latest.Spec.Versions {
+			if tags[latest.Spec.Versions[i].Version] {
+				latest.Spec.Versions[i].Delete = true
+			}
+		}
+		if err := h.k8sClient.Patch(context.TODO(), latest, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+			latest = nil
+			return err
+		}
+		return nil
+	})
+}
+
+// serverHost returns host of the registry server url
+func serverHost(reg *v1.Registry) string {
+	host := strings.TrimPrefix(reg.Status.ServerURL, "http://")
+	return strings.TrimPrefix(host, "https://")
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMarkDeleted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, v1.AddToScheme(scheme))
+
+	reg := &v1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	repo := schemes.Repository(reg, "library/app", []string{"v1", "v2"})
+	repo.ResourceVersion = "1"
+	c := fake.NewFakeClientWithScheme(scheme, repo)
+	h := NewRetentionHandler(c, scheme)
+
+	// a tag is pushed after the repository is listed
+	listed := repo.DeepCopy()
+	pushed := repo.DeepCopy()
+	pushed.Spec.Versions = append(pushed.Spec.Versions, v1.ImageVersion{Version: "v3"})
+	assert.Equal(t, nil, c.Update(context.TODO(), pushed))
+
+	assert.Equal(t, nil, h.markDeleted(listed, []v1.ImageVersion{{Version: "v1"}}))
+
+	latest := &v1.Repository{}
+	assert.Equal(t, nil, c.Get(context.TODO(), types.NamespacedName{Name: repo.Name, Namespace: repo.Namespace}, latest))
+	deleted := map[string]bool{}
+	for _, v := range latest.Spec.Versions {
+		deleted[v.Version] = v.Delete
+	}
+	assert.Equal(t, map[string]bool{"v1": true, "v2": false, "v3": false}, deleted)
+}
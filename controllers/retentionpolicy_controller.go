@@ -0,0 +1,156 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/backupctl"
+	"github.com/tmax-cloud/registry-operator/internal/admission"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+)
+
+// RetentionPolicyReconciler reconciles a RetentionPolicy object
+type RetentionPolicyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=tmax.io,resources=retentionpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tmax.io,resources=retentionpolicies/status,verbs=get;update;patch
+
+func (r *RetentionPolicyReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("retentionpolicy", req.NamespacedName)
+
+	policy := &regv1.RetentionPolicy{}
+	if err := r.Get(context.TODO(), req.NamespacedName, policy); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "")
+		return ctrl.Result{}, err
+	}
+
+	original := policy.DeepCopy()
+	if err := r.reconcileCronJob(policy); err != nil {
+		reqLogger.Error(err, "failed to reconcile cron job")
+		return ctrl.Result{}, err
+	}
+
+	// the policy is run once for each generation, so that the report of a dry-run is shown as soon as it is changed
+	if policy.Status.ObservedGeneration != policy.Generation {
+		policy.Status.ObservedGeneration = policy.Generation
+		policy.Status.State = ""
+		policy.Status.Message = ""
+		policy.Status.CompletionTime = nil
+	}
+	if !backupctl.IsFinished(policy.Status.State) {
+		if err := r.reconcileJob(policy); err != nil {
+			reqLogger.Error(err, "failed to run retention policy")
+			now := metav1.Now()
+			policy.Status.State = regv1.RegistryJobStateFailed
+			policy.Status.Message = err.Error()
+			policy.Status.CompletionTime = &now
+		}
+	}
+
+	if err := r.Status().Patch(context.TODO(), policy, client.MergeFrom(original)); err != nil {
+		reqLogger.Error(err, "failed to patch status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileJob creates the registry job which runs the policy for its current generation and syncs its state
+func (r *RetentionPolicyReconciler) reconcileJob(policy *regv1.RetentionPolicy) error {
+	if err := admission.ValidateRetentionPolicy(policy).ToAggregate(); err != nil {
+		return err
+	}
+
+	reg := &regv1.Registry{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: policy.Spec.Registry, Namespace: policy.Namespace}, reg); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("registry %s is not found", policy.Spec.Registry)
+		}
+		return err
+	}
+
+	job, err := backupctl.GetOrCreateJob(r.Client, r.Scheme, policy, schemes.RetentionPolicyJob(policy))
+	if err != nil {
+		return err
+	}
+
+	status := backupctl.StatusOf(job)
+	policy.Status.State = status.State
+	policy.Status.Message = status.Message
+	policy.Status.CompletionTime = status.CompletionTime
+
+	return nil
+}
+
+// reconcileCronJob creates or updates the registry cron job if the policy has a schedule, and deletes it otherwise
+func (r *RetentionPolicyReconciler) reconcileCronJob(policy *regv1.RetentionPolicy) error {
+	manifest := schemes.RetentionPolicyCronJob(policy)
+	cj := &regv1.RegistryCronJob{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: manifest.Name, Namespace: manifest.Namespace}, cj)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if policy.Spec.Schedule == "" {
+		if !exists {
+			return nil
+		}
+		return client.IgnoreNotFound(r.Delete(context.TODO(), cj))
+	}
+
+	if !exists {
+		if err := controllerutil.SetControllerReference(policy, manifest, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(context.TODO(), manifest)
+	}
+
+	if cj.Spec.Schedule == manifest.Spec.Schedule {
+		return nil
+	}
+	origin := cj.DeepCopy()
+	cj.Spec.Schedule = manifest.Spec.Schedule
+	return r.Patch(context.TODO(), cj, client.MergeFrom(origin))
+}
+
+func (r *RetentionPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&regv1.RetentionPolicy{}).
+		Owns(&regv1.RegistryJob{}).
+		Owns(&regv1.RegistryCronJob{}).
+		Complete(r)
+}
@@ -10,6 +10,7 @@ require (
 	github.com/docker/distribution v2.7.1+incompatible
 	github.com/docker/docker v1.4.2-0.20190916154449-92cc603036dd
 	github.com/docker/go-metrics v0.0.1 // indirect
+	github.com/docker/libtrust v0.0.0-20160708172513-aabc10ec26b7
 	github.com/fsnotify/fsnotify v1.4.9
 	github.com/fvbommel/sortorder v1.0.2
 	github.com/genuinetools/reg v0.16.1
@@ -26,12 +27,14 @@ require (
 	github.com/opencontainers/go-digest v1.0.0
 	github.com/opencontainers/image-spec v1.0.1
 	github.com/operator-framework/operator-lib v0.1.0
+	github.com/prometheus/client_golang v1.8.0
 	github.com/robfig/cron v1.2.0
 	github.com/spf13/viper v1.3.2
 	github.com/theupdateframework/notary v0.6.2-0.20200804143915-84287fd8df4f
 	go.uber.org/zap v1.16.0
 	golang.org/x/lint v0.0.0-20210508222113-6edffad5e616 // indirect
 	golang.org/x/tools v0.1.4 // indirect
+	gomodules.xyz/jsonpatch/v2 v2.1.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 	k8s.io/api v0.19.4
 	k8s.io/apimachinery v0.19.4
@@ -39,6 +42,7 @@ require (
 	k8s.io/kube-aggregator v0.19.4
 	knative.dev/pkg v0.0.0-20201127013335-0d896b5c87b8
 	sigs.k8s.io/controller-runtime v0.6.2
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
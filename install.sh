@@ -96,6 +96,7 @@ kubectl apply -f config/manager/configmap.yaml
 kubectl apply -f config/manager/keycloak_secret.yaml
 kubectl apply -f config/apiservice/apiservice.yaml
 kubectl apply -f config/webhook/mutating-webhook.yaml
+kubectl apply -f config/webhook/validating-webhook.yaml
 kubectl apply -f config/manager/manager_config.yaml
 
 kubectl apply -f config/manager/manager.yaml
@@ -0,0 +1,62 @@
+// Package admission validates and defaults tmax.io custom resources.
+// It is used by the admission webhooks of the extension api server and by controllers,
+// since the webhooks may not be configured.
+package admission
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate checks the object by its kind. Kinds which are managed by the operator are not checked.
+func Validate(obj runtime.Object) field.ErrorList {
+	switch o := obj.(type) {
+	case *regv1.Registry:
+		return ValidateRegistry(o)
+	case *regv1.ExternalRegistry:
+		return ValidateExternalRegistry(o)
+	case *regv1.ImageReplicate:
+		return ValidateImageReplicate(o)
+	case *regv1.ImageScanRequest:
+		return ValidateImageScanRequest(o)
+	case *regv1.ImageSignRequest:
+		return ValidateImageSignRequest(o)
+	case *regv1.RegistryCronJob:
+		return ValidateRegistryCronJob(o)
+	case *regv1.RegistryJob:
+		return ValidateRegistryJob(o)
+	case *regv1.RegistryBackup:
+		return ValidateRegistryBackup(o)
+	case *regv1.RegistryRestore:
+		return ValidateRegistryRestore(o)
+	case *regv1.RegistryUser:
+		return ValidateRegistryUser(o)
+	case *regv1.RepositoryPermission:
+		return ValidateRepositoryPermission(o)
+	case *regv1.RetentionPolicy:
+		return ValidateRetentionPolicy(o)
+	}
+	return nil
+}
+
+// Default sets default values of the object by its kind.
+// Namespace is the namespace of the request, since the object may not have it yet.
+func Default(obj runtime.Object, namespace string) {
+	switch o := obj.(type) {
+	case *regv1.Registry:
+		DefaultRegistry(o)
+	case *regv1.ExternalRegistry:
+		DefaultExternalRegistry(o)
+	case *regv1.ImageReplicate:
+		DefaultImageReplicate(o, namespace)
+	case *regv1.RegistryBackup:
+		DefaultRegistryBackup(o)
+	case *regv1.RegistryRestore:
+		DefaultRegistryRestore(o)
+	case *regv1.RegistryUser:
+		DefaultRegistryUser(o)
+	case *regv1.RepositoryPermission:
+		DefaultRepositoryPermission(o, namespace)
+	}
+}
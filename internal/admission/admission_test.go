@@ -0,0 +1,127 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidate(t *testing.T) {
+	type suite struct {
+		name      string
+		obj       runtime.Object
+		expFields []string
+	}
+
+	pvcReg := func(exist *regv1.ExistPvc, create *regv1.CreatePvc) *regv1.Registry {
+		reg := &regv1.Registry{}
+		reg.Spec.LoginPassword = "password"
+		reg.Spec.PersistentVolumeClaim.Exist = exist
+		reg.Spec.PersistentVolumeClaim.Create = create
+		return reg
+	}
+	image := regv1.ImageInfo{RegistryType: regv1.RegistryTypeHpcdRegistry, RegistryName: "reg", RegistryNamespace: "ns", Image: "alpine:3"}
+
+	testCases := []suite{
+		{
+			name: "pvc exist",
+			obj:  pvcReg(&regv1.ExistPvc{PvcName: "data"}, nil),
+		},
+		{
+			name:      "pvc exist and create",
+			obj:       pvcReg(&regv1.ExistPvc{PvcName: "data"}, &regv1.CreatePvc{StorageSize: "10Gi"}),
+			expFields: []string{"spec.persistentVolumeClaim"},
+		},
+		{
+			name:      "pvc bad size",
+			obj:       pvcReg(nil, &regv1.CreatePvc{StorageSize: "ten"}),
+			expFields: []string{"spec.persistentVolumeClaim.create.storageSize"},
+		},
+		{
+			name: "external registry",
+			obj: &regv1.ExternalRegistry{Spec: regv1.ExternalRegistrySpec{
+				RegistryType: regv1.RegistryTypeHarborV2, RegistryURL: "https://harbor:443", Schedule: "*/5 * * * *",
+			}},
+		},
+		{
+			name: "external registry bad type and url",
+			obj: &regv1.ExternalRegistry{Spec: regv1.ExternalRegistrySpec{
+				RegistryType: "Quay", RegistryURL: "harbor:443",
+			}},
+			expFields: []string{"spec.registryType", "spec.registryUrl"},
+		},
+		{
+			name:      "cron job bad schedule",
+			obj:       &regv1.RegistryCronJob{Spec: regv1.RegistryCronJobSpec{Schedule: "every day"}},
+			expFields: []string{"spec.schedule"},
+		},
+		{
+			name:      "replicate same image",
+			obj:       &regv1.ImageReplicate{Spec: regv1.ImageReplicateSpec{FromImage: image, ToImage: image}},
+			expFields: []string{"spec.toImage"},
+		},
+		{
+			name: "backup with both storages",
+			obj: &regv1.RegistryBackup{Spec: regv1.RegistryBackupSpec{Registry: "reg", Storage: regv1.BackupStorage{
+				PVC: &regv1.BackupPVC{ClaimName: "backup"}, S3: &regv1.S3Storage{Bucket: "backup"},
+			}}},
+			expFields: []string{"spec.storage"},
+		},
+		{
+			name: "restore from pvc path escaping the pvc",
+			obj: &regv1.RegistryRestore{Spec: regv1.RegistryRestoreSpec{Registry: "reg", Backup: "b", Storage: regv1.BackupStorage{
+				PVC: &regv1.BackupPVC{ClaimName: "backup", Path: "/backups/../'; rm -rf /'"},
+			}}},
+			expFields: []string{"spec.storage.pvc.path"},
+		},
+		{
+			name: "backup to pvc directory",
+			obj: &regv1.RegistryBackup{Spec: regv1.RegistryBackupSpec{Registry: "reg", Storage: regv1.BackupStorage{
+				PVC: &regv1.BackupPVC{ClaimName: "backup", Path: "/backups/daily"},
+			}}},
+		},
+		{
+			name: "retention policy without rules and bad regexp",
+			obj: &regv1.RetentionPolicy{Spec: regv1.RetentionPolicySpec{
+				Registry: "reg", ExcludeTags: []string{"latest", "("},
+			}},
+			expFields: []string{"spec", "spec.excludeTags[1]"},
+		},
+		{
+			name: "operator managed kind",
+			obj:  &regv1.Repository{},
+		},
+	}
+
+	for _, c := range testCases {
+		errs := Validate(c.obj)
+		fields := []string{}
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+		if len(c.expFields) == 0 {
+			assert.Equal(t, 0, len(fields), c.name)
+			continue
+		}
+		assert.Equal(t, c.expFields, fields, c.name)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Spec.PersistentVolumeClaim.Create = &regv1.CreatePvc{DeleteWithPvc: true}
+	Default(reg, "ns")
+	assert.Equal(t, regv1.DeletionPolicyDelete, reg.Spec.DeletionPolicy)
+
+	exreg := &regv1.ExternalRegistry{Spec: regv1.ExternalRegistrySpec{RegistryType: regv1.RegistryTypeDockerHub}}
+	Default(exreg, "ns")
+	assert.Equal(t, "https://registry-1.docker.io", exreg.Spec.RegistryURL)
+
+	replicate := &regv1.ImageReplicate{}
+	replicate.Spec.ToImage.RegistryNamespace = "other"
+	Default(replicate, "ns")
+	assert.Equal(t, "ns", replicate.Spec.FromImage.RegistryNamespace)
+	assert.Equal(t, "other", replicate.Spec.ToImage.RegistryNamespace)
+}
@@ -0,0 +1,64 @@
+package admission
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/robfig/cron"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var externalRegistryTypes = []string{
+	string(regv1.RegistryTypeHarborV2),
+	string(regv1.RegistryTypeDockerHub),
+	string(regv1.RegistryTypeDocker),
+}
+
+// ValidateExternalRegistry checks external registry's type, url and sync schedule
+func ValidateExternalRegistry(exreg *regv1.ExternalRegistry) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if !utils.Contains(externalRegistryTypes, string(exreg.Spec.RegistryType)) {
+		errs = append(errs, field.NotSupported(spec.Child("registryType"), exreg.Spec.RegistryType, externalRegistryTypes))
+	}
+	if err := validateURL(exreg.Spec.RegistryURL); err != "" {
+		errs = append(errs, field.Invalid(spec.Child("registryUrl"), exreg.Spec.RegistryURL, err))
+	}
+	if len(exreg.Spec.LoginID) != 0 && len(exreg.Spec.LoginPassword) == 0 {
+		errs = append(errs, field.Required(spec.Child("loginPassword"), "must be set with loginId"))
+	}
+	if len(exreg.Spec.Schedule) != 0 {
+		if _, err := cron.ParseStandard(exreg.Spec.Schedule); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("schedule"), exreg.Spec.Schedule, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// DefaultExternalRegistry sets the url of docker hub and removes trailing slashes from the url
+func DefaultExternalRegistry(exreg *regv1.ExternalRegistry) {
+	if exreg.Spec.RegistryType == regv1.RegistryTypeDockerHub && exreg.Spec.RegistryURL == "" {
+		exreg.Spec.RegistryURL = image.DefaultServer
+	}
+	exreg.Spec.RegistryURL = strings.TrimRight(exreg.Spec.RegistryURL, "/")
+}
+
+// validateURL returns the reason if the url is not an absolute http(s) url
+func validateURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err.Error()
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "scheme must be http or https"
+	}
+	if parsed.Host == "" {
+		return "host is missing"
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package admission
+
+import (
+	"path"
+
+	"github.com/genuinetools/reg/registry"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var imageRegistryTypes = append([]string{string(regv1.RegistryTypeHpcdRegistry)}, externalRegistryTypes...)
+
+// ValidateImageReplicate checks source and destination images are given and different
+func ValidateImageReplicate(replicate *regv1.ImageReplicate) field.ErrorList {
+	spec := field.NewPath("spec")
+	errs := validateImageInfo(replicate.Spec.FromImage, spec.Child("fromImage"))
+	errs = append(errs, validateImageInfo(replicate.Spec.ToImage, spec.Child("toImage"))...)
+
+	if replicate.Spec.FromImage == replicate.Spec.ToImage {
+		errs = append(errs, field.Invalid(spec.Child("toImage"), replicate.Spec.ToImage.Image, "must be different from fromImage"))
+	}
+	if len(replicate.Spec.Signer) != 0 && replicate.Spec.ToImage.RegistryType != regv1.RegistryTypeHpcdRegistry {
+		errs = append(errs, field.Forbidden(spec.Child("signer"), "is available only if toImage's registryType is HpcdRegistry"))
+	}
+
+	return errs
+}
+
+func validateImageInfo(info regv1.ImageInfo, p *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if !utils.Contains(imageRegistryTypes, string(info.RegistryType)) {
+		errs = append(errs, field.NotSupported(p.Child("registryType"), info.RegistryType, imageRegistryTypes))
+	}
+	if len(info.RegistryName) == 0 {
+		errs = append(errs, field.Required(p.Child("registryName"), ""))
+	}
+	if len(info.Image) == 0 {
+		errs = append(errs, field.Required(p.Child("image"), ""))
+	}
+	return errs
+}
+
+// DefaultImageReplicate sets namespaces of source and destination registries to the namespace of image replicate
+func DefaultImageReplicate(replicate *regv1.ImageReplicate, namespace string) {
+	if replicate.Spec.FromImage.RegistryNamespace == "" {
+		replicate.Spec.FromImage.RegistryNamespace = namespace
+	}
+	if replicate.Spec.ToImage.RegistryNamespace == "" {
+		replicate.Spec.ToImage.RegistryNamespace = namespace
+	}
+}
+
+// ValidateImageScanRequest checks images to scan can be parsed
+func ValidateImageScanRequest(isr *regv1.ImageScanRequest) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(isr.Spec.ScanTargets) == 0 {
+		errs = append(errs, field.Required(spec.Child("scanTargets"), ""))
+	}
+	if isr.Spec.MaxFixable < 0 {
+		errs = append(errs, field.Invalid(spec.Child("maxFixable"), isr.Spec.MaxFixable, "cannot be negative"))
+	}
+	for i, target := range isr.Spec.ScanTargets {
+		t := spec.Child("scanTargets").Index(i)
+		if len(target.Images) == 0 {
+			errs = append(errs, field.Required(t.Child("images"), ""))
+		}
+		for j, img := range target.Images {
+			if _, err := registry.ParseImage(path.Join(target.RegistryURL, img)); err != nil {
+				errs = append(errs, field.Invalid(t.Child("images").Index(j), img, err.Error()))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ValidateImageSignRequest checks the image to sign can be parsed and the signer is given
+func ValidateImageSignRequest(isr *regv1.ImageSignRequest) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if _, err := registry.ParseImage(isr.Spec.Image); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("image"), isr.Spec.Image, err.Error()))
+	}
+	if len(isr.Spec.Signer) == 0 {
+		errs = append(errs, field.Required(spec.Child("signer"), ""))
+	}
+
+	return errs
+}
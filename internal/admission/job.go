@@ -0,0 +1,125 @@
+package admission
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/robfig/cron"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateRegistryCronJob checks the schedule can be parsed and the job spec is valid
+func ValidateRegistryCronJob(cj *regv1.RegistryCronJob) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if _, err := cron.ParseStandard(cj.Spec.Schedule); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("schedule"), cj.Spec.Schedule, err.Error()))
+	}
+	errs = append(errs, validateRegistryJobSpec(cj.Spec.JobSpec, spec.Child("jobSpec"))...)
+
+	return errs
+}
+
+// ValidateRegistryJob checks ttl, priority and the claim of the job
+func ValidateRegistryJob(job *regv1.RegistryJob) field.ErrorList {
+	return validateRegistryJobSpec(job.Spec, field.NewPath("spec"))
+}
+
+func validateRegistryJobSpec(spec regv1.RegistryJobSpec, p *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if spec.TTL < -1 {
+		errs = append(errs, field.Invalid(p.Child("ttl"), spec.TTL, "must be greater than or equal to -1"))
+	}
+	if spec.Priority < 0 {
+		errs = append(errs, field.Invalid(p.Child("priority"), spec.Priority, "must be greater than or equal to 0"))
+	}
+	if spec.Claim != nil && len(spec.Claim.HandleObject.Name) == 0 {
+		errs = append(errs, field.Required(p.Child("claim", "handleObject", "name"), ""))
+	}
+	return errs
+}
+
+// ValidateRegistryBackup checks the registry and exactly one of backup storages are given
+func ValidateRegistryBackup(backup *regv1.RegistryBackup) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(backup.Spec.Registry) == 0 {
+		errs = append(errs, field.Required(spec.Child("registry"), ""))
+	}
+	errs = append(errs, ValidateBackupStorage(&backup.Spec.Storage, spec.Child("storage"))...)
+
+	return errs
+}
+
+// ValidateRegistryRestore checks the registry, the backup and exactly one of backup storages are given
+func ValidateRegistryRestore(restore *regv1.RegistryRestore) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(restore.Spec.Registry) == 0 {
+		errs = append(errs, field.Required(spec.Child("registry"), ""))
+	}
+	if len(restore.Spec.Backup) == 0 {
+		errs = append(errs, field.Required(spec.Child("backup"), ""))
+	}
+	errs = append(errs, ValidateBackupStorage(&restore.Spec.Storage, spec.Child("storage"))...)
+
+	return errs
+}
+
+// ValidateBackupStorage checks exactly one of backup storages is set
+func ValidateBackupStorage(storage *regv1.BackupStorage, p *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if (storage.PVC == nil) == (storage.S3 == nil) {
+		errs = append(errs, field.Invalid(p, "", "exactly one of pvc and s3 must be set"))
+	}
+	if storage.PVC != nil && len(storage.PVC.ClaimName) == 0 {
+		errs = append(errs, field.Required(p.Child("pvc", "claimName"), ""))
+	}
+	if storage.PVC != nil && len(storage.PVC.Path) != 0 && !isBackupPath(storage.PVC.Path) {
+		errs = append(errs, field.Invalid(p.Child("pvc", "path"), storage.PVC.Path, "must be a clean path in the pvc without '..', consisting of alphanumeric characters, '-', '_', '.' and '/'"))
+	}
+	if storage.S3 != nil && len(storage.S3.Bucket) == 0 {
+		errs = append(errs, field.Required(p.Child("s3", "bucket"), ""))
+	}
+	return errs
+}
+
+// backupPathRegexp is the pattern of directories in backup pvc. A leading '/' is the root of the pvc.
+var backupPathRegexp = regexp.MustCompile(`^/?[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// isBackupPath returns true if the directory is the root of the pvc, or a clean path under it
+func isBackupPath(dir string) bool {
+	if dir == "/" {
+		return true
+	}
+	if !backupPathRegexp.MatchString(dir) || path.Clean(dir) != dir {
+		return false
+	}
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultRegistryBackup sets the directory in the backup pvc
+func DefaultRegistryBackup(backup *regv1.RegistryBackup) {
+	defaultBackupStorage(&backup.Spec.Storage)
+}
+
+// DefaultRegistryRestore sets the directory in the backup pvc
+func DefaultRegistryRestore(restore *regv1.RegistryRestore) {
+	defaultBackupStorage(&restore.Spec.Storage)
+}
+
+func defaultBackupStorage(storage *regv1.BackupStorage) {
+	if storage.PVC != nil && storage.PVC.Path == "" {
+		storage.PVC.Path = "/"
+	}
+}
@@ -0,0 +1,189 @@
+package admission
+
+import (
+	"github.com/robfig/cron"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// errCredentialRotationUnsupported is the reason why credential rotation is forbidden with keycloak provider
+const errCredentialRotationUnsupported = "requires builtin token provider, since keycloak does not accept the previous password during grace period"
+
+// ValidateRegistry checks registry's spec. Checks which need other resources, like access modes of an existing pvc,
+// are left to the registry controller.
+func ValidateRegistry(reg *regv1.Registry) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if reg.Spec.LoginPassword == "" && reg.Spec.PasswordSecretRef == nil {
+		errs = append(errs, field.Required(spec.Child("loginPassword"), "either loginPassword or passwordSecretRef must be set"))
+	}
+	if rotation := reg.Spec.CredentialRotation; rotation != nil && rotation.Period.Duration <= 0 {
+		errs = append(errs, field.Invalid(spec.Child("credentialRotation", "period"), rotation.Period.Duration.String(), "must be positive"))
+	}
+	if reg.Spec.CredentialRotation != nil && !auth.KeepsPreviousPassword() {
+		errs = append(errs, field.Forbidden(spec.Child("credentialRotation"), errCredentialRotationUnsupported))
+	}
+	if dist := reg.Spec.ImagePullSecretDistribution; dist != nil {
+		if _, err := metav1.LabelSelectorAsSelector(&dist.NamespaceSelector); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("imagePullSecretDistribution", "namespaceSelector"), dist.NamespaceSelector, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateRegistryStorage(reg, spec)...)
+	errs = append(errs, validateRegistryNotary(reg, spec.Child("notary"))...)
+
+	if reg.Spec.Proxy != nil && len(reg.Spec.Proxy.ExternalRegistry) == 0 {
+		errs = append(errs, field.Required(spec.Child("proxy", "externalRegistry"), ""))
+	}
+	if gc := reg.Spec.GarbageCollection; gc != nil {
+		if _, err := cron.ParseStandard(gc.Schedule); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("garbageCollection", "schedule"), gc.Schedule, err.Error()))
+		}
+	}
+	if len(reg.Spec.ConfigOverrides) != 0 {
+		if len(reg.Spec.CustomConfigYml) != 0 {
+			errs = append(errs, field.Forbidden(spec.Child("configOverrides"), "cannot be used with customConfigYml"))
+		} else if err := schemes.ValidateConfigOverrides(reg.Spec.ConfigOverrides); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("configOverrides"), reg.Spec.ConfigOverrides, err.Error()))
+		}
+	}
+	errs = append(errs, validateNotifications(reg.Spec.Notifications, spec.Child("notifications"))...)
+	errs = append(errs, validateExposure(string(reg.Spec.RegistryService.ServiceType), reg.Spec.RegistryService.ExposureOptions, spec.Child("service"))...)
+	errs = append(errs, validateTLS(reg.Spec.TLS, spec.Child("tls"))...)
+
+	if autoscaling := reg.Spec.RegistryDeployment.Autoscaling; autoscaling != nil &&
+		autoscaling.MaxReplicas < reg.Spec.RegistryDeployment.DesiredReplicas() {
+		errs = append(errs, field.Invalid(spec.Child("registryDeployment", "autoscaling", "maxReplicas"), autoscaling.MaxReplicas, "must not be less than minReplicas"))
+	}
+
+	return errs
+}
+
+func validateRegistryStorage(reg *regv1.Registry, spec *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if reg.Spec.Storage.Type == regv1.RegistryStorageTypeS3 {
+		s3 := spec.Child("storage", "s3")
+		if reg.Spec.Storage.S3 == nil {
+			errs = append(errs, field.Required(s3, "must be set for S3 storage"))
+		} else {
+			if len(reg.Spec.Storage.S3.Bucket) == 0 {
+				errs = append(errs, field.Required(s3.Child("bucket"), ""))
+			}
+			if len(reg.Spec.Storage.S3.CredentialSecret) == 0 {
+				errs = append(errs, field.Required(s3.Child("credentialSecret"), ""))
+			}
+		}
+	}
+
+	if !reg.Spec.Storage.UsePVC() {
+		return errs
+	}
+	pvc := spec.Child("persistentVolumeClaim")
+	exist, create := reg.Spec.PersistentVolumeClaim.Exist, reg.Spec.PersistentVolumeClaim.Create
+	switch {
+	case exist == nil && create == nil:
+		errs = append(errs, field.Required(pvc, "either exist or create must be set"))
+	case exist != nil && create != nil:
+		errs = append(errs, field.Forbidden(pvc, "exist and create cannot be set together"))
+	case exist != nil && len(exist.PvcName) == 0:
+		errs = append(errs, field.Required(pvc.Child("exist", "pvcName"), ""))
+	case create != nil:
+		if _, err := resource.ParseQuantity(create.StorageSize); err != nil {
+			errs = append(errs, field.Invalid(pvc.Child("create", "storageSize"), create.StorageSize, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func validateRegistryNotary(reg *regv1.Registry, notary *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if !reg.Spec.Notary.Enabled {
+		return errs
+	}
+
+	if reg.Spec.Proxy != nil {
+		errs = append(errs, field.Forbidden(notary.Child("enabled"), "notary cannot be enabled for pull-through cache registry"))
+	}
+	if len(reg.Spec.Notary.ServiceType) == 0 {
+		errs = append(errs, field.Required(notary.Child("serviceType"), ""))
+	}
+	if reg.Spec.Notary.PersistentVolumeClaim == (regv1.NotaryPVC{}) {
+		errs = append(errs, field.Required(notary.Child("persistentVolumeClaim"), ""))
+	}
+	errs = append(errs, validateExposure(string(reg.Spec.Notary.ServiceType), reg.Spec.Notary.ExposureOptions, notary)...)
+	errs = append(errs, validateTLS(reg.Spec.Notary.TLS, notary.Child("tls"))...)
+
+	return errs
+}
+
+func validateExposure(serviceType string, opts regv1.ExposureOptions, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if serviceType == regv1.RegServiceTypeGateway && (opts.Gateway == nil || len(opts.Gateway.Name) == 0) {
+		errs = append(errs, field.Required(path.Child("gateway", "name"), "must be set for Gateway service type"))
+	}
+	return errs
+}
+
+func validateTLS(tls *regv1.TLSConfig, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if tls == nil {
+		return errs
+	}
+	if tls.SecretRef != nil && tls.IssuerRef != nil {
+		errs = append(errs, field.Forbidden(path, "secretRef and issuerRef cannot be set together"))
+	}
+	if tls.SecretRef != nil && len(tls.SecretRef.Name) == 0 {
+		errs = append(errs, field.Required(path.Child("secretRef", "name"), ""))
+	}
+	if tls.IssuerRef != nil && len(tls.IssuerRef.Name) == 0 {
+		errs = append(errs, field.Required(path.Child("issuerRef", "name"), ""))
+	}
+	return errs
+}
+
+func validateNotifications(notifications *regv1.RegistryNotifications, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if notifications == nil {
+		return errs
+	}
+
+	names := map[string]bool{}
+	for i, e := range notifications.Endpoints {
+		endpoint := path.Child("endpoints").Index(i)
+		if len(e.Name) == 0 {
+			errs = append(errs, field.Required(endpoint.Child("name"), ""))
+		} else if e.Name == regv1.OperatorNotificationEndpoint {
+			errs = append(errs, field.Invalid(endpoint.Child("name"), e.Name, "is reserved for the operator"))
+		} else if names[e.Name] {
+			errs = append(errs, field.Duplicate(endpoint.Child("name"), e.Name))
+		}
+		names[e.Name] = true
+		if len(e.URL) == 0 {
+			errs = append(errs, field.Required(endpoint.Child("url"), ""))
+		}
+		for j, h := range e.Headers {
+			header := endpoint.Child("headers").Index(j)
+			if len(h.Name) == 0 {
+				errs = append(errs, field.Required(header.Child("name"), ""))
+			}
+			if len(h.Value) == 0 && h.ValueFrom == nil {
+				errs = append(errs, field.Required(header.Child("value"), "either value or valueFrom must be set"))
+			}
+		}
+	}
+	return errs
+}
+
+// DefaultRegistry sets the deletion policy derived from deleteWithPvc explicitly
+func DefaultRegistry(reg *regv1.Registry) {
+	if reg.Spec.DeletionPolicy == "" {
+		reg.Spec.DeletionPolicy = reg.Spec.GetDeletionPolicy()
+	}
+}
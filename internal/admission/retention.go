@@ -0,0 +1,51 @@
+package admission
+
+import (
+	"path"
+	"regexp"
+
+	"github.com/robfig/cron"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateRetentionPolicy checks the registry, at least one of keepLast and olderThan,
+// and that patterns, regular expressions and the schedule can be parsed
+func ValidateRetentionPolicy(policy *regv1.RetentionPolicy) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(policy.Spec.Registry) == 0 {
+		errs = append(errs, field.Required(spec.Child("registry"), ""))
+	}
+	if policy.Spec.KeepLast == nil && policy.Spec.OlderThan == nil {
+		errs = append(errs, field.Required(spec, "at least one of keepLast and olderThan must be set"))
+	}
+	if policy.Spec.OlderThan != nil && policy.Spec.OlderThan.Duration <= 0 {
+		errs = append(errs, field.Invalid(spec.Child("olderThan"), policy.Spec.OlderThan.Duration.String(), "must be positive"))
+	}
+	for i, pattern := range policy.Spec.Repositories {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("repositories").Index(i), pattern, err.Error()))
+		}
+	}
+	errs = append(errs, validateRegexps(policy.Spec.IncludeTags, spec.Child("includeTags"))...)
+	errs = append(errs, validateRegexps(policy.Spec.ExcludeTags, spec.Child("excludeTags"))...)
+	if len(policy.Spec.Schedule) != 0 {
+		if _, err := cron.ParseStandard(policy.Spec.Schedule); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("schedule"), policy.Spec.Schedule, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func validateRegexps(exprs []string, p *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for i, expr := range exprs {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, field.Invalid(p.Index(i), expr, err.Error()))
+		}
+	}
+	return errs
+}
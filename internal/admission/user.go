@@ -0,0 +1,70 @@
+package admission
+
+import (
+	"path"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var subjectKinds = []string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind}
+
+// ValidateRegistryUser checks the registry is given and the expiry is not before the creation
+func ValidateRegistryUser(user *regv1.RegistryUser) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(user.Spec.Registry) == 0 {
+		errs = append(errs, field.Required(spec.Child("registry"), ""))
+	}
+	if user.Spec.ExpiresAt != nil && !user.CreationTimestamp.IsZero() && user.Spec.ExpiresAt.Before(&user.CreationTimestamp) {
+		errs = append(errs, field.Invalid(spec.Child("expiresAt"), user.Spec.ExpiresAt.String(), "must not be before creation"))
+	}
+
+	return errs
+}
+
+// DefaultRegistryUser sets the username to the name of registry user
+func DefaultRegistryUser(user *regv1.RegistryUser) {
+	if user.Spec.Username == "" && user.Name != "" {
+		user.Spec.Username = user.Name
+	}
+}
+
+// ValidateRepositoryPermission checks repository patterns and subjects
+func ValidateRepositoryPermission(perm *regv1.RepositoryPermission) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(perm.Spec.Registry) == 0 {
+		errs = append(errs, field.Required(spec.Child("registry"), ""))
+	}
+	for i, pattern := range perm.Spec.Repositories {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("repositories").Index(i), pattern, err.Error()))
+		}
+	}
+	for i, subject := range perm.Spec.Subjects {
+		s := spec.Child("subjects").Index(i)
+		if len(subject.Name) == 0 {
+			errs = append(errs, field.Required(s.Child("name"), ""))
+		}
+		switch subject.Kind {
+		case rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind:
+		default:
+			errs = append(errs, field.NotSupported(s.Child("kind"), subject.Kind, subjectKinds))
+		}
+	}
+
+	return errs
+}
+
+// DefaultRepositoryPermission sets namespaces of service account subjects to the namespace of repository permission
+func DefaultRepositoryPermission(perm *regv1.RepositoryPermission, namespace string) {
+	for i := range perm.Spec.Subjects {
+		if perm.Spec.Subjects[i].Kind == rbacv1.ServiceAccountKind && perm.Spec.Subjects[i].Namespace == "" {
+			perm.Spec.Subjects[i].Namespace = namespace
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CertificateReady returns whether cert-manager Certificate is ready and the message of its Ready condition
+func CertificateReady(cert *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		message, _ := cond["message"].(string)
+		return cond["status"] == string(corev1.ConditionTrue), message
+	}
+
+	return false, "certificate is not issued yet"
+}
+
+// ValidateTLSSecret checks the secret has a certificate and its private key
+func ValidateTLSSecret(secret *corev1.Secret) error {
+	if _, ok := secret.Data[corev1.TLSCertKey]; !ok {
+		return fmt.Errorf("secret %s has no %s field", secret.Name, corev1.TLSCertKey)
+	}
+	if _, ok := secret.Data[corev1.TLSPrivateKeyKey]; !ok {
+		return fmt.Errorf("secret %s has no %s field", secret.Name, corev1.TLSPrivateKeyKey)
+	}
+	if _, err := ParseCertificate(secret.Data[corev1.TLSCertKey]); err != nil {
+		return fmt.Errorf("secret %s has invalid certificate: %s", secret.Name, err.Error())
+	}
+	return nil
+}
+
+// ExternalCAData returns ca.crt of the tls secret if the certificate is not issued by the operator, since it may have its own ca.
+// It returns nil if the certificate is issued by the operator or the secret cannot be read.
+func ExternalCAData(c client.Client, tls *regv1.TLSConfig, secret types.NamespacedName) []byte {
+	if tls.IsOperatorIssued() {
+		return nil
+	}
+
+	tlsSecret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), secret, tlsSecret); err != nil {
+		return nil
+	}
+	return tlsSecret.Data[RootCACert]
+}
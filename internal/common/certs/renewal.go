@@ -0,0 +1,155 @@
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/operator-lib/status"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	regConfig "github.com/tmax-cloud/registry-operator/internal/common/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RenewedAtAnnotation is put on pod templates to roll pods out when their certificate is reissued
+const RenewedAtAnnotation = "tmax.io/certificate-renewed-at"
+
+// NeedsRenewal returns true if the certificate expires within renewBefore or is not signed by the active ca
+func NeedsRenewal(crt, ca *x509.Certificate, now time.Time, renewBefore time.Duration) bool {
+	if crt.NotAfter.Sub(now) <= renewBefore {
+		return true
+	}
+	return crt.CheckSignatureFrom(ca) != nil
+}
+
+// RenewTLSSecret reissues the certificate of tls secret with manifest, if it is needed.
+// It returns the certificate of the secret and whether it is reissued.
+func RenewTLSSecret(c client.Client, secret *corev1.Secret, manifest func() (*corev1.Secret, error), now time.Time) (*x509.Certificate, bool, error) {
+	rootCA, err := GetSystemRootCASecret(c)
+	if err != nil {
+		return nil, false, err
+	}
+	ca, _, err := ParseCA(rootCA)
+	if err != nil {
+		return nil, false, err
+	}
+
+	crt, err := ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err == nil && !NeedsRenewal(crt, ca, now, regConfig.Config.GetDuration(regConfig.ConfigCertRenewBefore)) {
+		return crt, false, nil
+	}
+
+	m, err := manifest()
+	if err != nil {
+		return crt, false, err
+	}
+	original := secret.DeepCopy()
+	secret.Data = m.Data
+	if err := c.Patch(context.TODO(), secret, client.MergeFrom(original)); err != nil {
+		return crt, false, err
+	}
+
+	crt, err = ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, true, err
+	}
+	return crt, true, nil
+}
+
+// ObserveTLSSecret returns the certificate of tls secret which is not issued by the operator,
+// and whether it is replaced after its expiry is recorded in statuses
+func ObserveTLSSecret(secret *corev1.Secret, statuses []regv1.CertificateStatus) (*x509.Certificate, bool, error) {
+	crt, err := ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, false, err
+	}
+	for _, s := range statuses {
+		if s.Secret == secret.Name {
+			return crt, !s.NotAfter.Time.Equal(crt.NotAfter), nil
+		}
+	}
+	return crt, false, nil
+}
+
+// RetainCertificateStatus drops statuses of secrets which are not used anymore
+func RetainCertificateStatus(statuses []regv1.CertificateStatus, secrets ...string) []regv1.CertificateStatus {
+	out := []regv1.CertificateStatus{}
+	for _, s := range statuses {
+		for _, name := range secrets {
+			if s.Secret == name {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// SetCertificateStatus records expiry of the certificate in secret
+func SetCertificateStatus(statuses *[]regv1.CertificateStatus, secret string, crt *x509.Certificate, renewed bool, now time.Time) {
+	cur := regv1.CertificateStatus{Secret: secret, NotAfter: metav1.NewTime(crt.NotAfter)}
+	for i, s := range *statuses {
+		if s.Secret != secret {
+			continue
+		}
+		cur.LastRenewal = s.LastRenewal
+		if renewed {
+			cur.LastRenewal = &metav1.Time{Time: now}
+		}
+		(*statuses)[i] = cur
+		return
+	}
+	if renewed {
+		cur.LastRenewal = &metav1.Time{Time: now}
+	}
+	*statuses = append(*statuses, cur)
+}
+
+// ExpiringCondition returns CertificateExpiring condition of the certificates.
+// It is true while any of them expires within the renewal threshold, which means it is not renewed in time.
+func ExpiringCondition(statuses []regv1.CertificateStatus, now time.Time) status.Condition {
+	renewBefore := regConfig.Config.GetDuration(regConfig.ConfigCertRenewBefore)
+	cond := status.Condition{
+		Type:   regv1.ConditionTypeCertificateExpiring,
+		Status: corev1.ConditionFalse,
+	}
+
+	var earliest *regv1.CertificateStatus
+	for i, s := range statuses {
+		if earliest == nil || s.NotAfter.Before(&earliest.NotAfter) {
+			earliest = &statuses[i]
+		}
+	}
+	if earliest == nil {
+		return cond
+	}
+
+	cond.Message = fmt.Sprintf("certificate of secret %s expires at %s", earliest.Secret, earliest.NotAfter.UTC().Format(time.RFC3339))
+	if earliest.NotAfter.Sub(now) <= renewBefore {
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = "ExpiresSoon"
+		if !now.Before(earliest.NotAfter.Time) {
+			cond.Reason = "Expired"
+		}
+	}
+
+	return cond
+}
+
+// NextCheck returns when to check the certificates again
+func NextCheck(statuses []regv1.CertificateStatus, now time.Time) time.Duration {
+	next := regConfig.Config.GetDuration(regConfig.ConfigCertCheckPeriod)
+	renewBefore := regConfig.Config.GetDuration(regConfig.ConfigCertRenewBefore)
+	for _, s := range statuses {
+		if until := s.NotAfter.Sub(now) - renewBefore; until > 0 && until < next {
+			next = until
+		}
+	}
+	return next
+}
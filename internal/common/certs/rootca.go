@@ -1,8 +1,8 @@
 package certs
 
 import (
+	"bytes"
 	"context"
-	"strings"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 	regConfig "github.com/tmax-cloud/registry-operator/internal/common/config"
@@ -75,13 +75,7 @@ func createRootCASecret(c client.Client, namespace string) (*corev1.Secret, erro
 		return nil, err
 	}
 
-	var crtData []byte
-	for k, v := range sysRegCA.Data {
-		if strings.Contains(k, ".crt") {
-			crtData = v
-			break
-		}
-	}
+	crtData, _ := CAData(sysRegCA)
 
 	secret := &corev1.Secret{
 		ObjectMeta: v1.ObjectMeta{
@@ -98,6 +92,30 @@ func createRootCASecret(c client.Client, namespace string) (*corev1.Secret, erro
 	return secret, nil
 }
 
+// UpdateRootCABundles updates root ca secrets of all namespaces to trust the bundle of system root ca
+func UpdateRootCABundles(c client.Client, bundle []byte) error {
+	secrets := &corev1.SecretList{}
+	if err := c.List(context.TODO(), secrets); err != nil {
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		if secret.Name != RootCASecretName || bytes.Equal(secret.Data[RootCACert], bundle) {
+			continue
+		}
+		original := secret.DeepCopy()
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[RootCACert] = bundle
+		if err := c.Patch(context.TODO(), &secret, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GetSystemKeycloakCert(c client.Client) (*corev1.Secret, error) {
 	if c == nil {
 		cli, err := client.New(config.GetConfigOrDie(), client.Options{})
@@ -0,0 +1,86 @@
+package certs
+
+import (
+	"time"
+
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NextRootCAPromoteAtAnnotation is the RFC3339 time when the next root ca replaces the active one
+const NextRootCAPromoteAtAnnotation = "tmax.io/next-ca-promote-at"
+
+// RotateRootCA rolls the root ca secret over at now.
+// A new root ca is staged renewBefore the active one expires, and it is only trusted for overlap
+// so that every component trusts it before it signs any certificate. Then it is promoted and
+// the replaced one is trusted until it expires.
+// It returns whether the secret is changed.
+func RotateRootCA(secret *corev1.Secret, now time.Time, renewBefore, overlap time.Duration) (bool, error) {
+	active, _, err := ParseCA(secret)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+
+	if crtData, ok := secret.Data[PreviousRootCACert]; ok {
+		previous, err := ParseCertificate(crtData)
+		if err != nil || !now.Before(previous.NotAfter) {
+			delete(secret.Data, PreviousRootCACert)
+			changed = true
+		}
+	}
+
+	if _, ok := secret.Data[NextRootCACert]; ok {
+		promoteAt, err := time.Parse(time.RFC3339, secret.Annotations[NextRootCAPromoteAtAnnotation])
+		if err != nil || now.Before(promoteAt) {
+			return changed, nil
+		}
+		if _, _, err := ParseKeyPair(secret.Data[NextRootCACert], secret.Data[NextRootCAPriv]); err != nil {
+			return changed, err
+		}
+
+		secret.Data[PreviousRootCACert] = secret.Data[RootCACert]
+		secret.Data[RootCACert] = secret.Data[NextRootCACert]
+		secret.Data[RootCAPriv] = secret.Data[NextRootCAPriv]
+		delete(secret.Data, NextRootCACert)
+		delete(secret.Data, NextRootCAPriv)
+		delete(secret.Annotations, NextRootCAPromoteAtAnnotation)
+		return true, nil
+	}
+
+	if active.NotAfter.Sub(now) > renewBefore {
+		return changed, nil
+	}
+
+	next, err := utils.NewCertPair(nil, true)
+	if err != nil {
+		return changed, err
+	}
+	next.SetSubject(&active.Subject)
+	next.SetValidity(active.NotAfter.Sub(active.NotBefore))
+	next.SetParent(next.Template, next.Key)
+	if err := next.CreateCertificateData(); err != nil {
+		return changed, err
+	}
+	crtPem, err := next.CertDataToPem()
+	if err != nil {
+		return changed, err
+	}
+	keyPem, err := next.KeyToPem()
+	if err != nil {
+		return changed, err
+	}
+
+	promoteAt := now.Add(overlap)
+	if active.NotAfter.Before(promoteAt) {
+		promoteAt = active.NotAfter
+	}
+	secret.Data[NextRootCACert] = crtPem
+	secret.Data[NextRootCAPriv] = keyPem
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[NextRootCAPromoteAtAnnotation] = promoteAt.UTC().Format(time.RFC3339)
+
+	return true, nil
+}
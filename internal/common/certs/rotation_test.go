@@ -0,0 +1,72 @@
+package certs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newRootCASecret(t *testing.T, validity time.Duration) *corev1.Secret {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	ca, err := utils.NewCertPair(key, true)
+	assert.Equal(t, nil, err)
+	ca.SetSubject(&pkix.Name{CommonName: "registry-ca"})
+	ca.SetValidity(validity)
+	ca.SetParent(ca.Template, ca.Key)
+	assert.Equal(t, nil, ca.CreateCertificateData())
+	crtPem, _ := ca.CertDataToPem()
+	keyPem, _ := ca.KeyToPem()
+
+	return &corev1.Secret{Data: map[string][]byte{RootCACert: crtPem, RootCAPriv: keyPem}}
+}
+
+func TestRotateRootCA(t *testing.T) {
+	secret := newRootCASecret(t, 48*time.Hour)
+	original := secret.Data[RootCACert]
+	now := time.Now()
+
+	// far from expiry
+	changed, err := RotateRootCA(secret, now, time.Hour, time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, changed)
+
+	// staged, but not promoted yet
+	changed, err = RotateRootCA(secret, now, 72*time.Hour, time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, changed)
+	assert.Equal(t, original, secret.Data[RootCACert])
+	next := secret.Data[NextRootCACert]
+	assert.NotEqual(t, 0, len(next))
+	bundle, _ := CAData(secret)
+	assert.Equal(t, string(original)+string(next), string(bundle))
+
+	changed, err = RotateRootCA(secret, now.Add(30*time.Minute), 72*time.Hour, time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, changed)
+
+	// promoted after overlap, and the replaced one is still trusted
+	changed, err = RotateRootCA(secret, now.Add(2*time.Hour), 72*time.Hour, time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, changed)
+	assert.Equal(t, next, secret.Data[RootCACert])
+	assert.Equal(t, original, secret.Data[PreviousRootCACert])
+	_, hasNext := secret.Data[NextRootCACert]
+	assert.Equal(t, false, hasNext)
+	crt, _, err := ParseCA(secret)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "registry-ca", crt.Subject.CommonName)
+
+	// the replaced one is dropped when it expires
+	changed, err = RotateRootCA(secret, now.Add(49*time.Hour), time.Hour, time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, changed)
+	_, hasPrevious := secret.Data[PreviousRootCACert]
+	assert.Equal(t, false, hasPrevious)
+}
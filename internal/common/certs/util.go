@@ -1,17 +1,86 @@
 package certs
 
 import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
 const (
 	RootCACert = "ca.crt"
 	RootCAPriv = "ca.key"
+	// NextRootCACert and NextRootCAPriv are the root ca staged to replace the active one
+	NextRootCACert = "next-ca.crt"
+	NextRootCAPriv = "next-ca.key"
+	// PreviousRootCACert is the root ca replaced by the active one. It is trusted until it expires.
+	PreviousRootCACert = "previous-ca.crt"
 )
 
+// CAData returns the certificate bundle to trust and the private key of the active CA.
+// The bundle has the active, the next and the previous CA certificates in order.
 func CAData(secret *corev1.Secret) ([]byte, []byte) {
 	if secret == nil {
 		return nil, nil
 	}
-	return secret.Data[RootCACert], secret.Data[RootCAPriv]
+
+	bundle := append([]byte{}, secret.Data[RootCACert]...)
+	for _, key := range []string{NextRootCACert, PreviousRootCACert} {
+		crt, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+		if len(bundle) > 0 && !bytes.HasSuffix(bundle, []byte("\n")) {
+			bundle = append(bundle, '\n')
+		}
+		bundle = append(bundle, crt...)
+	}
+
+	return bundle, secret.Data[RootCAPriv]
+}
+
+// ParseCA parses the certificate and the private key of CA secret
+func ParseCA(secret *corev1.Secret) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if secret == nil {
+		return nil, nil, errors.New("ca secret is nil")
+	}
+	return ParseKeyPair(secret.Data[RootCACert], secret.Data[RootCAPriv])
+}
+
+// ParseCertificate parses the first certificate of pem data
+func ParseCertificate(crtData []byte) (*x509.Certificate, error) {
+	crtBlock, _ := pem.Decode(crtData)
+	if crtBlock == nil {
+		return nil, errors.New("failed to decode certificate")
+	}
+	return x509.ParseCertificate(crtBlock.Bytes)
+}
+
+// ParseKeyPair parses pem encoded certificate and its rsa private key
+func ParseKeyPair(crtData, keyData []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	crt, err := ParseCertificate(crtData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBlock, _ := pem.Decode(keyData)
+	if keyBlock == nil {
+		return nil, nil, errors.New("failed to decode private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err == nil {
+		return crt, key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("private key is not rsa key")
+	}
+
+	return crt, rsaKey, nil
 }
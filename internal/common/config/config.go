@@ -34,6 +34,26 @@ func defaultValues() map[string]string {
 	values[ConfigNotaryDBCPU] = "0.1"
 	values[ConfigNotaryDBMemory] = "256Mi"
 	values[ConfigExternalRegistrySyncPeriod] = "*/5 * * * *"
+	values[ConfigRegistryProxyStatsPeriod] = "1m"
+	values[ConfigRegistryStorageUsagePeriod] = "5m"
+	values[ConfigRegistryStorageNearlyFullPercent] = "90"
+	values[ConfigRegistryGCRolloutTimeout] = "10m"
+	values[ConfigRegistryHealthProbePeriod] = "1m"
+	values[ConfigRegistryHealthProbeTimeout] = "10s"
+	values[ConfigCertValidity] = "8760h"
+	values[ConfigCertRenewBefore] = "720h"
+	values[ConfigCertCheckPeriod] = "1h"
+	values[ConfigRootCARenewBefore] = "2160h"
+	values[ConfigRootCAOverlap] = "168h"
+	values[ConfigIngressClassName] = "nginx-shd"
+	values[ConfigIngressControllerNamespace] = "ingress-nginx-shared"
+	values[ConfigIngressControllerService] = "ingress-nginx-shared-controller"
+	values[ConfigIngressHostnameTemplate] = "{namespace}.{name}.{component}.{domain}"
+	values[ConfigTokenServiceProvider] = "keycloak"
+	values[ConfigTokenServiceExpiration] = "5m"
+	values[ConfigRegistryEventQueueSize] = "1000"
+	values[ConfigRegistryEventWorkers] = "4"
+	values[ConfigRegistryEventMaxRetries] = "10"
 
 	// If IMAGE_REGISTRY is set, it assumes the necessary images are in the registry.
 	registry := Config.GetString(ConfigImageRegistry)
@@ -8,6 +8,10 @@ const (
 	ConfigTokenServiceAddr         = "token.url"
 	ConfigTokenServiceInsecure     = "token.insecure"
 	ConfigTokenServiceDebug        = "token.debug"
+	// ConfigTokenServiceProvider is the key to get token.provider config (keycloak or builtin)
+	ConfigTokenServiceProvider = "token.provider"
+	// ConfigTokenServiceExpiration is the key to get token.expiration config
+	ConfigTokenServiceExpiration = "token.expiration"
 	// ConfigClusterName is the key to get cluster.name config
 	ConfigClusterName = "cluster.name"
 	// ConfigImageScanSvr is the key to get clair.url config
@@ -36,8 +40,56 @@ const (
 	ConfigNotarySignerImagePullSecret = "notary.signer.image_pull_secret"
 	// ConfigNotaryDBImagePullSecret is the key to get notary.db.image_pull_secret config
 	ConfigNotaryDBImagePullSecret = "notary.db.image_pull_secret"
+	// ConfigRegistryProxyStatsPeriod is the key to get registry.proxy_stats_period config
+	ConfigRegistryProxyStatsPeriod = "registry.proxy_stats_period"
+	// ConfigRegistryStorageUsagePeriod is the key to get registry.storage_usage_period config
+	ConfigRegistryStorageUsagePeriod = "registry.storage_usage_period"
+	// ConfigRegistryStorageNearlyFullPercent is the key to get registry.storage_nearly_full_percent config
+	ConfigRegistryStorageNearlyFullPercent = "registry.storage_nearly_full_percent"
+	// ConfigRegistryGCRolloutTimeout is the key to get registry.gc_rollout_timeout config
+	ConfigRegistryGCRolloutTimeout = "registry.gc_rollout_timeout"
+	// ConfigRegistryHealthProbePeriod is the key to get registry.health_probe_period config
+	ConfigRegistryHealthProbePeriod = "registry.health_probe_period"
+	// ConfigRegistryHealthProbeTimeout is the key to get registry.health_probe_timeout config
+	ConfigRegistryHealthProbeTimeout = "registry.health_probe_timeout"
+	// ConfigCertValidity is the key to get cert.validity config
+	ConfigCertValidity = "cert.validity"
+	// ConfigCertRenewBefore is the key to get cert.renew_before config
+	ConfigCertRenewBefore = "cert.renew_before"
+	// ConfigCertCheckPeriod is the key to get cert.check_period config
+	ConfigCertCheckPeriod = "cert.check_period"
+	// ConfigRootCARenewBefore is the key to get cert.rootca_renew_before config
+	ConfigRootCARenewBefore = "cert.rootca_renew_before"
+	// ConfigRootCAOverlap is the key to get cert.rootca_overlap config
+	ConfigRootCAOverlap = "cert.rootca_overlap"
+	// ConfigIngressClassName is the key to get ingress.class_name config
+	ConfigIngressClassName = "ingress.class_name"
+	// ConfigIngressControllerNamespace is the key to get ingress.controller_namespace config
+	ConfigIngressControllerNamespace = "ingress.controller_namespace"
+	// ConfigIngressControllerService is the key to get ingress.controller_service config
+	ConfigIngressControllerService = "ingress.controller_service"
+	// ConfigIngressDomain is the key to get ingress.domain config
+	ConfigIngressDomain = "ingress.domain"
+	// ConfigIngressHostnameTemplate is the key to get ingress.hostname_template config
+	ConfigIngressHostnameTemplate = "ingress.hostname_template"
+	// ConfigNodePortAddress is the key to get nodeport.address config
+	ConfigNodePortAddress = "nodeport.address"
 	// ConfigExternalRegistrySyncPeriod is the key to get external_registry.sync_period config
 	ConfigExternalRegistrySyncPeriod = "external_registry.sync_period"
+	// ConfigRegistryEventQueueSize is the key to get registry_event.queue_size config
+	ConfigRegistryEventQueueSize = "registry_event.queue_size"
+	// ConfigRegistryEventWorkers is the key to get registry_event.workers config
+	ConfigRegistryEventWorkers = "registry_event.workers"
+	// ConfigRegistryEventMaxRetries is the key to get registry_event.max_retries config
+	ConfigRegistryEventMaxRetries = "registry_event.max_retries"
+	// ConfigRegistryEventClientCAFile is the key to get registry_event.client_ca_file config
+	ConfigRegistryEventClientCAFile = "registry_event.client_ca_file"
+	// ConfigServerTLSCertFile is the key to get server.tls_cert_file config
+	ConfigServerTLSCertFile = "server.tls_cert_file"
+	// ConfigServerTLSKeyFile is the key to get server.tls_key_file config
+	ConfigServerTLSKeyFile = "server.tls_key_file"
+	// ConfigServerTLSCAFile is the key to get server.tls_ca_file config
+	ConfigServerTLSCAFile = "server.tls_ca_file"
 
 	// ConfigRegistryCPU is the key to get registry.cpu config
 	ConfigRegistryCPU = "registry.cpu"
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	regconfig "github.com/tmax-cloud/registry-operator/internal/common/config"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -11,13 +12,9 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-const (
-	ingressControllerSVCName      = "ingress-nginx-shared-controller"
-	ingressControllerSVCNamesapce = "ingress-nginx-shared"
-)
-
 var logger logr.Logger = logf.Log.WithName("ingress controller")
 
+// GetIngressControllerSVC returns the ingress controller service set by ingress.controller_namespace and ingress.controller_service config
 func GetIngressControllerSVC() (*corev1.Service, error) {
 	c, err := client.New(config.GetConfigOrDie(), client.Options{})
 	if err != nil {
@@ -25,7 +22,7 @@ func GetIngressControllerSVC() (*corev1.Service, error) {
 	}
 
 	svc := &corev1.Service{}
-	err = c.Get(context.TODO(), types.NamespacedName{Name: ingressControllerSVCName, Namespace: ingressControllerSVCNamesapce}, svc)
+	err = c.Get(context.TODO(), controllerSVCName(), svc)
 	if err != nil {
 		return nil, err
 	}
@@ -33,16 +30,28 @@ func GetIngressControllerSVC() (*corev1.Service, error) {
 	return svc, nil
 }
 
+// GetIngressControllerIP returns the external ip(or hostname) of the ingress controller service
 func GetIngressControllerIP() string {
 	svc, err := GetIngressControllerSVC()
 	if err != nil {
-		logger.Error(err, "there is no ingress controller service", "service name", ingressControllerSVCName, "service namespace", ingressControllerSVCNamesapce)
+		name := controllerSVCName()
+		logger.Error(err, "there is no ingress controller service", "service name", name.Name, "service namespace", name.Namespace)
 		return ""
 	}
 
 	if len(svc.Status.LoadBalancer.Ingress) > 0 {
-		return svc.Status.LoadBalancer.Ingress[0].IP
+		if svc.Status.LoadBalancer.Ingress[0].IP != "" {
+			return svc.Status.LoadBalancer.Ingress[0].IP
+		}
+		return svc.Status.LoadBalancer.Ingress[0].Hostname
 	}
 
 	return ""
 }
+
+func controllerSVCName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      regconfig.Config.GetString(regconfig.ConfigIngressControllerService),
+		Namespace: regconfig.Config.GetString(regconfig.ConfigIngressControllerNamespace),
+	}
+}
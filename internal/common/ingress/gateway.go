@@ -0,0 +1,66 @@
+package ingress
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RouteAccepted returns whether Gateway API route is accepted by all of its gateways and the message if not
+func RouteAccepted(route *unstructured.Unstructured) (bool, string) {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) == 0 {
+		return false, "route is not accepted by the gateway yet"
+	}
+
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		accepted, message := false, "route is not accepted by the gateway yet"
+		for _, c := range conditions {
+			cond, ok := c.(map[string]interface{})
+			if !ok || cond["type"] != "Accepted" {
+				continue
+			}
+			accepted = cond["status"] == string(corev1.ConditionTrue)
+			message, _ = cond["message"].(string)
+		}
+		if !accepted {
+			return false, message
+		}
+	}
+
+	return true, ""
+}
+
+// RouteChanged returns whether hostnames or gateways of the route differ from the desired one.
+// Other fields are not compared, because the api server fills their default values.
+func RouteChanged(current, desired *unstructured.Unstructured) bool {
+	currentHosts, _, _ := unstructured.NestedStringSlice(current.Object, "spec", "hostnames")
+	desiredHosts, _, _ := unstructured.NestedStringSlice(desired.Object, "spec", "hostnames")
+	if !reflect.DeepEqual(currentHosts, desiredHosts) {
+		return true
+	}
+
+	return !reflect.DeepEqual(parentRefs(current), parentRefs(desired))
+}
+
+func parentRefs(route *unstructured.Unstructured) []string {
+	refs := []string{}
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := parent["name"].(string)
+		namespace, _ := parent["namespace"].(string)
+		section, _ := parent["sectionName"].(string)
+		refs = append(refs, namespace+"/"+name+"/"+section)
+	}
+	return refs
+}
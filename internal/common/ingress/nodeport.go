@@ -0,0 +1,35 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	regconfig "github.com/tmax-cloud/registry-operator/internal/common/config"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNodeAddress returns the address to reach NodePort services.
+// It is nodeport.address config, or the first node's external ip(or internal ip if there is no external one).
+func GetNodeAddress(c client.Client) (string, error) {
+	if addr := regconfig.Config.GetString(regconfig.ConfigNodePortAddress); addr != "" {
+		return addr, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(context.TODO(), nodes); err != nil {
+		return "", err
+	}
+
+	for _, addrType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for _, node := range nodes.Items {
+			for _, addr := range node.Status.Addresses {
+				if addr.Type == addrType && addr.Address != "" {
+					return addr.Address, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("there is no node address to reach NodePort service")
+}
@@ -12,6 +12,30 @@ import (
 
 func ExecCmd(podName, containerName, namespace string,
 	command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	return execCmd(podName, containerName, namespace, command, stdin, stdout, stderr, true)
+}
+
+// ExecStream executes command without tty, so that binary data can be streamed through stdin and stdout
+func ExecStream(podName, containerName, namespace string,
+	command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	return execCmd(podName, containerName, namespace, command, stdin, stdout, stderr, false)
+}
+
+// ExecStreamArgs executes the script without tty like ExecStream, passing args as positional parameters($1, $2, ...)
+// so that they are not interpreted by the shell
+func ExecStreamArgs(podName, containerName, namespace string,
+	script string, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	cmd := append([]string{"sh", "-c", script, "--"}, args...)
+	return exec(podName, containerName, namespace, cmd, stdin, stdout, stderr, false)
+}
+
+func execCmd(podName, containerName, namespace string,
+	command string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool) error {
+	return exec(podName, containerName, namespace, []string{"sh", "-c", command}, stdin, stdout, stderr, tty)
+}
+
+func exec(podName, containerName, namespace string,
+	cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool) error {
 	kubeCfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(),
 		&clientcmd.ConfigOverrides{},
@@ -25,11 +49,6 @@ func ExecCmd(podName, containerName, namespace string,
 		return err
 	}
 
-	cmd := []string{
-		"sh",
-		"-c",
-		command,
-	}
 	req := coreClient.RESTClient().Post().Resource("pods").Name(podName).
 		Namespace(namespace).SubResource("exec").Param("container", containerName)
 	option := &v1.PodExecOptions{
@@ -37,7 +56,7 @@ func ExecCmd(podName, containerName, namespace string,
 		Stdin:   true,
 		Stdout:  true,
 		Stderr:  true,
-		TTY:     true,
+		TTY:     tty,
 	}
 	if stdin == nil {
 		option.Stdin = false
@@ -46,11 +65,11 @@ func ExecCmd(podName, containerName, namespace string,
 		option,
 		scheme.ParameterCodec,
 	)
-	exec, err := remotecommand.NewSPDYExecutor(restCfg, "POST", req.URL())
+	executor, err := remotecommand.NewSPDYExecutor(restCfg, "POST", req.URL())
 	if err != nil {
 		return err
 	}
-	err = exec.Stream(remotecommand.StreamOptions{
+	err = executor.Stream(remotecommand.StreamOptions{
 		Stdin:  stdin,
 		Stdout: stdout,
 		Stderr: stderr,
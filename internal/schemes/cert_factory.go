@@ -6,6 +6,7 @@ import (
 	"net"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
 	"github.com/tmax-cloud/registry-operator/internal/utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -107,6 +108,9 @@ func (t *CertTemplate) CreateCertPair(cert Certificate, source interface{}) (*ut
 		return nil, err
 	}
 
+	if validity := config.Config.GetDuration(config.ConfigCertValidity); validity > 0 {
+		out.SetValidity(validity)
+	}
 	out.SetSubject(cert.GetSubject())
 	out.SetSubjectAltName(cert.GetSanIP(source), cert.GetSanDNS(source))
 
@@ -126,6 +130,11 @@ func (n *NotaryServerCert) GetSanIP(notary interface{}) []net.IP {
 	if len(nt.Status.ServerLoadBalancerIP) > 0 {
 		ips = append(ips, net.ParseIP(nt.Status.ServerLoadBalancerIP))
 	}
+	if nt.Spec.ServiceType == regv1.NotaryServiceTypeNodePort {
+		if ip := net.ParseIP(urlHostname(nt.Status.NotaryURL)); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
 
 	return ips
 }
@@ -134,8 +143,13 @@ func (n *NotaryServerCert) GetSanDNS(notary interface{}) []string {
 	nt := notary.(*regv1.Notary)
 
 	domains := []string{}
-	if nt.Spec.ServiceType == "Ingress" {
+	switch nt.Spec.ServiceType {
+	case regv1.NotaryServiceTypeIngress, regv1.NotaryServiceTypeGateway:
 		domains = append(domains, NotaryDomainName(nt))
+	case regv1.NotaryServiceTypeNodePort:
+		if host := urlHostname(nt.Status.NotaryURL); host != "" && net.ParseIP(host) == nil {
+			domains = append(domains, host)
+		}
 	}
 	domains = append(domains, utils.BuildServiceHostname(SubresourceName(nt, SubTypeNotaryServerService), nt.Namespace))
 
@@ -203,6 +217,11 @@ func (c *RegistryCert) GetSanIP(registry interface{}) []net.IP {
 	if len(reg.Status.LoadBalancerIP) > 0 {
 		ips = append(ips, net.ParseIP(reg.Status.LoadBalancerIP))
 	}
+	if reg.Spec.RegistryService.ServiceType == regv1.RegServiceTypeNodePort {
+		if ip := net.ParseIP(urlHostname(reg.Status.ServerURL)); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
 
 	return ips
 }
@@ -211,10 +230,15 @@ func (c *RegistryCert) GetSanDNS(registry interface{}) []string {
 	reg := registry.(*regv1.Registry)
 
 	domains := []string{}
-	if reg.Spec.RegistryService.ServiceType == "Ingress" {
+	switch reg.Spec.RegistryService.ServiceType {
+	case "Ingress", regv1.RegServiceTypeGateway:
 		domains = append(domains, RegistryDomainName(reg))
+	case regv1.RegServiceTypeNodePort:
+		if host := urlHostname(reg.Status.ServerURL); host != "" && net.ParseIP(host) == nil {
+			domains = append(domains, host)
+		}
 	}
-	domains = append(domains, utils.BuildServiceHostname(SubresourceName(reg, SubTypeNotarySignerService), reg.Namespace))
+	domains = append(domains, utils.BuildServiceHostname(SubresourceName(reg, SubTypeRegistryService), reg.Namespace))
 
 	return domains
 }
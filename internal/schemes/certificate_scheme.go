@@ -0,0 +1,83 @@
+package schemes
+
+import (
+	"net"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CertificateGVK is cert-manager's Certificate kind.
+// It is handled as unstructured not to depend on cert-manager.
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// RegistryTLSSecretName returns the name of tls secret used by registry
+func RegistryTLSSecretName(reg *regv1.Registry) string {
+	if reg.Spec.TLS != nil && reg.Spec.TLS.SecretRef != nil {
+		return reg.Spec.TLS.SecretRef.Name
+	}
+	return SubresourceName(reg, SubTypeRegistryTLSSecret)
+}
+
+// NotaryServerTLSSecretName returns the name of tls secret used by notary server
+func NotaryServerTLSSecretName(notary *regv1.Notary) string {
+	if notary.Spec.TLS != nil && notary.Spec.TLS.SecretRef != nil {
+		return notary.Spec.TLS.SecretRef.Name
+	}
+	return SubresourceName(notary, SubTypeNotaryServerSecret)
+}
+
+// RegistryCertificate is cert-manager Certificate for registry, which issues the certificate into registry's tls secret
+func RegistryCertificate(reg *regv1.Registry) *unstructured.Unstructured {
+	c := &RegistryCert{}
+	return certificate(SubresourceName(reg, SubTypeRegistryTLSSecret), reg.Namespace, reg.Spec.TLS.IssuerRef, c.GetSanDNS(reg), c.GetSanIP(reg))
+}
+
+// NotaryServerCertificate is cert-manager Certificate for notary server, which issues the certificate into notary server's tls secret
+func NotaryServerCertificate(notary *regv1.Notary) *unstructured.Unstructured {
+	c := &NotaryServerCert{}
+	return certificate(SubresourceName(notary, SubTypeNotaryServerSecret), notary.Namespace, notary.Spec.TLS.IssuerRef, c.GetSanDNS(notary), c.GetSanIP(notary))
+}
+
+func certificate(name, namespace string, issuer *regv1.CertIssuerRef, dnsNames []string, ips []net.IP) *unstructured.Unstructured {
+	kind := issuer.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	group := issuer.Group
+	if group == "" {
+		group = CertificateGVK.Group
+	}
+
+	ipAddresses := []interface{}{}
+	for _, ip := range ips {
+		if ip != nil {
+			ipAddresses = append(ipAddresses, ip.String())
+		}
+	}
+	domains := []interface{}{}
+	for _, d := range dnsNames {
+		domains = append(domains, d)
+	}
+
+	spec := map[string]interface{}{
+		"secretName": name,
+		"dnsNames":   domains,
+		"issuerRef": map[string]interface{}{
+			"name":  issuer.Name,
+			"kind":  kind,
+			"group": group,
+		},
+	}
+	if len(ipAddresses) > 0 {
+		spec["ipAddresses"] = ipAddresses
+	}
+
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	cert.SetGroupVersionKind(CertificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(namespace)
+
+	return cert
+}
@@ -0,0 +1,77 @@
+package schemes
+
+import (
+	"net/url"
+	"strings"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegistryDomainName returns the hostname of registry exposed by Ingress or Gateway service type
+func RegistryDomainName(reg *regv1.Registry) string {
+	return Hostname(reg.Spec.RegistryService.HostnameTemplate, reg.Name, reg.Namespace, "registry")
+}
+
+// NotaryDomainName returns the hostname of notary server exposed by Ingress or Gateway service type
+func NotaryDomainName(notary *regv1.Notary) string {
+	return Hostname(notary.Spec.HostnameTemplate, notary.Name, notary.Namespace, "notary")
+}
+
+// Hostname renders the hostname template. If template is empty, ingress.hostname_template config is used.
+// {domain} is ingress.domain config, or {ingress controller's external ip}.nip.io if it is not set.
+// It returns empty string if the domain is not known yet.
+func Hostname(template, name, namespace, component string) string {
+	if template == "" {
+		template = config.Config.GetString(config.ConfigIngressHostnameTemplate)
+	}
+
+	domain := ""
+	if strings.Contains(template, "{domain}") {
+		if domain = config.Config.GetString(config.ConfigIngressDomain); domain == "" {
+			icIP := ingress.GetIngressControllerIP()
+			if icIP == "" {
+				return ""
+			}
+			domain = icIP + ".nip.io"
+		}
+	}
+
+	return strings.NewReplacer(
+		"{name}", name,
+		"{namespace}", namespace,
+		"{component}", component,
+		"{domain}", domain,
+	).Replace(template)
+}
+
+// IngressClassName returns the ingress class of the exposure options, or ingress.class_name config if it is not set
+func IngressClassName(opts regv1.ExposureOptions) string {
+	if opts.IngressClassName != nil && *opts.IngressClassName != "" {
+		return *opts.IngressClassName
+	}
+	return config.Config.GetString(config.ConfigIngressClassName)
+}
+
+// kubernetesServiceType returns the type of kubernetes service to expose registry or notary server
+func kubernetesServiceType(serviceType string) corev1.ServiceType {
+	switch serviceType {
+	case regv1.RegServiceTypeLoadBalancer:
+		return corev1.ServiceTypeLoadBalancer
+	case regv1.RegServiceTypeNodePort:
+		return corev1.ServiceTypeNodePort
+	default:
+		return corev1.ServiceTypeClusterIP
+	}
+}
+
+// urlHostname returns the host of the url without port
+func urlHostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,50 @@
+package schemes
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestHostname(t *testing.T) {
+	config.InitEnv()
+	config.Config.Set(config.ConfigIngressDomain, "example.com")
+	defer config.Config.Set(config.ConfigIngressDomain, "")
+
+	assert.Equal(t, "ns.reg.registry.example.com", Hostname("", "reg", "ns", "registry"))
+	assert.Equal(t, "reg.ns.example.com", Hostname("{name}.{namespace}.{domain}", "reg", "ns", "registry"))
+	assert.Equal(t, "reg-notary.internal", Hostname("{name}-{component}.internal", "reg", "ns", "notary"))
+}
+
+func TestRegistryRoute(t *testing.T) {
+	reg := &regv1.Registry{
+		ObjectMeta: metav1.ObjectMeta{Name: "reg", Namespace: "ns"},
+		Spec: regv1.RegistrySpec{
+			RegistryService: regv1.RegistryService{
+				ServiceType: regv1.RegServiceTypeGateway,
+				ExposureOptions: regv1.ExposureOptions{
+					HostnameTemplate: "{name}.registry.internal",
+					Gateway:          &regv1.GatewayRef{Name: "gw", SectionName: "tls"},
+				},
+			},
+		},
+	}
+
+	route := RegistryRoute(reg)
+	assert.Equal(t, "TLSRoute", route.GetKind())
+	assert.Equal(t, "v1alpha2", route.GroupVersionKind().Version)
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	assert.Equal(t, []string{"reg.registry.internal"}, hostnames)
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "gw", "sectionName": "tls"}}, parents)
+
+	reg.Spec.RegistryService.Gateway.RouteKind = regv1.GatewayRouteKindHTTP
+	assert.Equal(t, "HTTPRoute", RegistryRoute(reg).GetKind())
+
+	reg.Spec.RegistryService.Gateway = nil
+	assert.Equal(t, (*unstructured.Unstructured)(nil), RegistryRoute(reg))
+}
@@ -29,10 +29,12 @@ func Notary(reg *regv1.Registry, auth *regv1.AuthConfig) (*regv1.Notary, error)
 				Realm:   auth.Realm,
 				Service: auth.Service,
 			},
-			ServiceType: reg.Spec.Notary.ServiceType,
-			Server:      reg.Spec.Notary.Server,
-			Signer:      reg.Spec.Notary.Signer,
-			DB:          reg.Spec.Notary.DB,
+			ServiceType:     reg.Spec.Notary.ServiceType,
+			ExposureOptions: *reg.Spec.Notary.ExposureOptions.DeepCopy(),
+			Server:          reg.Spec.Notary.Server,
+			Signer:          reg.Spec.Notary.Signer,
+			DB:              reg.Spec.Notary.DB,
+			TLS:             reg.Spec.Notary.TLS.DeepCopy(),
 		},
 	}
 
@@ -1,10 +1,7 @@
 package schemes
 
 import (
-	"strings"
-
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
-	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
 
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,7 +16,7 @@ func NotaryServerIngress(notary *regv1.Notary) *v1beta1.Ingress {
 
 	ingressTLS := v1beta1.IngressTLS{
 		Hosts:      []string{notaryDomain},
-		SecretName: SubresourceName(notary, SubTypeNotaryServerSecret),
+		SecretName: NotaryServerTLSSecretName(notary),
 	}
 	httpIngressPath := v1beta1.HTTPIngressPath{
 		Path: "/",
@@ -49,7 +46,7 @@ func NotaryServerIngress(notary *regv1.Notary) *v1beta1.Ingress {
 				"apps": SubresourceName(notary, SubTypeNotaryServerIngress),
 			},
 			Annotations: map[string]string{
-				"kubernetes.io/ingress.class":                       "nginx-shd",
+				"kubernetes.io/ingress.class":                       IngressClassName(notary.Spec.ExposureOptions),
 				"nginx.ingress.kubernetes.io/proxy-connect-timeout": "3600",
 				"nginx.ingress.kubernetes.io/proxy-read-timeout":    "3600",
 				"nginx.ingress.kubernetes.io/ssl-redirect":          "true",
@@ -67,12 +64,3 @@ func NotaryServerIngress(notary *regv1.Notary) *v1beta1.Ingress {
 		},
 	}
 }
-
-func NotaryDomainName(notary *regv1.Notary) string {
-	icIP := ingress.GetIngressControllerIP()
-	if icIP == "" {
-		return ""
-	}
-
-	return strings.Join([]string{notary.Namespace, notary.Name, "notary", icIP, "nip", "io"}, ".")
-}
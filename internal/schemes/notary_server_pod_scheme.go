@@ -178,7 +178,7 @@ func NotaryServerPod(notary *regv1.Notary) *corev1.Pod {
 					VolumeSource: corev1.VolumeSource{
 						Secret: &corev1.SecretVolumeSource{
 							DefaultMode: &mode,
-							SecretName:  SubresourceName(notary, SubTypeNotaryServerSecret),
+							SecretName:  NotaryServerTLSSecretName(notary),
 						},
 					},
 				},
@@ -18,11 +18,6 @@ func NotaryServerService(notary *regv1.Notary) *corev1.Service {
 	labels["apps"] = resName
 	port := NotaryServerDefaultPort
 
-	svcType := notary.Spec.ServiceType
-	if svcType != regv1.RegServiceTypeLoadBalancer {
-		svcType = regv1.RegServiceTypeIngress
-	}
-
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      resName,
@@ -30,7 +25,7 @@ func NotaryServerService(notary *regv1.Notary) *corev1.Service {
 			Labels:    labels,
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceType(svcType),
+			Type: kubernetesServiceType(string(notary.Spec.ServiceType)),
 			Selector: map[string]string{
 				resName: "lb",
 			},
@@ -0,0 +1,42 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryBackupJob is a scheme of registry backup job
+func RegistryBackupJob(backup *regv1.RegistryBackup) *regv1.RegistryJob {
+	resName := SubresourceName(backup, SubTypeRegistryBackupJob)
+	return registryJob(resName, backup.Namespace, "registry-backup-job", regv1.JobTypeRegistryBackup, backup.Name)
+}
+
+// RegistryRestoreJob is a scheme of registry restore job
+func RegistryRestoreJob(restore *regv1.RegistryRestore) *regv1.RegistryJob {
+	resName := SubresourceName(restore, SubTypeRegistryRestoreJob)
+	return registryJob(resName, restore.Namespace, "registry-restore-job", regv1.JobTypeRegistryRestore, restore.Name)
+}
+
+func registryJob(name, namespace, app string, jobType regv1.RegistryJobType, handleObject string) *regv1.RegistryJob {
+	return &regv1.RegistryJob{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":  app,
+				"apps": name,
+			},
+		},
+		Spec: regv1.RegistryJobSpec{
+			Priority: 100,
+			TTL:      -1,
+			Claim: &regv1.RegistryJobClaim{
+				JobType: jobType,
+				HandleObject: corev1.LocalObjectReference{
+					Name: handleObject,
+				},
+			},
+		},
+	}
+}
@@ -1,13 +1,51 @@
 package schemes
 
 import (
+	"fmt"
+	"strings"
+
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func CredentialSecret(reg *regv1.Registry) *corev1.Secret {
+const (
+	// CredentialSecretID is the key of registry's login id in credential secret
+	CredentialSecretID = "ID"
+	// CredentialSecretPassword is the key of registry's login password in credential secret
+	CredentialSecretPassword = "PASSWD"
+	// CredentialSecretHTTPSecret is the key of registry's shared http secret in credential secret
+	CredentialSecretHTTPSecret = "HTTP_SECRET"
+	// CredentialSecretPreviousPassword is the key of registry's login password before the latest rotation
+	CredentialSecretPreviousPassword = "PREVIOUS_PASSWD"
+	// CredentialSecretPreviousPasswordExpiry is the key of RFC3339 time until when the previous password is accepted
+	CredentialSecretPreviousPasswordExpiry = "PREVIOUS_PASSWD_EXPIRES_AT"
+	// CredentialSecretNotificationToken is the key of the bearer token which registry sends events to the operator with
+	CredentialSecretNotificationToken = "NOTIFICATION_TOKEN"
+	// CredentialSecretProxyUsername is the key of the remote registry's username of pull-through cache
+	CredentialSecretProxyUsername = "PROXY_ID"
+	// CredentialSecretProxyPassword is the key of the remote registry's password of pull-through cache
+	CredentialSecretProxyPassword = "PROXY_PASSWD"
+	// CredentialSecretNotificationEndpoints is the key of registry's notification endpoints in YAML, which may have secret headers
+	CredentialSecretNotificationEndpoints = "NOTIFICATION_ENDPOINTS"
+	// CredentialSecretOperatorCA is the key of the CA certificate of the operator's server, which registry pods trust
+	CredentialSecretOperatorCA = "OPERATOR_CA"
+)
+
+// CredentialSecret is a secret which has registry's login id and password, and the shared http secret of registry replicas
+func CredentialSecret(reg *regv1.Registry, password string) (*corev1.Secret, error) {
+	httpSecret, err := utils.RandomSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	token, err := NotificationToken(reg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      SubresourceName(reg, SubTypeRegistryOpaqueSecret),
@@ -18,10 +56,34 @@ func CredentialSecret(reg *regv1.Registry) *corev1.Secret {
 		},
 		Type: corev1.SecretTypeOpaque,
 		Data: map[string][]byte{
-			"ID":     []byte(reg.Spec.LoginID),
-			"PASSWD": []byte(reg.Spec.LoginPassword),
+			CredentialSecretID:       []byte(reg.Spec.LoginID),
+			CredentialSecretPassword: []byte(password),
+			// shared by all registry replicas to sign upload states
+			CredentialSecretHTTPSecret:        []byte(httpSecret),
+			CredentialSecretNotificationToken: []byte(token),
 		},
+	}, nil
+}
+
+// NotificationToken returns a new random token of the registry's notification endpoint.
+// It is prefixed with the namespace and name of the registry, so that the operator finds the registry which sent events.
+func NotificationToken(reg *regv1.Registry) (string, error) {
+	random, err := utils.RandomSecret(32)
+	if err != nil {
+		return "", err
 	}
+
+	return fmt.Sprintf("%s/%s/%s", reg.Namespace, reg.Name, random), nil
+}
+
+// NotificationTokenRegistry returns the registry which the notification token is issued for
+func NotificationTokenRegistry(token string) (types.NamespacedName, bool) {
+	parts := strings.Split(token, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return types.NamespacedName{}, false
+	}
+
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, true
 }
 
 func TlsSecret(reg *regv1.Registry, c client.Client) (*corev1.Secret, error) {
@@ -1,14 +1,78 @@
 package schemes
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultConfigMapName = "registry-config"
+	// RegistryConfigYmlKey is the key of registry's config.yml in configmap
+	RegistryConfigYmlKey = "config.yml"
+	// ConfigHashAnnotation is the annotation of registry pod template to roll pods out when config.yml is changed
+	ConfigHashAnnotation = "tmax.io/config-hash"
 )
 
-const DefaultConfigMapName = "registry-config"
+// protectedConfigKeys are the keys of config.yml managed by the operator, which cannot be overridden
+var protectedConfigKeys = []string{
+	"auth",
+	"http.addr",
+	"http.secret",
+	"http.tls",
+	"notifications.endpoints",
+	"proxy",
+	"storage.maintenance.readonly",
+}
+
+// RegistryProxyDebugPort is registry's debug server port which exposes proxy cache statistics
+const RegistryProxyDebugPort = 5001
+
+// RegistryProxyConfig is remote registry settings for pull-through cache
+type RegistryProxyConfig struct {
+	RemoteURL string
+	Username  string
+	Password  string
+}
+
+// ConfigSecretKeys are the keys of credential secret which have settings of config.yml kept out of the configmap.
+// Registry pods read them by env variables, and the operator's CA by a volume.
+var ConfigSecretKeys = []string{CredentialSecretProxyUsername, CredentialSecretProxyPassword, CredentialSecretNotificationEndpoints, CredentialSecretOperatorCA}
+
+// RegistryNotificationHeaders are header values of user-defined notification endpoints read from secrets,
+// and of the operator's endpoint, keyed by endpoint name and header name
+type RegistryNotificationHeaders map[string]map[string]string
+
+// storageDrivers are the storage driver keys which can be set in registry's config.yml
+var storageDrivers = []string{"filesystem", "inmemory", "s3", "swift", "azure", "gcs", "oss"}
+
+// ConfigMap is a scheme of registry configmap. Storage and proxy settings of config.yml are rendered from registry spec.
+// current is registry's configmap in use, whose root directory of filesystem storage is kept. It is nil if not created yet.
+func ConfigMap(reg *regv1.Registry, data map[string]string, proxy *RegistryProxyConfig, current *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	out := map[string]string{}
+	for k, v := range data {
+		out[k] = v
+	}
+
+	if cfg, ok := out[RegistryConfigYmlKey]; ok {
+		rendered, err := renderConfig(reg, cfg, proxy, FilesystemRootDirectory(current))
+		if err != nil {
+			return nil, err
+		}
+		out[RegistryConfigYmlKey] = rendered
+	}
 
-func ConfigMap(reg *regv1.Registry, data map[string]string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      SubresourceName(reg, SubTypeRegistryConfigmap),
@@ -18,6 +82,374 @@ func ConfigMap(reg *regv1.Registry, data map[string]string) *corev1.ConfigMap {
 				"apps": SubresourceName(reg, SubTypeRegistryConfigmap),
 			},
 		},
-		Data: data,
+		Data: out,
+	}, nil
+}
+
+func renderConfig(reg *regv1.Registry, cfg string, proxy *RegistryProxyConfig, rootDirectory string) (string, error) {
+	conf := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(cfg), &conf); err != nil {
+		return "", err
+	}
+
+	if reg.Spec.ConfigOverrides != "" {
+		overrides, err := parseConfigOverrides(reg.Spec.ConfigOverrides)
+		if err != nil {
+			return "", err
+		}
+		base := protectedConfigValues(conf)
+		mergeConfig(conf, overrides)
+		if key := changedConfigKey(base, protectedConfigValues(conf)); key != "" {
+			return "", fmt.Errorf("configOverrides cannot set %s, which is managed by the operator", key)
+		}
+	}
+
+	if err := setStorageConfig(reg, conf, rootDirectory); err != nil {
+		return "", err
+	}
+	setProxyConfig(conf, proxy)
+	// endpoints are set by env variable from credential secret, since their headers may have secrets
+	if notifications, ok := conf["notifications"].(map[string]interface{}); ok {
+		delete(notifications, "endpoints")
+	}
+
+	rendered, err := yaml.Marshal(conf)
+	if err != nil {
+		return "", err
+	}
+
+	return string(rendered), nil
+}
+
+// setStorageConfig replaces storage driver of config with the driver of registry's storage spec.
+// Other storage settings like cache, delete and maintenance are kept. Filesystem storage is rooted at rootDirectory
+// if given, or at the mount path of the pvc.
+func setStorageConfig(reg *regv1.Registry, conf map[string]interface{}, rootDirectory string) error {
+	storage, ok := conf["storage"].(map[string]interface{})
+	if !ok {
+		storage = map[string]interface{}{}
+	}
+	for _, driver := range storageDrivers {
+		delete(storage, driver)
+	}
+
+	switch reg.Spec.Storage.Type {
+	case regv1.RegistryStorageTypeS3:
+		s3 := reg.Spec.Storage.S3
+		if s3 == nil {
+			return regv1.MakeRegistryError("S3 storage setting is missing")
+		}
+		driver := map[string]interface{}{
+			"bucket": s3.Bucket,
+			"region": s3.Region,
+			"secure": !s3.Insecure,
+		}
+		if s3.Endpoint != "" {
+			driver["regionendpoint"] = s3.Endpoint
+		}
+		if s3.RootDirectory != "" {
+			driver["rootdirectory"] = s3.RootDirectory
+		}
+		if s3.SkipVerify {
+			driver["skipverify"] = true
+		}
+		storage["s3"] = driver
+	default:
+		if rootDirectory == "" {
+			rootDirectory = RegistryMountPath(reg)
+		}
+		storage["filesystem"] = map[string]interface{}{
+			"rootdirectory": rootDirectory,
+		}
+	}
+	conf["storage"] = storage
+
+	return nil
+}
+
+// FilesystemRootDirectory returns the root directory of filesystem storage in registry's configmap, or empty if not set.
+// Registries created before the storage spec was introduced use the default one of the base config even if the pvc is
+// mounted at another path, so it must not be moved.
+func FilesystemRootDirectory(cm *corev1.ConfigMap) string {
+	if cm == nil {
+		return ""
+	}
+	conf := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(cm.Data[RegistryConfigYmlKey]), &conf); err != nil {
+		return ""
+	}
+	storage, _ := conf["storage"].(map[string]interface{})
+	filesystem, _ := storage["filesystem"].(map[string]interface{})
+	root, _ := filesystem["rootdirectory"].(string)
+
+	return root
+}
+
+// setProxyConfig sets remote registry of pull-through cache and
+// opens debug server to collect cache statistics
+func setProxyConfig(conf map[string]interface{}, proxy *RegistryProxyConfig) {
+	if proxy == nil {
+		delete(conf, "proxy")
+		return
+	}
+
+	// remote credentials are set by env variables from credential secret
+	conf["proxy"] = map[string]interface{}{
+		"remoteurl": proxy.RemoteURL,
+	}
+
+	http, ok := conf["http"].(map[string]interface{})
+	if !ok {
+		http = map[string]interface{}{}
+	}
+	http["debug"] = map[string]interface{}{
+		"addr": fmt.Sprintf(":%d", RegistryProxyDebugPort),
+	}
+	conf["http"] = http
+}
+
+// notificationEndpoints returns the operator's notification endpoint of the base config followed by user-defined endpoints.
+// If the base config has no operator's endpoint, it is added.
+func notificationEndpoints(reg *regv1.Registry, conf map[string]interface{}, headers RegistryNotificationHeaders) []interface{} {
+	notifications, ok := conf["notifications"].(map[string]interface{})
+	if !ok {
+		notifications = map[string]interface{}{}
+	}
+
+	var operator map[string]interface{}
+	if endpoints, ok := notifications["endpoints"].([]interface{}); ok {
+		for _, e := range endpoints {
+			if endpoint, ok := e.(map[string]interface{}); ok && endpoint["name"] == regv1.OperatorNotificationEndpoint {
+				operator = endpoint
+				break
+			}
+		}
+	}
+	if operator == nil {
+		operator = map[string]interface{}{
+			"name": regv1.OperatorNotificationEndpoint,
+			"url":  fmt.Sprintf("http://%s.%s:28677/registry/event", utils.OperatorServiceName(), regv1.OperatorNamespace),
+		}
+	}
+	// the operator's server accepts only https if it has a certificate
+	if isServerTLS() {
+		if u, err := url.Parse(fmt.Sprint(operator["url"])); err == nil && u.Scheme == "http" {
+			u.Scheme = "https"
+			operator["url"] = u.String()
+		}
+	}
+	// headers of the operator's endpoint, e.g. the registry's notification token, replace those of the base config
+	if len(headers[regv1.OperatorNotificationEndpoint]) > 0 {
+		operatorHeaders, ok := operator["headers"].(map[string]interface{})
+		if !ok {
+			operatorHeaders = map[string]interface{}{}
+		}
+		for name, value := range headers[regv1.OperatorNotificationEndpoint] {
+			operatorHeaders[name] = []interface{}{value}
+		}
+		operator["headers"] = operatorHeaders
+	}
+	endpoints := []interface{}{operator}
+
+	if reg.Spec.Notifications != nil {
+		for _, e := range reg.Spec.Notifications.Endpoints {
+			endpoints = append(endpoints, notificationEndpoint(e, headers[e.Name]))
+		}
+	}
+	return endpoints
+}
+
+func isServerTLS() bool {
+	return config.Config.GetString(config.ConfigServerTLSCertFile) != "" && config.Config.GetString(config.ConfigServerTLSKeyFile) != ""
+}
+
+func notificationEndpoint(e regv1.RegistryNotificationEndpoint, secretHeaders map[string]string) map[string]interface{} {
+	endpoint := map[string]interface{}{
+		"name": e.Name,
+		"url":  e.URL,
+	}
+	if len(e.Headers) > 0 {
+		headers := map[string]interface{}{}
+		for _, h := range e.Headers {
+			value := h.Value
+			if h.ValueFrom != nil {
+				value = secretHeaders[h.Name]
+			}
+			headers[h.Name] = []interface{}{value}
+		}
+		endpoint["headers"] = headers
+	}
+	if e.Timeout != nil {
+		endpoint["timeout"] = e.Timeout.Duration.String()
+	}
+	if e.Threshold > 0 {
+		endpoint["threshold"] = e.Threshold
+	}
+	if e.Backoff != nil {
+		endpoint["backoff"] = e.Backoff.Duration.String()
+	}
+	if len(e.Actions) > 0 {
+		// registry filters events by ignored actions
+		ignored := []interface{}{}
+		for _, action := range regv1.RegistryNotificationActions {
+			if !containsAction(e.Actions, action) {
+				ignored = append(ignored, string(action))
+			}
+		}
+		if len(ignored) > 0 {
+			endpoint["ignore"] = map[string]interface{}{"actions": ignored}
+		}
+	}
+	return endpoint
+}
+
+func containsAction(actions []regv1.RegistryNotificationAction, action regv1.RegistryNotificationAction) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigSecretData returns the settings of config.yml which must not be written in the configmap,
+// keyed by ConfigSecretKeys. Notification endpoints are rendered from the base config in data and registry spec.
+func ConfigSecretData(reg *regv1.Registry, data map[string]string, proxy *RegistryProxyConfig, headers RegistryNotificationHeaders) (map[string][]byte, error) {
+	out := map[string][]byte{}
+	if proxy != nil && proxy.Username != "" {
+		out[CredentialSecretProxyUsername] = []byte(proxy.Username)
+		out[CredentialSecretProxyPassword] = []byte(proxy.Password)
+	}
+
+	if cfg, ok := data[RegistryConfigYmlKey]; ok {
+		conf := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(cfg), &conf); err != nil {
+			return nil, err
+		}
+		endpoints, err := yaml.Marshal(notificationEndpoints(reg, conf, headers))
+		if err != nil {
+			return nil, err
+		}
+		out[CredentialSecretNotificationEndpoints] = endpoints
+	}
+
+	// registries send events to the operator over https, so they trust its server certificate by the given CA
+	if caFile := config.Config.GetString(config.ConfigServerTLSCAFile); caFile != "" && isServerTLS() {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		out[CredentialSecretOperatorCA] = ca
+	}
+
+	return out, nil
+}
+
+// ConfigHash returns the hash of config.yml in the configmap and the settings in credential secret
+func ConfigHash(cm *corev1.ConfigMap, secretData map[string][]byte) string {
+	h := sha256.New()
+	h.Write([]byte(cm.Data[RegistryConfigYmlKey]))
+
+	keys := []string{}
+	for k := range secretData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "\x00%s=%s", k, secretData[k])
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+// ValidateConfigOverrides checks config overrides are valid YAML or JSON and do not set the keys managed by the operator
+func ValidateConfigOverrides(overrides string) error {
+	conf, err := parseConfigOverrides(overrides)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range protectedKeys() {
+		if touchesConfigKey(conf, strings.Split(key, ".")) {
+			return fmt.Errorf("configOverrides cannot set %s, which is managed by the operator", key)
+		}
+	}
+
+	return nil
+}
+
+// protectedKeys returns the keys managed by the operator including storage drivers
+func protectedKeys() []string {
+	keys := append([]string{}, protectedConfigKeys...)
+	for _, driver := range storageDrivers {
+		keys = append(keys, "storage."+driver)
+	}
+	return keys
+}
+
+func parseConfigOverrides(overrides string) (map[string]interface{}, error) {
+	conf := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(overrides), &conf); err != nil {
+		return nil, fmt.Errorf("configOverrides is not valid YAML or JSON: %s", err.Error())
+	}
+	return conf, nil
+}
+
+// touchesConfigKey returns true if the config sets the key or replaces one of its ancestors with a value which is
+// not a map, e.g. null, since merging such a value removes the whole subtree
+func touchesConfigKey(conf map[string]interface{}, path []string) bool {
+	v, ok := conf[path[0]]
+	if !ok || len(path) == 1 {
+		return ok
+	}
+	child, ok := v.(map[string]interface{})
+	return !ok || touchesConfigKey(child, path[1:])
+}
+
+// protectedConfigValues returns values of the protected keys set in the config
+func protectedConfigValues(conf map[string]interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, key := range protectedKeys() {
+		if v, ok := configValue(conf, strings.Split(key, ".")); ok {
+			values[key] = v
+		}
+	}
+	return values
+}
+
+func configValue(conf map[string]interface{}, path []string) (interface{}, bool) {
+	v, ok := conf[path[0]]
+	if !ok || len(path) == 1 {
+		return v, ok
+	}
+	child, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return configValue(child, path[1:])
+}
+
+// changedConfigKey returns a key whose value differs between the protected values, or empty string if none differs
+func changedConfigKey(before, after map[string]interface{}) string {
+	for _, key := range protectedKeys() {
+		b, bok := before[key]
+		a, aok := after[key]
+		if bok != aok || !reflect.DeepEqual(a, b) {
+			return key
+		}
+	}
+	return ""
+}
+
+// mergeConfig merges src into dst. Maps are merged recursively and other values of src replace those of dst.
+func mergeConfig(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
 	}
 }
@@ -0,0 +1,317 @@
+package schemes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const testConfigYml = `version: 0.1
+storage:
+  cache:
+    blobdescriptor: inmemory
+  filesystem:
+    rootdirectory: /var/lib/registry
+  delete:
+    enabled: true
+`
+
+func TestRenderConfig(t *testing.T) {
+	type suite struct {
+		storage    regv1.RegistryStorage
+		mountPath  string
+		expStorage map[string]interface{}
+		expError   bool
+	}
+	testCases := []suite{
+		{
+			storage:   regv1.RegistryStorage{},
+			mountPath: "/data",
+			expStorage: map[string]interface{}{
+				"filesystem": map[string]interface{}{"rootdirectory": "/data"},
+			},
+		},
+		{
+			storage: regv1.RegistryStorage{
+				Type: regv1.RegistryStorageTypeS3,
+				S3: &regv1.S3Storage{
+					Endpoint:         "http://minio:9000",
+					Bucket:           "registry",
+					Region:           "us-east-1",
+					CredentialSecret: "minio-cred",
+					Insecure:         true,
+				},
+			},
+			expStorage: map[string]interface{}{
+				"s3": map[string]interface{}{
+					"bucket":         "registry",
+					"region":         "us-east-1",
+					"regionendpoint": "http://minio:9000",
+					"secure":         false,
+				},
+			},
+		},
+		{
+			storage:  regv1.RegistryStorage{Type: regv1.RegistryStorageTypeS3},
+			expError: true,
+		},
+	}
+
+	for _, c := range testCases {
+		reg := &regv1.Registry{}
+		reg.Spec.Storage = c.storage
+		reg.Spec.PersistentVolumeClaim.MountPath = c.mountPath
+
+		rendered, err := renderConfig(reg, testConfigYml, nil, "")
+		if c.expError {
+			assert.NotEqual(t, nil, err)
+			continue
+		}
+		assert.Equal(t, nil, err)
+
+		conf := map[string]interface{}{}
+		assert.Equal(t, nil, yaml.Unmarshal([]byte(rendered), &conf))
+		storage := conf["storage"].(map[string]interface{})
+		for driver, exp := range c.expStorage {
+			assert.Equal(t, exp, storage[driver])
+		}
+		assert.Equal(t, len(c.expStorage)+2, len(storage))
+		assert.NotEqual(t, nil, storage["cache"])
+		assert.NotEqual(t, nil, storage["delete"])
+		assert.Equal(t, nil, conf["proxy"])
+	}
+}
+
+func TestConfigMapRootDirectory(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	reg.Spec.PersistentVolumeClaim.MountPath = "/data"
+	data := map[string]string{RegistryConfigYmlKey: testConfigYml}
+
+	// new registries store images at the mount path of the pvc
+	cm, err := ConfigMap(reg, data, nil, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/data", FilesystemRootDirectory(cm))
+
+	// and existing ones keep their root directory
+	current := &corev1.ConfigMap{Data: map[string]string{RegistryConfigYmlKey: testConfigYml}}
+	cm, err = ConfigMap(reg, data, nil, current)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/var/lib/registry", FilesystemRootDirectory(cm))
+
+	// unless they were using other storages
+	current.Data[RegistryConfigYmlKey] = "storage:\n  s3:\n    bucket: registry\n"
+	cm, err = ConfigMap(reg, data, nil, current)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/data", FilesystemRootDirectory(cm))
+}
+
+func TestRenderProxyConfig(t *testing.T) {
+	reg := &regv1.Registry{}
+	proxy := &RegistryProxyConfig{
+		RemoteURL: "https://registry-1.docker.io",
+		Username:  "tmax",
+		Password:  "tmax123",
+	}
+
+	rendered, err := renderConfig(reg, testConfigYml, proxy, "")
+	assert.Equal(t, nil, err)
+
+	conf := map[string]interface{}{}
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(rendered), &conf))
+	assert.Equal(t, map[string]interface{}{
+		"remoteurl": "https://registry-1.docker.io",
+	}, conf["proxy"])
+	assert.Equal(t, map[string]interface{}{"addr": ":5001"}, conf["http"].(map[string]interface{})["debug"])
+
+	// remote credentials are kept in credential secret, and pods roll out when they are changed
+	data, err := ConfigSecretData(reg, nil, proxy, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string][]byte{
+		CredentialSecretProxyUsername: []byte("tmax"),
+		CredentialSecretProxyPassword: []byte("tmax123"),
+	}, data)
+	anonymous, err := ConfigSecretData(reg, nil, &RegistryProxyConfig{RemoteURL: proxy.RemoteURL}, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string][]byte{}, anonymous)
+
+	cm := &corev1.ConfigMap{Data: map[string]string{RegistryConfigYmlKey: rendered}}
+	hash := ConfigHash(cm, data)
+	data[CredentialSecretProxyPassword] = []byte("changed")
+	assert.NotEqual(t, hash, ConfigHash(cm, data))
+}
+
+func TestConfigOverrides(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Spec.PersistentVolumeClaim.MountPath = "/data"
+	reg.Spec.ConfigOverrides = `
+log:
+  level: debug
+storage:
+  delete:
+    enabled: false
+`
+	rendered, err := renderConfig(reg, testConfigYml, nil, "")
+	assert.Equal(t, nil, err)
+
+	conf := map[string]interface{}{}
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(rendered), &conf))
+	assert.Equal(t, map[string]interface{}{"level": "debug"}, conf["log"])
+	storage := conf["storage"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"enabled": false}, storage["delete"])
+	assert.Equal(t, map[string]interface{}{"blobdescriptor": "inmemory"}, storage["cache"])
+
+	type suite struct {
+		overrides string
+		expError  bool
+	}
+	testCases := []suite{
+		{overrides: "log:\n  level: info\nhttp:\n  headers:\n    X-Content-Type-Options: [nosniff]\n"},
+		{overrides: `{"health": {"storagedriver": {"enabled": true}}}`},
+		{overrides: "auth:\n  token:\n    realm: https://evil\n", expError: true},
+		{overrides: "notifications:\n  endpoints: []\n", expError: true},
+		{overrides: "http:\n  secret: foo\n", expError: true},
+		{overrides: "storage:\n  s3:\n    bucket: foo\n", expError: true},
+		{overrides: "- not a map\n", expError: true},
+		// replacing an ancestor of managed keys removes them
+		{overrides: "http: null\n", expError: true},
+		{overrides: "storage: null\n", expError: true},
+		{overrides: "auth: x\n", expError: true},
+		{overrides: "storage:\n  maintenance: []\n", expError: true},
+		{overrides: "storage:\n  cache:\n    blobdescriptor: redis\n"},
+	}
+	for _, c := range testCases {
+		err := ValidateConfigOverrides(c.overrides)
+		assert.Equal(t, c.expError, err != nil)
+	}
+}
+
+func TestConfigOverridesMerged(t *testing.T) {
+	// overrides which were not validated cannot replace the managed keys either
+	reg := &regv1.Registry{}
+	reg.Spec.ConfigOverrides = "http: null\n"
+	_, err := renderConfig(reg, testConfigYml+"http:\n  addr: :5000\n", nil, "")
+	assert.NotEqual(t, nil, err)
+
+	reg.Spec.ConfigOverrides = "http:\n  headers:\n    X-Content-Type-Options: [nosniff]\n"
+	_, err = renderConfig(reg, testConfigYml+"http:\n  addr: :5000\n", nil, "")
+	assert.Equal(t, nil, err)
+}
+
+func TestNotificationConfig(t *testing.T) {
+	base := testConfigYml + `notifications:
+  endpoints:
+  - name: registry-operator
+    url: http://operator/registry/event
+`
+	reg := &regv1.Registry{}
+	reg.Spec.Notifications = &regv1.RegistryNotifications{
+		Endpoints: []regv1.RegistryNotificationEndpoint{
+			{
+				Name: "ci",
+				URL:  "https://ci/hook",
+				Headers: []regv1.RegistryNotificationHeader{
+					{Name: "X-Env", Value: "prod"},
+					{Name: "Authorization", ValueFrom: &corev1.SecretKeySelector{Key: "token"}},
+				},
+				Actions:   []regv1.RegistryNotificationAction{regv1.RegistryNotificationActionPush},
+				Threshold: 3,
+			},
+		},
+	}
+	headers := RegistryNotificationHeaders{
+		"ci":                               {"Authorization": "Bearer abc"},
+		regv1.OperatorNotificationEndpoint: {"Authorization": "Bearer ns/reg/token"},
+	}
+
+	for _, cfg := range []string{base, testConfigYml} {
+		// endpoints are kept out of the configmap, since their headers have secrets
+		rendered, err := renderConfig(reg, cfg, nil, "")
+		assert.Equal(t, nil, err)
+		conf := map[string]interface{}{}
+		assert.Equal(t, nil, yaml.Unmarshal([]byte(rendered), &conf))
+		if notifications, ok := conf["notifications"].(map[string]interface{}); ok {
+			_, ok := notifications["endpoints"]
+			assert.Equal(t, false, ok)
+		}
+
+		data, err := ConfigSecretData(reg, map[string]string{RegistryConfigYmlKey: cfg}, nil, headers)
+		assert.Equal(t, nil, err)
+		endpoints := []interface{}{}
+		assert.Equal(t, nil, yaml.Unmarshal(data[CredentialSecretNotificationEndpoints], &endpoints))
+		assert.Equal(t, 2, len(endpoints))
+		assert.Equal(t, regv1.OperatorNotificationEndpoint, endpoints[0].(map[string]interface{})["name"])
+		assert.Equal(t, map[string]interface{}{"Authorization": []interface{}{"Bearer ns/reg/token"}}, endpoints[0].(map[string]interface{})["headers"])
+
+		ci := endpoints[1].(map[string]interface{})
+		assert.Equal(t, "https://ci/hook", ci["url"])
+		assert.Equal(t, map[string]interface{}{
+			"X-Env":         []interface{}{"prod"},
+			"Authorization": []interface{}{"Bearer abc"},
+		}, ci["headers"])
+		assert.Equal(t, map[string]interface{}{"actions": []interface{}{"pull", "mount", "delete"}}, ci["ignore"])
+		assert.Equal(t, float64(3), ci["threshold"])
+	}
+
+	// events are sent over https if the operator's server has a certificate
+	config.Config.Set(config.ConfigServerTLSCertFile, "/tls.crt")
+	config.Config.Set(config.ConfigServerTLSKeyFile, "/tls.key")
+	defer config.Config.Set(config.ConfigServerTLSCertFile, "")
+	defer config.Config.Set(config.ConfigServerTLSKeyFile, "")
+	data, err := ConfigSecretData(reg, map[string]string{RegistryConfigYmlKey: base}, nil, headers)
+	assert.Equal(t, nil, err)
+	endpoints := []interface{}{}
+	assert.Equal(t, nil, yaml.Unmarshal(data[CredentialSecretNotificationEndpoints], &endpoints))
+	assert.Equal(t, "https://operator/registry/event", endpoints[0].(map[string]interface{})["url"])
+	_, ok := data[CredentialSecretOperatorCA]
+	assert.Equal(t, false, ok)
+
+	// and trust its certificate by the given CA
+	dir, err := ioutil.TempDir("", "ca")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.crt")
+	assert.Equal(t, nil, ioutil.WriteFile(caFile, []byte("ca"), 0600))
+	config.Config.Set(config.ConfigServerTLSCAFile, caFile)
+	defer config.Config.Set(config.ConfigServerTLSCAFile, "")
+	data, err = ConfigSecretData(reg, map[string]string{RegistryConfigYmlKey: base}, nil, headers)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ca", string(data[CredentialSecretOperatorCA]))
+}
+
+func TestMountOperatorCA(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "registry"}}}
+
+	assert.Equal(t, true, MountOperatorCA(reg, podSpec))
+	assert.Equal(t, false, MountOperatorCA(reg, podSpec))
+	assert.Equal(t, 1, len(podSpec.Volumes))
+	assert.Equal(t, SubresourceName(reg, SubTypeRegistryOpaqueSecret), podSpec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, CredentialSecretOperatorCA, podSpec.Volumes[0].Secret.Items[0].Key)
+	assert.Equal(t, 1, len(podSpec.Containers[0].VolumeMounts))
+	assert.Equal(t, []corev1.EnvVar{{Name: "SSL_CERT_DIR", Value: "/certs/operator"}}, podSpec.Containers[0].Env)
+}
+
+func TestNotificationToken(t *testing.T) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+
+	token, err := NotificationToken(reg)
+	assert.Equal(t, nil, err)
+	key, ok := NotificationTokenRegistry(token)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "ns/reg", key.String())
+
+	for _, invalid := range []string{"", "abc", "ns/reg", "ns//abc", "ns/reg/abc/def"} {
+		_, ok := NotificationTokenRegistry(invalid)
+		assert.Equal(t, false, ok)
+	}
+}
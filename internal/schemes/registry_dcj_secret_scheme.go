@@ -3,6 +3,7 @@ package schemes
 import (
 	"encoding/base64"
 	"encoding/json"
+	"strings"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 
@@ -18,14 +19,45 @@ type AuthValue struct {
 	Auth string `json:"auth"`
 }
 
-func DCJSecret(reg *regv1.Registry) *corev1.Secret {
-	serviceType := reg.Spec.RegistryService.ServiceType
+// DCJSecret is a dockerconfigjson secret of registry's login user
+func DCJSecret(reg *regv1.Registry, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SubresourceName(reg, SubTypeRegistryDCJSecret),
+			Namespace: reg.Namespace,
+			Labels: map[string]string{
+				"secret": "docker",
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: dockerConfigData(reg, reg.Spec.LoginID, password),
+	}
+}
+
+// RegistryUserDCJSecret is a dockerconfigjson secret of the registry user
+func RegistryUserDCJSecret(user *regv1.RegistryUser, reg *regv1.Registry, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SubresourceName(user, SubTypeRegistryUserDCJSecret),
+			Namespace: user.Namespace,
+			Labels: map[string]string{
+				"secret": "docker",
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: dockerConfigData(reg, user.GetUsername(), password),
+	}
+}
+
+// dockerConfigData returns dockerconfigjson data which has the credential for all domains of the registry
+func dockerConfigData(reg *regv1.Registry, username, password string) map[string][]byte {
 	var domainList []string
-	data := map[string][]byte{}
-	if serviceType == regv1.RegServiceTypeLoadBalancer {
-		// port = reg.Spec.RegistryService.LoadBalancer.Port
+	switch reg.Spec.RegistryService.ServiceType {
+	case regv1.RegServiceTypeLoadBalancer:
 		domainList = append(domainList, reg.Status.LoadBalancerIP)
-	} else {
+	case regv1.RegServiceTypeNodePort:
+		domainList = append(domainList, strings.TrimPrefix(reg.Status.ServerURL, "https://"))
+	default:
 		domainList = append(domainList, RegistryDomainName(reg))
 	}
 	domainList = append(domainList, reg.Status.ClusterIP)
@@ -34,21 +66,33 @@ func DCJSecret(reg *regv1.Registry) *corev1.Secret {
 		Auths: map[string]AuthValue{},
 	}
 	for _, domain := range domainList {
-		config.Auths[domain] = AuthValue{base64.StdEncoding.EncodeToString([]byte(reg.Spec.LoginID + ":" + reg.Spec.LoginPassword))}
+		config.Auths[domain] = AuthValue{base64.StdEncoding.EncodeToString([]byte(username + ":" + password))}
 	}
 
 	configBytes, _ := json.Marshal(config)
-	data[corev1.DockerConfigJsonKey] = configBytes
+	return map[string][]byte{corev1.DockerConfigJsonKey: configBytes}
+}
+
+// DistributedDCJSecretLabels returns labels of copies of the registry's dockerconfigjson secret in other namespaces
+func DistributedDCJSecretLabels(namespace, name string) map[string]string {
+	return map[string]string{
+		"registry":           name,
+		"registry-namespace": namespace,
+	}
+}
+
+// DistributedDCJSecret is a copy of the registry's dockerconfigjson secret in another namespace
+func DistributedDCJSecret(reg *regv1.Registry, source *corev1.Secret, namespace string) *corev1.Secret {
+	labels := DistributedDCJSecretLabels(reg.Namespace, reg.Name)
+	labels["secret"] = "docker"
 
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      SubresourceName(reg, SubTypeRegistryDCJSecret),
-			Namespace: reg.Namespace,
-			Labels: map[string]string{
-				"secret": "docker",
-			},
+			Name:      SubresourceName(reg, SubTypeRegistryDistributedDCJSecret),
+			Namespace: namespace,
+			Labels:    labels,
 		},
-		Type: corev1.SecretTypeDockerConfigJson,
-		Data: data,
+		Type: source.Type,
+		Data: source.Data,
 	}
 }
@@ -20,16 +20,32 @@ const (
 	// RegistryEnvValueStorageMaintenance sets readonly
 	RegistryEnvValueStorageMaintenance = `{"readonly":{"enabled":true}}`
 
+	// RegistryEnvKeyHTTPSecret is registry's http secret shared by all replicas
+	RegistryEnvKeyHTTPSecret = "REGISTRY_HTTP_SECRET"
+	// RegistryEnvKeyS3AccessKey is registry's S3 storage access key
+	RegistryEnvKeyS3AccessKey = "REGISTRY_STORAGE_S3_ACCESSKEY"
+	// RegistryEnvKeyS3SecretKey is registry's S3 storage secret key
+	RegistryEnvKeyS3SecretKey = "REGISTRY_STORAGE_S3_SECRETKEY"
+	// RegistryEnvKeyProxyUsername is the remote registry's username of pull-through cache
+	RegistryEnvKeyProxyUsername = "REGISTRY_PROXY_USERNAME"
+	// RegistryEnvKeyProxyPassword is the remote registry's password of pull-through cache
+	RegistryEnvKeyProxyPassword = "REGISTRY_PROXY_PASSWORD"
+	// RegistryEnvKeyNotificationEndpoints is registry's notification endpoints in YAML, which replace those of config.yml
+	RegistryEnvKeyNotificationEndpoints = "REGISTRY_NOTIFICATIONS_ENDPOINTS"
+
 	configMapMountPath = "/etc/docker/registry"
 
 	registryTLSCrtPath = "/certs/registry/tls.crt"
 	registryTLSKeyPath = "/certs/registry/tls.key"
 	authTokenKeyPath   = "/certs/rootca/ca.crt"
+	operatorCAPath     = "/certs/operator/ca.crt"
+
+	operatorCAVolume = "operator-ca"
 )
 
 // Deployment is a scheme of registry deployment
 func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment, error) {
-	var resName, pvcMountPath, pvcName, configMapName string
+	var resName, pvcName, configMapName string
 	resName = SubresourceName(reg, SubTypeRegistryDeployment)
 	label, labelSelector := map[string]string{}, map[string]string{}
 	label["app"] = "registry"
@@ -50,13 +66,6 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 		labelSelector[k] = v
 	}
 
-	// Set mountpath
-	if len(reg.Spec.PersistentVolumeClaim.MountPath) == 0 {
-		pvcMountPath = RegistryPVCMountPath
-	} else {
-		pvcMountPath = reg.Spec.PersistentVolumeClaim.MountPath
-	}
-
 	// Set pvc
 	if reg.Spec.PersistentVolumeClaim.Exist != nil {
 		pvcName = reg.Spec.PersistentVolumeClaim.Exist.PvcName
@@ -93,6 +102,10 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 		memoryLimit = resource.MustParse(config.Config.GetString(config.ConfigRegistryMemory))
 	}
 
+	replicas := reg.Spec.RegistryDeployment.DesiredReplicas()
+	// http secret is optional for the credential secret created before replicas were supported
+	optional := true
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      resName,
@@ -100,6 +113,7 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 			Labels:    label,
 		},
 		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels:      labelSelector,
 				MatchExpressions: reg.Spec.RegistryDeployment.Selector.MatchExpressions,
@@ -165,12 +179,18 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 									Name:  "REGISTRY_HTTP_TLS_KEY",
 									Value: registryTLSKeyPath,
 								},
-							},
-							VolumeMounts: []corev1.VolumeMount{
 								{
-									Name:      "registry",
-									MountPath: pvcMountPath,
+									Name: RegistryEnvKeyHTTPSecret,
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{Name: SubresourceName(reg, SubTypeRegistryOpaqueSecret)},
+											Key:                  CredentialSecretHTTPSecret,
+											Optional:             &optional,
+										},
+									},
 								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      "config",
 									MountPath: configMapMountPath,
@@ -218,14 +238,6 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 						},
 					},
 					Volumes: []corev1.Volume{
-						{
-							Name: "registry",
-							VolumeSource: corev1.VolumeSource{
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: pvcName,
-								},
-							},
-						},
 						{
 							Name: "config",
 							VolumeSource: corev1.VolumeSource{
@@ -238,7 +250,7 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 							Name: "https",
 							VolumeSource: corev1.VolumeSource{
 								Secret: &corev1.SecretVolumeSource{
-									SecretName: SubresourceName(reg, SubTypeRegistryTLSSecret),
+									SecretName: RegistryTLSSecretName(reg),
 								},
 							},
 						},
@@ -246,7 +258,7 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 							Name: "auth",
 							VolumeSource: corev1.VolumeSource{
 								Secret: &corev1.SecretVolumeSource{
-									SecretName: "registry-token-key",
+									SecretName: regv1.RegistryTokenKeySecretName,
 								},
 							},
 						},
@@ -256,7 +268,56 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 		},
 	}
 
-	if reg.Spec.ReadOnly {
+	if affinity := RegistryAntiAffinity(reg.Spec.RegistryDeployment.AntiAffinity, labelSelector); affinity != nil {
+		deployment.Spec.Template.Spec.Affinity = affinity
+	}
+
+	if reg.Spec.Storage.UsePVC() {
+		podSpec := &deployment.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+			corev1.VolumeMount{
+				Name:      "registry",
+				MountPath: RegistryMountPath(reg),
+			},
+		)
+		podSpec.Volumes = append(podSpec.Volumes,
+			corev1.Volume{
+				Name: "registry",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: pvcName,
+					},
+				},
+			},
+		)
+	}
+
+	MountOperatorCA(reg, &deployment.Spec.Template.Spec)
+
+	if reg.Spec.Storage.Type == regv1.RegistryStorageTypeS3 && reg.Spec.Storage.S3 != nil {
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env,
+			s3CredentialEnv(RegistryEnvKeyS3AccessKey, reg.Spec.Storage.S3.CredentialSecret, regv1.S3AccessKey),
+			s3CredentialEnv(RegistryEnvKeyS3SecretKey, reg.Spec.Storage.S3.CredentialSecret, regv1.S3SecretKey),
+		)
+	}
+
+	if reg.Spec.Proxy != nil {
+		deployment.Spec.Template.Spec.Containers[0].Ports = append(deployment.Spec.Template.Spec.Containers[0].Ports,
+			corev1.ContainerPort{
+				Name:          "debug",
+				ContainerPort: RegistryProxyDebugPort,
+				Protocol:      "TCP",
+			},
+		)
+		// the keys are missing if the remote registry allows anonymous pull
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env,
+			credentialSecretEnv(reg, RegistryEnvKeyProxyUsername, CredentialSecretProxyUsername),
+			credentialSecretEnv(reg, RegistryEnvKeyProxyPassword, CredentialSecretProxyPassword),
+		)
+	}
+
+	// pull-through cache rejects push by itself and must write cached data to storage
+	if (reg.Spec.ReadOnly || reg.Status.Quota.IsExceeded()) && reg.Spec.Proxy == nil {
 		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env,
 			corev1.EnvVar{
 				Name:  RegistryEnvKeyStorageMaintenance,
@@ -265,9 +326,114 @@ func Deployment(reg *regv1.Registry, auth *regv1.AuthConfig) (*appsv1.Deployment
 		)
 	}
 
+	if len(reg.Spec.CustomConfigYml) == 0 {
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env,
+			credentialSecretEnv(reg, RegistryEnvKeyNotificationEndpoints, CredentialSecretNotificationEndpoints),
+		)
+	}
+
+	if reg.Status.ConfigHash != "" && len(reg.Spec.CustomConfigYml) == 0 {
+		deployment.Spec.Template.Annotations = map[string]string{ConfigHashAnnotation: reg.Status.ConfigHash}
+	}
+
 	if config.Config.GetString(config.ConfigRegistryImagePullSecret) != "" {
 		deployment.Spec.Template.Spec.ImagePullSecrets = append(deployment.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: config.Config.GetString(config.ConfigRegistryImagePullSecret)})
 	}
 
 	return deployment, nil
 }
+
+// MountOperatorCA mounts the CA of the operator's server in credential secret into registry container, which is trusted
+// in addition to the CA bundle of the registry image. It returns false if it is already mounted.
+func MountOperatorCA(reg *regv1.Registry, podSpec *corev1.PodSpec) bool {
+	for _, v := range podSpec.Volumes {
+		if v.Name == operatorCAVolume {
+			return false
+		}
+	}
+
+	// the CA is set only if the operator's server has a certificate
+	optional := true
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: operatorCAVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: SubresourceName(reg, SubTypeRegistryOpaqueSecret),
+				Items:      []corev1.KeyToPath{{Key: CredentialSecretOperatorCA, Path: path.Base(operatorCAPath)}},
+				Optional:   &optional,
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      operatorCAVolume,
+		MountPath: path.Dir(operatorCAPath),
+		ReadOnly:  true,
+	})
+	// certificates in SSL_CERT_DIR replace those in the default directories, while the bundle file is still loaded
+	container.Env = append(container.Env, corev1.EnvVar{Name: "SSL_CERT_DIR", Value: path.Dir(operatorCAPath)})
+
+	return true
+}
+
+// RegistryMountPath returns the path where registry's pvc is mounted
+func RegistryMountPath(reg *regv1.Registry) string {
+	if len(reg.Spec.PersistentVolumeClaim.MountPath) == 0 {
+		return RegistryPVCMountPath
+	}
+	return reg.Spec.PersistentVolumeClaim.MountPath
+}
+
+func s3CredentialEnv(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// credentialSecretEnv returns an env variable of the value in registry's credential secret, which is optional
+func credentialSecretEnv(reg *regv1.Registry, name, key string) corev1.EnvVar {
+	optional := true
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: SubresourceName(reg, SubTypeRegistryOpaqueSecret)},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		},
+	}
+}
+
+// RegistryAntiAffinity returns pod anti-affinity of registry pods selected by the label selector, or nil if it is not set
+func RegistryAntiAffinity(affinityType regv1.RegistryAntiAffinityType, labelSelector map[string]string) *corev1.Affinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: labelSelector},
+		TopologyKey:   corev1.LabelHostname,
+	}
+
+	switch affinityType {
+	case regv1.RegistryAntiAffinityPreferred:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{Weight: 100, PodAffinityTerm: term},
+				},
+			},
+		}
+	case regv1.RegistryAntiAffinityRequired:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{term},
+			},
+		}
+	}
+
+	return nil
+}
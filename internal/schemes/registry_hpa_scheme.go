@@ -0,0 +1,43 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RegistryHPADefaultTargetCPU is registry's default target average cpu utilization
+	RegistryHPADefaultTargetCPU = int32(80)
+)
+
+// HorizontalPodAutoscaler is a scheme of registry horizontal pod autoscaler
+func HorizontalPodAutoscaler(reg *regv1.Registry) *autoscalingv1.HorizontalPodAutoscaler {
+	resName := SubresourceName(reg, SubTypeRegistryHPA)
+	minReplicas := reg.Spec.RegistryDeployment.DesiredReplicas()
+	targetCPU := RegistryHPADefaultTargetCPU
+	if reg.Spec.RegistryDeployment.Autoscaling.TargetCPUUtilizationPercentage != nil {
+		targetCPU = *reg.Spec.RegistryDeployment.Autoscaling.TargetCPUUtilizationPercentage
+	}
+
+	return &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resName,
+			Namespace: reg.Namespace,
+			Labels: map[string]string{
+				"app":  "registry",
+				"apps": resName,
+			},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       SubresourceName(reg, SubTypeRegistryDeployment),
+			},
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    reg.Spec.RegistryDeployment.Autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: &targetCPU,
+		},
+	}
+}
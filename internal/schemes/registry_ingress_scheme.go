@@ -1,10 +1,7 @@
 package schemes
 
 import (
-	"strings"
-
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
-	"github.com/tmax-cloud/registry-operator/internal/common/ingress"
 
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,7 +23,7 @@ func Ingress(reg *regv1.Registry) *v1beta1.Ingress {
 				"apps": SubresourceName(reg, SubTypeRegistryIngress),
 			},
 			Annotations: map[string]string{
-				"kubernetes.io/ingress.class":                       "nginx-shd",
+				"kubernetes.io/ingress.class":                       IngressClassName(reg.Spec.RegistryService.ExposureOptions),
 				"nginx.ingress.kubernetes.io/proxy-connect-timeout": "3600",
 				"nginx.ingress.kubernetes.io/proxy-read-timeout":    "3600",
 				"nginx.ingress.kubernetes.io/ssl-redirect":          "true",
@@ -38,7 +35,7 @@ func Ingress(reg *regv1.Registry) *v1beta1.Ingress {
 			TLS: []v1beta1.IngressTLS{
 				{
 					Hosts:      []string{registryDomain},
-					SecretName: SubresourceName(reg, SubTypeRegistryTLSSecret),
+					SecretName: RegistryTLSSecretName(reg),
 				},
 			},
 			Rules: []v1beta1.IngressRule{
@@ -62,12 +59,3 @@ func Ingress(reg *regv1.Registry) *v1beta1.Ingress {
 		},
 	}
 }
-
-func RegistryDomainName(reg *regv1.Registry) string {
-	icIP := ingress.GetIngressControllerIP()
-	if icIP == "" {
-		return ""
-	}
-
-	return strings.Join([]string{reg.Namespace, reg.Name, "registry", icIP, "nip", "io"}, ".")
-}
@@ -0,0 +1,35 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// PodDisruptionBudget is a scheme of registry pod disruption budget.
+// It allows only one registry pod to be evicted at a time.
+func PodDisruptionBudget(reg *regv1.Registry) *policyv1beta1.PodDisruptionBudget {
+	resName := SubresourceName(reg, SubTypeRegistryPDB)
+	maxUnavailable := intstr.FromInt(1)
+
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resName,
+			Namespace: reg.Namespace,
+			Labels: map[string]string{
+				"app":  "registry",
+				"apps": resName,
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":  "registry",
+					"apps": SubresourceName(reg, SubTypeRegistryDeployment),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryUserSecret is a secret which has the username and the generated password of the registry user
+func RegistryUserSecret(user *regv1.RegistryUser, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SubresourceName(user, SubTypeRegistryUserSecret),
+			Namespace: user.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			regv1.RegistryUserUsernameKey: []byte(user.GetUsername()),
+			regv1.RegistryUserPasswordKey: []byte(password),
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RetentionPolicyJob is a scheme of retention policy job which is run once for the current generation of the policy
+func RetentionPolicyJob(policy *regv1.RetentionPolicy) *regv1.RegistryJob {
+	resName := SubresourceName(policy, SubTypeRetentionPolicyJob)
+	return registryJob(resName, policy.Namespace, "retention-policy-job", regv1.JobTypeRetentionPolicy, policy.Name)
+}
+
+// RetentionPolicyCronJob is a scheme of retention policy cron job which runs the policy by its schedule
+func RetentionPolicyCronJob(policy *regv1.RetentionPolicy) *regv1.RegistryCronJob {
+	resName := SubresourceName(policy, SubTypeRetentionPolicyCronJob)
+	return &regv1.RegistryCronJob{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      resName,
+			Namespace: policy.Namespace,
+			Labels: map[string]string{
+				"app":  "retention-policy-cron-job",
+				"apps": resName,
+			},
+		},
+		Spec: regv1.RegistryCronJobSpec{
+			JobSpec: regv1.RegistryJobSpec{
+				Priority: 0,
+				Claim: &regv1.RegistryJobClaim{
+					JobType: regv1.JobTypeRetentionPolicy,
+					HandleObject: corev1.LocalObjectReference{
+						Name: policy.Name,
+					},
+				},
+				TTL: 180,
+			},
+			Schedule: policy.Spec.Schedule,
+		},
+	}
+}
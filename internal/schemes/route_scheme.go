@@ -0,0 +1,73 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RouteGVK returns Gateway API's route kind.
+// Routes are handled as unstructured not to depend on Gateway API.
+func RouteGVK(kind regv1.GatewayRouteKind) schema.GroupVersionKind {
+	if kind == regv1.GatewayRouteKindHTTP {
+		return schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: string(regv1.GatewayRouteKindHTTP)}
+	}
+	return schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: string(regv1.GatewayRouteKindTLS)}
+}
+
+// RegistryRoute is Gateway API route to registry service. It returns nil if the hostname is not known yet.
+func RegistryRoute(reg *regv1.Registry) *unstructured.Unstructured {
+	hostname := RegistryDomainName(reg)
+	if hostname == "" || reg.Spec.RegistryService.Gateway == nil {
+		return nil
+	}
+
+	resName := SubresourceName(reg, SubTypeRegistryRoute)
+	return route(reg.Spec.RegistryService.Gateway, resName, reg.Namespace, map[string]string{"app": "registry", "apps": resName},
+		hostname, SubresourceName(reg, SubTypeRegistryService), 443)
+}
+
+// NotaryServerRoute is Gateway API route to notary server service. It returns nil if the hostname is not known yet.
+func NotaryServerRoute(notary *regv1.Notary) *unstructured.Unstructured {
+	hostname := NotaryDomainName(notary)
+	if hostname == "" || notary.Spec.Gateway == nil {
+		return nil
+	}
+
+	resName := SubresourceName(notary, SubTypeNotaryServerRoute)
+	return route(notary.Spec.Gateway, resName, notary.Namespace, map[string]string{"app": "notary-server", "apps": resName},
+		hostname, SubresourceName(notary, SubTypeNotaryServerService), NotaryServerDefaultPort)
+}
+
+func route(gateway *regv1.GatewayRef, name, namespace string, labels map[string]string, hostname, service string, port int64) *unstructured.Unstructured {
+	parent := map[string]interface{}{"name": gateway.Name}
+	if gateway.Namespace != "" {
+		parent["namespace"] = gateway.Namespace
+	}
+	if gateway.SectionName != "" {
+		parent["sectionName"] = gateway.SectionName
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parent},
+		"hostnames":  []interface{}{hostname},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": service,
+						"port": port,
+					},
+				},
+			},
+		},
+	}
+
+	r := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	r.SetGroupVersionKind(RouteGVK(gateway.GetRouteKind()))
+	r.SetName(name)
+	r.SetNamespace(namespace)
+	r.SetLabels(labels)
+
+	return r
+}
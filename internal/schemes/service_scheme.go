@@ -8,11 +8,6 @@ import (
 )
 
 func Service(reg *regv1.Registry) *corev1.Service {
-	serviceName := reg.Spec.RegistryService.ServiceType
-	if serviceName != regv1.RegServiceTypeLoadBalancer {
-		serviceName = regv1.RegServiceTypeIngress
-	}
-
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      SubresourceName(reg, SubTypeRegistryService),
@@ -23,7 +18,7 @@ func Service(reg *regv1.Registry) *corev1.Service {
 			},
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceType(serviceName),
+			Type: kubernetesServiceType(string(reg.Spec.RegistryService.ServiceType)),
 			Selector: map[string]string{
 				SubresourceName(reg, SubTypeRegistryService): "lb",
 			},
@@ -1,17 +1,13 @@
 package schemes
 
 import (
-	"context"
 	"crypto/rsa"
 	"crypto/x509"
-	"encoding/pem"
-	"errors"
+	"strconv"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
-	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
 	"github.com/tmax-cloud/registry-operator/internal/utils"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -26,6 +22,10 @@ const (
 	LoginSecretPrefix      = "login-"
 	ImageReplicatePrefix   = "repl-"
 	SynchronizePrefix      = "sync-"
+	BackupPrefix           = "backup-"
+	RestorePrefix          = "restore-"
+	UserPrefix             = "user-"
+	RetentionPrefix        = "retention-"
 )
 
 const (
@@ -33,6 +33,7 @@ const (
 	SubTypeNotaryDBPVC
 	SubTypeNotaryDBService
 	SubTypeNotaryServerIngress
+	SubTypeNotaryServerRoute
 	SubTypeNotaryServerPod
 	SubTypeNotaryServerSecret
 	SubTypeNotaryServerService
@@ -44,11 +45,15 @@ const (
 	SubTypeRegistryService
 	SubTypeRegistryPVC
 	SubTypeRegistryDCJSecret
+	SubTypeRegistryDistributedDCJSecret
 	SubTypeRegistryOpaqueSecret
 	SubTypeRegistryTLSSecret
 	SubTypeRegistryDeployment
 	SubTypeRegistryConfigmap
 	SubTypeRegistryIngress
+	SubTypeRegistryRoute
+	SubTypeRegistryPDB
+	SubTypeRegistryHPA
 
 	SubTypeExternalRegistryLoginSecret
 	SubTypeExternalRegistryCronJob
@@ -57,6 +62,15 @@ const (
 	SubTypeImageReplicateJob
 	SubTypeImageReplicateSyncJob
 	SubTypeImageReplicateImageSignRequest
+
+	SubTypeRegistryBackupJob
+	SubTypeRegistryRestoreJob
+
+	SubTypeRegistryUserSecret
+	SubTypeRegistryUserDCJSecret
+
+	SubTypeRetentionPolicyJob
+	SubTypeRetentionPolicyCronJob
 )
 
 // SubresourceName returns Notary's or Registry's subresource name
@@ -69,7 +83,7 @@ func SubresourceName(subresource interface{}, subresourceType SubresourceType) s
 			return regv1.K8sPrefix + regv1.K8sNotaryPrefix + NotaryDBPrefix + res.Name
 
 		// Notary Server
-		case SubTypeNotaryServerIngress, SubTypeNotaryServerPod, SubTypeNotaryServerSecret, SubTypeNotaryServerService:
+		case SubTypeNotaryServerIngress, SubTypeNotaryServerRoute, SubTypeNotaryServerPod, SubTypeNotaryServerSecret, SubTypeNotaryServerService:
 			return regv1.K8sPrefix + regv1.K8sNotaryPrefix + NotaryServerPrefix + res.Name
 
 		// Notary signer
@@ -82,7 +96,8 @@ func SubresourceName(subresource interface{}, subresourceType SubresourceType) s
 		case SubTypeRegistryNotary:
 			return res.Name
 
-		case SubTypeRegistryService, SubTypeRegistryPVC, SubTypeRegistryDeployment, SubTypeRegistryOpaqueSecret, SubTypeRegistryConfigmap, SubTypeRegistryIngress:
+		case SubTypeRegistryService, SubTypeRegistryPVC, SubTypeRegistryDeployment, SubTypeRegistryOpaqueSecret, SubTypeRegistryConfigmap, SubTypeRegistryIngress,
+			SubTypeRegistryRoute, SubTypeRegistryPDB, SubTypeRegistryHPA:
 			return regv1.K8sPrefix + res.Name
 
 		case SubTypeRegistryTLSSecret:
@@ -90,6 +105,10 @@ func SubresourceName(subresource interface{}, subresourceType SubresourceType) s
 
 		case SubTypeRegistryDCJSecret:
 			return regv1.K8sPrefix + regv1.K8sRegistryPrefix + res.Name
+
+		case SubTypeRegistryDistributedDCJSecret:
+			// copies in other namespaces are qualified with registry's namespace not to collide
+			return regv1.K8sPrefix + regv1.K8sRegistryPrefix + res.Name + "." + res.Namespace
 		}
 
 	case *regv1.ExternalRegistry:
@@ -114,6 +133,35 @@ func SubresourceName(subresource interface{}, subresourceType SubresourceType) s
 			}
 			return regv1.K8sPrefix + ImageReplicatePrefix + res.Name + "-" + utils.RandomString(10)
 		}
+
+	case *regv1.RegistryBackup:
+		switch subresourceType {
+		case SubTypeRegistryBackupJob:
+			return regv1.K8sPrefix + BackupPrefix + res.Name
+		}
+
+	case *regv1.RegistryRestore:
+		switch subresourceType {
+		case SubTypeRegistryRestoreJob:
+			return regv1.K8sPrefix + RestorePrefix + res.Name
+		}
+
+	case *regv1.RegistryUser:
+		switch subresourceType {
+		case SubTypeRegistryUserSecret:
+			return regv1.K8sPrefix + UserPrefix + res.Name
+		case SubTypeRegistryUserDCJSecret:
+			return regv1.K8sPrefix + regv1.K8sRegistryPrefix + UserPrefix + res.Name
+		}
+
+	case *regv1.RetentionPolicy:
+		switch subresourceType {
+		case SubTypeRetentionPolicyJob:
+			// a job is run for each generation of the policy
+			return regv1.K8sPrefix + RetentionPrefix + res.Name + "-" + strconv.FormatInt(res.Generation, 10)
+		case SubTypeRetentionPolicyCronJob:
+			return regv1.K8sPrefix + RetentionPrefix + res.Name
+		}
 	}
 
 	return ""
@@ -127,52 +175,17 @@ const (
 func getRootCACertificate(c client.Client) (*x509.Certificate, *rsa.PrivateKey) {
 	logger := logf.Log.WithName("cert-util")
 
-	opNamespace := config.Config.GetString("operator.namespace")
-	if opNamespace == "" {
-		opNamespace = regv1.OperatorNamespace
-	}
-
-	rootSecret := corev1.Secret{}
-	req := types.NamespacedName{Name: regv1.RegistryRootCASecretName, Namespace: opNamespace}
-	if err := c.Get(context.TODO(), req, &rootSecret); err != nil {
+	rootSecret, err := certs.GetSystemRootCASecret(c)
+	if err != nil {
 		logger.Error(err, "Get Root Secret Error")
 		return nil, nil
 	}
 
-	block, rest := pem.Decode(rootSecret.Data[RootCACert])
-	if len(rest) != 0 {
-		logger.Info("Cert is not PEM format", "Rest", rest)
-		return nil, nil
-	}
-
-	if block == nil {
-		logger.Error(errors.New("failed to decode pem data"), "maybe there is no pem data")
-		return nil, nil
-	}
-
-	cert, err := x509.ParseCertificate(block.Bytes)
+	cert, key, err := certs.ParseCA(rootSecret)
 	if err != nil {
-		logger.Error(err, "Parse Root CA block Error")
-		return nil, nil
-	}
-
-	privBlock, privRest := pem.Decode(rootSecret.Data[RootCAPriv])
-	if len(privRest) != 0 {
-		logger.Info("Private key is not PEM format", "Rest", privRest)
+		logger.Error(err, "Parse Root CA Error")
 		return nil, nil
 	}
 
-	var key interface{}
-	var privKeyErr error
-
-	key, privKeyErr = x509.ParsePKCS8PrivateKey(privBlock.Bytes)
-	if privKeyErr != nil {
-		key, privKeyErr = x509.ParsePKCS1PrivateKey(privBlock.Bytes)
-		if privKeyErr != nil {
-			logger.Error(privKeyErr, "Parse private key Error")
-			return nil, nil
-		}
-	}
-
-	return cert, key.(*rsa.PrivateKey)
+	return cert, key
 }
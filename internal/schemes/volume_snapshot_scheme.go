@@ -0,0 +1,47 @@
+package schemes
+
+import (
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// VolumeSnapshotGVK is csi snapshotter's VolumeSnapshot kind.
+// It is handled as unstructured not to depend on external-snapshotter.
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// RegistryPVCName returns the name of pvc used by registry
+func RegistryPVCName(reg *regv1.Registry) string {
+	if reg.Spec.PersistentVolumeClaim.Exist != nil {
+		return reg.Spec.PersistentVolumeClaim.Exist.PvcName
+	}
+	return SubresourceName(reg, SubTypeRegistryPVC)
+}
+
+// VolumeSnapshot is a scheme of the snapshot of registry's pvc taken when registry is deleted.
+// It is not owned by registry to outlive it, and its name has registry's uid not to be confused with a registry recreated with the same name.
+func VolumeSnapshot(reg *regv1.Registry) *unstructured.Unstructured {
+	source := map[string]interface{}{
+		"persistentVolumeClaimName": RegistryPVCName(reg),
+	}
+	spec := map[string]interface{}{"source": source}
+	if reg.Spec.VolumeSnapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = reg.Spec.VolumeSnapshotClassName
+	}
+
+	name := SubresourceName(reg, SubTypeRegistryPVC)
+	if uid := string(reg.UID); len(uid) >= 8 {
+		name += "-" + uid[:8]
+	}
+
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(reg.Namespace)
+	snapshot.SetLabels(map[string]string{
+		"app":      "registry",
+		"registry": reg.Name,
+	})
+
+	return snapshot
+}
@@ -38,12 +38,17 @@ func NewCertPair(key *rsa.PrivateKey, isCA bool) (*CertPair, error) {
 		return nil, err
 	}
 
+	keyUsage := x509.KeyUsageCRLSign
+	if isCA {
+		keyUsage |= x509.KeyUsageCertSign
+	}
+
 	return &CertPair{
 		Template: &x509.Certificate{
 			SerialNumber:          serialNumber,
 			NotBefore:             time.Now(),
 			NotAfter:              time.Now().Add(time.Hour * 24 * 365 * 10),
-			KeyUsage:              x509.KeyUsageCRLSign,
+			KeyUsage:              keyUsage,
 			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
 			IsCA:                  isCA,
 			BasicConstraintsValid: true,
@@ -61,6 +66,12 @@ func (c *CertPair) SetSubject(subject *pkix.Name) {
 	c.Template.Subject = *subject
 }
 
+// SetValidity sets the certificate valid from now for the duration
+func (c *CertPair) SetValidity(d time.Duration) {
+	c.Template.NotBefore = time.Now()
+	c.Template.NotAfter = c.Template.NotBefore.Add(d)
+}
+
 func (c *CertPair) SetSubjectAltName(ips []net.IP, domains []string) {
 	c.Template.IPAddresses = append(c.Template.IPAddresses, ips...)
 	c.Template.DNSNames = append(c.Template.DNSNames, domains...)
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
 	"math/rand"
 	"time"
 )
@@ -17,3 +19,13 @@ func RandomString(length int) string {
 
 	return string(str)
 }
+
+// RandomSecret generate cryptographically secure random hex string from the given number of bytes
+func RandomSecret(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
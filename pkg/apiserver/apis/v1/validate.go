@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/admission"
+	"gomodules.xyz/jsonpatch/v2"
+	"k8s.io/api/admission/v1beta1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+var admissionScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(regv1.AddToScheme(admissionScheme))
+}
+
+// Validate rejects tmax.io objects which have invalid spec with field path errors
+func Validate(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	req := ar.Request
+	logger.Info(fmt.Sprintf("AdmissionReview for Kind=%v, Namespace=%v Name=%v  UID=%v patchOperation=%v UserInfo=%v",
+		req.Kind, req.Namespace, req.Name, req.UID, req.Operation, req.UserInfo))
+
+	obj, err := decodeObject(req)
+	if err != nil {
+		logger.Error(err, "unable to decode object", "kind", req.Kind.Kind, "name", req.Name)
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	// objects being deleted are only updated to remove their finalizers
+	if accessor, ok := obj.(metav1.Object); ok && accessor.GetDeletionTimestamp() != nil {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+	if errs := admission.Validate(obj); len(errs) > 0 {
+		status := k8serr.NewInvalid(schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}, req.Name, errs).ErrStatus
+		return &v1beta1.AdmissionResponse{
+			Result: &status,
+		}
+	}
+
+	return &v1beta1.AdmissionResponse{
+		Allowed: true,
+	}
+}
+
+// Default sets default values of tmax.io objects
+func Default(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	req := ar.Request
+	logger.Info(fmt.Sprintf("AdmissionReview for Kind=%v, Namespace=%v Name=%v  UID=%v patchOperation=%v UserInfo=%v",
+		req.Kind, req.Namespace, req.Name, req.UID, req.Operation, req.UserInfo))
+
+	obj, err := decodeObject(req)
+	if err != nil {
+		logger.Error(err, "unable to decode object", "kind", req.Kind.Kind, "name", req.Name)
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	admission.Default(obj, req.Namespace)
+	defaulted, err := json.Marshal(obj)
+	if err != nil {
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+	patch, err := jsonpatch.CreatePatch(req.Object.Raw, defaulted)
+	if err != nil {
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+	if len(patch) == 0 {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return &v1beta1.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *v1beta1.PatchType {
+			pt := v1beta1.PatchTypeJSONPatch
+			return &pt
+		}(),
+	}
+}
+
+// decodeObject decodes the object of the request into its typed object
+func decodeObject(req *v1beta1.AdmissionRequest) (runtime.Object, error) {
+	obj, err := admissionScheme.New(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
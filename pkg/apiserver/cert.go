@@ -28,13 +28,14 @@ const (
 	K8sConfigMapName = "extension-apiserver-authentication"
 	K8sConfigMapKey  = "requestheader-client-ca-file"
 
-	APIServiceName                   = "v1.registry.tmax.io"
-	MutatingWebhookConfigurationName = "registry-operator-webhook-cfg"
+	APIServiceName                     = "v1.registry.tmax.io"
+	MutatingWebhookConfigurationName   = "registry-operator-webhook-cfg"
+	ValidatingWebhookConfigurationName = "registry-operator-validating-webhook-cfg"
 )
 
 // Create and Store certificates for webhook server
 // server key / server cert is stored as file in CertDir
-// CA bundle is stored in ValidatingWebhookConfigurations
+// CA bundle is stored in ApiService, MutatingWebhookConfiguration and ValidatingWebhookConfiguration
 func createCert(ctx context.Context, client client.Client) error {
 	// Make directory recursively
 	if err := os.MkdirAll(CertDir, os.ModePerm); err != nil {
@@ -82,15 +83,28 @@ func createCert(ctx context.Context, client client.Client) error {
 	if err := client.Get(ctx, types.NamespacedName{Name: MutatingWebhookConfigurationName}, mwConfig); err != nil {
 		return err
 	}
-	if len(mwConfig.Webhooks) != 2 {
-		return fmt.Errorf("MutatingWebhookConfiguration's webhook must be two, but there is/are %d", len(mwConfig.Webhooks))
+	if len(mwConfig.Webhooks) == 0 {
+		return fmt.Errorf("MutatingWebhookConfiguration has no webhook")
+	}
+	for i := range mwConfig.Webhooks {
+		mwConfig.Webhooks[i].ClientConfig.CABundle = caCrt
 	}
-	mwConfig.Webhooks[0].ClientConfig.CABundle = caCrt
-	mwConfig.Webhooks[1].ClientConfig.CABundle = caCrt
 	if err := client.Update(ctx, mwConfig); err != nil {
 		return err
 	}
 
+	// Update ValidatingWebhookConfiguration
+	vwConfig := &v1beta1.ValidatingWebhookConfiguration{}
+	if err := client.Get(ctx, types.NamespacedName{Name: ValidatingWebhookConfigurationName}, vwConfig); err != nil {
+		return err
+	}
+	for i := range vwConfig.Webhooks {
+		vwConfig.Webhooks[i].ClientConfig.CABundle = caCrt
+	}
+	if err := client.Update(ctx, vwConfig); err != nil {
+		return err
+	}
+
 	return nil
 }
 
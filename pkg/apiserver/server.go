@@ -49,6 +49,8 @@ func New() *Server {
 	server.Wrapper.Router.HandleFunc("/", server.rootHandler)
 	server.Wrapper.Router.HandleFunc("/mutate", server.mutateHandler)
 	server.Wrapper.Router.HandleFunc("/imagesignrequest", server.imageSignRequestHandler)
+	server.Wrapper.Router.HandleFunc("/validate", server.validateHandler)
+	server.Wrapper.Router.HandleFunc("/default", server.defaultHandler)
 
 	if err := apis.AddApis(server.Wrapper); err != nil {
 		log.Error(err, "cannot add apis")
@@ -125,61 +127,28 @@ var (
 )
 
 func (s *Server) mutateHandler(w http.ResponseWriter, r *http.Request) {
-	paths := metav1.RootPaths{Paths: []string{"/mutate"}}
-	addPath(&paths.Paths, s.Wrapper)
+	s.serveAdmission(w, r, "/mutate", func(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+		return v1.Mutate(ar, s.Client)
+	})
+}
 
-	var body []byte
-	if r.Body != nil {
-		if data, err := ioutil.ReadAll(r.Body); err == nil {
-			body = data
-		}
-	}
-	if len(body) == 0 {
-		log.Error(nil, "empty body")
-		http.Error(w, "empty body", http.StatusBadRequest)
-		return
-	}
-	// verify the content type is accurate
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		log.Error(nil, "Content-Type=%s, expect application/json", contentType)
-		http.Error(w, "invalid Content-Type, expect `application/json`", http.StatusUnsupportedMediaType)
-		return
-	}
-	var admissionResponse *v1beta1.AdmissionResponse
-	ar := v1beta1.AdmissionReview{}
-	if _, _, err := deserializer.Decode(body, nil, &ar); err != nil {
-		log.Error(nil, "Can't decode body: %v", err)
-		admissionResponse = &v1beta1.AdmissionResponse{
-			Result: &metav1.Status{
-				Message: err.Error(),
-			},
-		}
-	} else {
-		admissionResponse = v1.Mutate(&ar, s.Client)
-	}
-	admissionReview := v1beta1.AdmissionReview{}
-	if admissionResponse != nil {
-		admissionReview.Response = admissionResponse
-		if ar.Request != nil {
-			admissionReview.Response.UID = ar.Request.UID
-		}
-	}
+func (s *Server) imageSignRequestHandler(w http.ResponseWriter, r *http.Request) {
+	s.serveAdmission(w, r, "/imagesignrequest", func(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+		return v1.ImageSignRequest(ar, w, r)
+	})
+}
 
-	resp, err := json.Marshal(admissionReview)
-	if err != nil {
-		log.Error(nil, "Can't encode response: %v", err)
-		http.Error(w, fmt.Sprintf("could not encode response: %v", err), http.StatusInternalServerError)
-	}
-	log.Info("Ready to write reponse ...")
-	if _, err := w.Write(resp); err != nil {
-		log.Error(nil, "Can't write response: %v", err)
-		http.Error(w, fmt.Sprintf("could not write response: %v", err), http.StatusInternalServerError)
-	}
+func (s *Server) validateHandler(w http.ResponseWriter, r *http.Request) {
+	s.serveAdmission(w, r, "/validate", v1.Validate)
 }
 
-func (s *Server) imageSignRequestHandler(w http.ResponseWriter, r *http.Request) {
-	paths := metav1.RootPaths{Paths: []string{"/imagesignrequest"}}
+func (s *Server) defaultHandler(w http.ResponseWriter, r *http.Request) {
+	s.serveAdmission(w, r, "/default", v1.Default)
+}
+
+// serveAdmission decodes admission review of the request, reviews it and writes the response
+func (s *Server) serveAdmission(w http.ResponseWriter, r *http.Request, endpoint string, review func(*v1beta1.AdmissionReview) *v1beta1.AdmissionResponse) {
+	paths := metav1.RootPaths{Paths: []string{endpoint}}
 	addPath(&paths.Paths, s.Wrapper)
 
 	var body []byte
@@ -210,7 +179,7 @@ func (s *Server) imageSignRequestHandler(w http.ResponseWriter, r *http.Request)
 			},
 		}
 	} else {
-		admissionResponse = v1.ImageSignRequest(&ar, w, r)
+		admissionResponse = review(&ar)
 	}
 	admissionReview := v1beta1.AdmissionReview{}
 	if admissionResponse != nil {
@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/docker/distribution/registry/auth/token"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	anonymousUser  = "system:anonymous"
+	anonymousGroup = "system:unauthenticated"
+
+	resourceTypeRepository = "repository"
+	resourceTypeRegistry   = "registry"
+	catalogName            = "catalog"
+)
+
+// Identity is the requester of a token
+type Identity struct {
+	Username string
+	Groups   []string
+	// Admin is true for the registry's login user and registry users, who are allowed everything
+	Admin bool
+}
+
+// Subject returns the subject claim of tokens. It is empty for anonymous requester.
+func (i *Identity) Subject() string {
+	if i.Username == anonymousUser {
+		return ""
+	}
+	return i.Username
+}
+
+// authenticate returns the identity of the request.
+// Credentials are the registry's login user, an active RegistryUser of the registry
+// or any username with a Kubernetes token as its password.
+func (s *TokenService) authenticate(req *TokenRequest) (*Identity, error) {
+	if req.Username == "" && req.Password == "" {
+		return &Identity{Username: anonymousUser, Groups: []string{anonymousGroup}}, nil
+	}
+
+	reg := &regv1.Registry{ObjectMeta: metav1.ObjectMeta{Name: req.Service, Namespace: req.Namespace}}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryOpaqueSecret), Namespace: req.Namespace}
+	if err := s.client.Get(context.TODO(), key, secret); err != nil {
+		logger.Error(err, "failed to get registry credential", "namespace", req.Namespace, "registry", req.Service)
+		return nil, ErrUnauthorized
+	}
+	idMatch := subtle.ConstantTimeCompare(secret.Data[schemes.CredentialSecretID], []byte(req.Username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare(secret.Data[schemes.CredentialSecretPassword], []byte(req.Password)) == 1 ||
+		previousPasswordMatch(secret, req.Password, time.Now())
+	if idMatch && passwordMatch {
+		return &Identity{Username: req.Username, Admin: true}, nil
+	}
+
+	user, err := s.registryUser(req)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	review := &authnv1.TokenReview{
+		Spec: authnv1.TokenReviewSpec{Token: req.Password},
+	}
+	if err := s.client.Create(context.TODO(), review); err != nil {
+		logger.Error(err, "failed to review token")
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, ErrUnauthorized
+	}
+
+	return &Identity{Username: review.Status.User.Username, Groups: review.Status.User.Groups}, nil
+}
+
+// previousPasswordMatch returns true if the password is the login password before the latest rotation
+// and its grace period is not over
+func previousPasswordMatch(secret *corev1.Secret, password string, now time.Time) bool {
+	previous := secret.Data[schemes.CredentialSecretPreviousPassword]
+	if len(previous) == 0 {
+		return false
+	}
+	expiry, err := time.Parse(time.RFC3339, string(secret.Data[schemes.CredentialSecretPreviousPasswordExpiry]))
+	if err != nil || !now.Before(expiry) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(previous, []byte(password)) == 1
+}
+
+// registryUser returns the identity of an active RegistryUser of the registry matching the credential, or nil if not matched
+func (s *TokenService) registryUser(req *TokenRequest) (*Identity, error) {
+	users := &regv1.RegistryUserList{}
+	if err := s.client.List(context.TODO(), users, client.InNamespace(req.Namespace)); err != nil {
+		logger.Error(err, "failed to list registry users", "namespace", req.Namespace)
+		return nil, err
+	}
+
+	now := metav1.Now()
+	for _, user := range users.Items {
+		if user.Spec.Registry != req.Service || user.GetUsername() != req.Username {
+			continue
+		}
+		// an expired user may remain with the username of a new one
+		if user.Status.State != regv1.RegistryUserActive || user.IsExpired(now) {
+			continue
+		}
+
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: schemes.SubresourceName(&user, schemes.SubTypeRegistryUserSecret), Namespace: req.Namespace}
+		if err := s.client.Get(context.TODO(), key, secret); err != nil {
+			logger.Error(err, "failed to get registry user secret", "namespace", req.Namespace, "name", user.Name)
+			return nil, nil
+		}
+		if subtle.ConstantTimeCompare(secret.Data[regv1.RegistryUserPasswordKey], []byte(req.Password)) == 1 {
+			return &Identity{Username: req.Username, Admin: true}, nil
+		}
+		return nil, nil
+	}
+
+	return nil, nil
+}
+
+// authorize returns access allowed to the identity among the requested access
+func (s *TokenService) authorize(identity *Identity, namespace, registry string, access []*token.ResourceActions) ([]*token.ResourceActions, error) {
+	if identity.Admin {
+		return access, nil
+	}
+
+	perms := &regv1.RepositoryPermissionList{}
+	if err := s.client.List(context.TODO(), perms, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	granted := []*token.ResourceActions{}
+	for _, resource := range access {
+		allowed := []string{}
+		for _, action := range resource.Actions {
+			ok, err := s.allowed(identity, namespace, registry, perms.Items, resource, action)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				allowed = append(allowed, action)
+			}
+		}
+		if len(allowed) > 0 {
+			granted = append(granted, &token.ResourceActions{Type: resource.Type, Class: resource.Class, Name: resource.Name, Actions: allowed})
+		}
+	}
+
+	return granted, nil
+}
+
+// allowed checks if the action is allowed by RepositoryPermissions or RBAC.
+// Actions on a repository are verbs on the Repository object, and listing the catalog is list verb on repositories.
+func (s *TokenService) allowed(identity *Identity, namespace, registry string, perms []regv1.RepositoryPermission, resource *token.ResourceActions, action string) (bool, error) {
+	attrs := &authzv1.ResourceAttributes{
+		Namespace: namespace,
+		Group:     regv1.GroupVersion.Group,
+		Resource:  "repositories",
+	}
+	switch {
+	case resource.Type == resourceTypeRepository:
+		for _, perm := range perms {
+			if permits(&perm, identity, registry, resource.Name, action) {
+				return true, nil
+			}
+		}
+		attrs.Verb = action
+		attrs.Name = schemes.RepositoryName(resource.Name, registry)
+	case resource.Type == resourceTypeRegistry && resource.Name == catalogName:
+		attrs.Verb = "list"
+	default:
+		return false, nil
+	}
+
+	review := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			ResourceAttributes: attrs,
+			User:               identity.Username,
+			Groups:             identity.Groups,
+		},
+	}
+	if err := s.client.Create(context.TODO(), review); err != nil {
+		logger.Error(err, "failed to review subject access")
+		return false, err
+	}
+
+	return review.Status.Allowed, nil
+}
+
+// permits checks if the permission grants the action on the repository to the identity
+func permits(perm *regv1.RepositoryPermission, identity *Identity, registry, repository, action string) bool {
+	if perm.Spec.Registry != registry {
+		return false
+	}
+
+	actionMatched := false
+	for _, a := range perm.Spec.Actions {
+		if a == regv1.RepositoryActionAll || string(a) == action {
+			actionMatched = true
+			break
+		}
+	}
+	if !actionMatched {
+		return false
+	}
+
+	repoMatched := false
+	for _, pattern := range perm.Spec.Repositories {
+		if matched, _ := path.Match(pattern, repository); matched {
+			repoMatched = true
+			break
+		}
+	}
+	if !repoMatched {
+		return false
+	}
+
+	for _, subject := range perm.Spec.Subjects {
+		if subjectMatches(subject, perm.Namespace, identity) {
+			return true
+		}
+	}
+	return false
+}
+
+func subjectMatches(subject rbacv1.Subject, namespace string, identity *Identity) bool {
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		return subject.Name == identity.Username
+	case rbacv1.GroupKind:
+		for _, group := range identity.Groups {
+			if group == subject.Name {
+				return true
+			}
+		}
+	case rbacv1.ServiceAccountKind:
+		if subject.Namespace != "" {
+			namespace = subject.Namespace
+		}
+		return identity.Username == fmt.Sprintf("system:serviceaccount:%s:%s", namespace, subject.Name)
+	}
+	return false
+}
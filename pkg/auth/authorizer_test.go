@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPermits(t *testing.T) {
+	perm := &regv1.RepositoryPermission{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "reg-test"},
+		Spec: regv1.RepositoryPermissionSpec{
+			Registry:     "tmax-registry",
+			Repositories: []string{"team-a/*"},
+			Actions:      []regv1.RepositoryAction{regv1.RepositoryActionPull, regv1.RepositoryActionPush},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, Name: "team-a"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "ci"},
+			},
+		},
+	}
+
+	member := &Identity{Username: "alice", Groups: []string{"team-a"}}
+	assert.Equal(t, true, permits(perm, member, "tmax-registry", "team-a/app", "push"))
+	assert.Equal(t, false, permits(perm, member, "tmax-registry", "team-a/app", "delete"))
+	assert.Equal(t, false, permits(perm, member, "tmax-registry", "team-b/app", "pull"))
+	assert.Equal(t, false, permits(perm, member, "tmax-registry", "team-a/app/nested", "pull"))
+	assert.Equal(t, false, permits(perm, member, "other-registry", "team-a/app", "pull"))
+
+	sa := &Identity{Username: "system:serviceaccount:reg-test:ci"}
+	assert.Equal(t, true, permits(perm, sa, "tmax-registry", "team-a/app", "pull"))
+	otherSA := &Identity{Username: "system:serviceaccount:default:ci"}
+	assert.Equal(t, false, permits(perm, otherSA, "tmax-registry", "team-a/app", "pull"))
+}
+
+func TestPreviousPasswordMatch(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			schemes.CredentialSecretPassword:               []byte("new"),
+			schemes.CredentialSecretPreviousPassword:       []byte("old"),
+			schemes.CredentialSecretPreviousPasswordExpiry: []byte(now.Add(time.Hour).Format(time.RFC3339)),
+		},
+	}
+
+	assert.Equal(t, true, previousPasswordMatch(secret, "old", now))
+	assert.Equal(t, false, previousPasswordMatch(secret, "new", now))
+	assert.Equal(t, false, previousPasswordMatch(secret, "old", now.Add(time.Hour)))
+	assert.Equal(t, false, previousPasswordMatch(&corev1.Secret{}, "", now))
+}
+
+func TestRegistryUser(t *testing.T) {
+	newUser := func(name, state string, expiresAt *metav1.Time) (*regv1.RegistryUser, *corev1.Secret) {
+		user := &regv1.RegistryUser{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "reg-test"},
+			Spec:       regv1.RegistryUserSpec{Registry: "tmax-registry", Username: "ci", ExpiresAt: expiresAt},
+			Status:     regv1.RegistryUserStatus{State: regv1.RegistryUserState(state)},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: schemes.SubresourceName(user, schemes.SubTypeRegistryUserSecret), Namespace: "reg-test"},
+			Data:       map[string][]byte{regv1.RegistryUserPasswordKey: []byte(name)},
+		}
+		return user, secret
+	}
+	expired := metav1.NewTime(time.Now().Add(-time.Hour))
+	oldUser, oldSecret := newUser("ci-old", string(regv1.RegistryUserExpired), nil)
+	expiredUser, expiredSecret := newUser("ci-expired", string(regv1.RegistryUserActive), &expired)
+	activeUser, activeSecret := newUser("ci-new", string(regv1.RegistryUserActive), nil)
+
+	scheme := runtime.NewScheme()
+	assert.Equal(t, nil, corev1.AddToScheme(scheme))
+	assert.Equal(t, nil, regv1.AddToScheme(scheme))
+	s := &TokenService{client: fake.NewFakeClientWithScheme(scheme, oldUser, oldSecret, expiredUser, expiredSecret, activeUser, activeSecret)}
+
+	// inactive and expired users with the same username are skipped
+	req := &TokenRequest{Namespace: "reg-test", Service: "tmax-registry", Username: "ci", Password: "ci-new"}
+	identity, err := s.registryUser(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &Identity{Username: "ci", Admin: true}, identity)
+
+	for _, password := range []string{"ci-old", "ci-expired"} {
+		req.Password = password
+		identity, err = s.registryUser(req)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, (*Identity)(nil), identity)
+	}
+}
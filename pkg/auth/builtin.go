@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"path"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenPath is the path prefix of the builtin token service. A namespace is appended to it.
+const TokenPath = "/token"
+
+// builtinProvider uses the operator's token service, which signs tokens with the system root CA key
+type builtinProvider struct{}
+
+// TokenRealm returns realm(=issuer) of the builtin token service for registries in the namespace
+func TokenRealm(namespace string) string {
+	return tokenServiceURL(path.Join(TokenPath, namespace))
+}
+
+func (p *builtinProvider) AuthConfig(reg *regv1.Registry) *regv1.AuthConfig {
+	return &regv1.AuthConfig{
+		Realm:   TokenRealm(reg.Namespace),
+		Service: reg.Name,
+		Issuer:  TokenRealm(reg.Namespace),
+	}
+}
+
+// Prepare makes registries in the namespace trust the system root CA certificate for tokens
+func (p *builtinProvider) Prepare(ctx context.Context, c client.Client, reg *regv1.Registry) error {
+	rootCA, err := certs.GetSystemRootCASecret(c)
+	if err != nil {
+		logger.Error(err, "failed to get system root ca")
+		return err
+	}
+	crt, _ := certs.CAData(rootCA)
+
+	secret := &corev1.Secret{}
+	err = c.Get(ctx, types.NamespacedName{Name: regv1.RegistryTokenKeySecretName, Namespace: reg.Namespace}, secret)
+	if err != nil {
+		if !k8serr.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      regv1.RegistryTokenKeySecretName,
+				Namespace: reg.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{certs.RootCACert: crt},
+		})
+	}
+
+	if bytes.Equal(secret.Data[certs.RootCACert], crt) {
+		return nil
+	}
+	original := secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[certs.RootCACert] = crt
+	return c.Patch(ctx, secret, client.MergeFrom(original))
+}
+
+// Cleanup does nothing, since nothing is prepared per registry
+func (p *builtinProvider) Cleanup(ctx context.Context, c client.Client, namespace, name string) error {
+	return nil
+}
+
+// ProvisionUser does nothing, since the token service looks up RegistryUsers and their secrets directly
+func (p *builtinProvider) ProvisionUser(ctx context.Context, reg *regv1.Registry, username, password string) error {
+	return nil
+}
+
+// RevokeUser does nothing, since a deleted or expired RegistryUser cannot be authenticated anymore
+func (p *builtinProvider) RevokeUser(ctx context.Context, namespace, registry, username string) error {
+	return nil
+}
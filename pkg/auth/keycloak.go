@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v7"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// keycloakProvider manages a realm per namespace and a docker-v2 client per registry
+type keycloakProvider struct {
+	keycloak gocloak.GoCloak
+}
+
+func newKeycloakProvider() *keycloakProvider {
+	address := config.Config.GetString(config.ConfigTokenServiceAddr)
+	insecure := config.Config.GetBool(config.ConfigTokenServiceInsecure)
+	debug := config.Config.GetBool(config.ConfigTokenServiceDebug)
+
+	keycloak := gocloak.NewClient(address)
+	restyKeycloak := keycloak.RestyClient()
+	restyKeycloak.SetDebug(debug)
+
+	tlscfg := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+	if rootCAPath := os.Getenv("ROOTCA_PATH"); len(rootCAPath) > 0 {
+		rootCA, err := ioutil.ReadFile(rootCAPath)
+		if err != nil {
+			fmt.Println("failed to load root CA")
+			os.Exit(1)
+		}
+		certpool, err := x509.SystemCertPool()
+		if err != nil {
+			fmt.Println("failed to load system cert pool")
+			os.Exit(1)
+		}
+		if ok := certpool.AppendCertsFromPEM(rootCA); !ok {
+			fmt.Println("failed to add cert to pool")
+			os.Exit(1)
+		}
+		tlscfg.RootCAs = certpool
+	}
+	restyKeycloak.SetTLSClientConfig(tlscfg)
+
+	return &keycloakProvider{keycloak: keycloak}
+}
+
+func (p *keycloakProvider) AuthConfig(reg *regv1.Registry) *regv1.AuthConfig {
+	realmName := reg.Namespace
+	realmPath := path.Join("auth", "realms", realmName, "protocol", "docker-v2", "auth", "/")
+	issuerPath := path.Join("auth", "realms", realmName, "/")
+
+	return &regv1.AuthConfig{
+		Realm:   tokenServiceURL(realmPath),
+		Service: reg.Name,
+		Issuer:  tokenServiceURL(issuerPath),
+	}
+}
+
+func (p *keycloakProvider) login(ctx context.Context) (*gocloak.JWT, error) {
+	username := config.Config.GetString("keycloak.username")
+	password := config.Config.GetString("keycloak.password")
+	return p.keycloak.LoginAdmin(ctx, username, password, "master")
+}
+
+func (p *keycloakProvider) Prepare(ctx context.Context, c client.Client, reg *regv1.Registry) error {
+	token, err := p.login(ctx)
+	if err != nil {
+		logger.Error(err, "failed to login keycloak")
+		return err
+	}
+
+	enabled := true
+	realmName := reg.Namespace
+	realm, err := p.keycloak.GetRealm(ctx, token.AccessToken, realmName)
+	if err != nil {
+		apiError, ok := err.(*gocloak.APIError)
+		if !ok || apiError.Code != http.StatusNotFound {
+			return err
+		}
+		if _, err = p.keycloak.CreateRealm(ctx, token.AccessToken, gocloak.RealmRepresentation{
+			ID:      &realmName,
+			Realm:   &realmName,
+			Enabled: &enabled,
+		}); err != nil {
+			logger.Error(err, "failed to create realm")
+			return err
+		}
+		if realm, err = p.keycloak.GetRealm(ctx, token.AccessToken, realmName); err != nil {
+			return err
+		}
+	}
+	if realm == nil {
+		return fmt.Errorf("nil realm")
+	}
+	logger.Info("found realm", "realmID", realm.ID, "realm", realm.Realm)
+
+	clientName := reg.Name
+	protocol := "docker-v2"
+	clients, err := p.keycloak.GetClients(ctx, token.AccessToken, *realm.Realm, gocloak.GetClientsParams{})
+	if err != nil {
+		return err
+	}
+	isClientExist := false
+	for _, c := range clients {
+		if *c.ClientID == clientName {
+			isClientExist = true
+			break
+		}
+	}
+	if !isClientExist {
+		created, err := p.keycloak.CreateClient(ctx, token.AccessToken, realmName, gocloak.Client{
+			ClientID: &clientName,
+			Protocol: &protocol,
+		})
+		if err != nil {
+			logger.Error(err, "failed to create docker client")
+			return err
+		}
+		logger.Info("client created: " + created)
+	}
+
+	if err := p.createTokenKeySecret(ctx, c, token, realmName); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createTokenKeySecret creates the secret of realm's active RS256 certificate, if not exists
+func (p *keycloakProvider) createTokenKeySecret(ctx context.Context, c client.Client, token *gocloak.JWT, realmName string) error {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: realmName, Name: regv1.RegistryTokenKeySecretName}, secret)
+	if err == nil {
+		return nil
+	}
+	if !k8serr.IsNotFound(err) {
+		logger.Error(err, "failed to get registry token key secret")
+		return err
+	}
+
+	storeConfig, err := p.keycloak.GetKeyStoreConfig(ctx, token.AccessToken, realmName)
+	if err != nil {
+		logger.Error(err, "failed to get keystoreconfig")
+		return err
+	}
+
+	var tokenCACrt string
+	for _, k := range *storeConfig.Key {
+		if *k.Kid == *storeConfig.ActiveKeys.RS256 {
+			tokenCACrt = *k.Certificate
+		}
+	}
+	if len(tokenCACrt) == 0 {
+		err = fmt.Errorf("no key found")
+		logger.Error(err, "failed to get realm key")
+		return err
+	}
+	if err = c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: realmName,
+			Name:      regv1.RegistryTokenKeySecretName,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"ca.crt": []byte(
+				strings.Join([]string{
+					"-----BEGIN CERTIFICATE-----",
+					tokenCACrt,
+					"-----END CERTIFICATE-----"}, "\n"),
+			),
+		},
+	}); err != nil {
+		logger.Error(err, "failed to create registry token key secret")
+		return err
+	}
+
+	return nil
+}
+
+func (p *keycloakProvider) Cleanup(ctx context.Context, c client.Client, namespace, name string) error {
+	token, err := p.login(ctx)
+	if err != nil {
+		logger.Error(err, "failed to login keycloak")
+		return err
+	}
+
+	realmName := namespace
+	clientID := name
+	clients, err := p.keycloak.GetClients(ctx, token.AccessToken, realmName, gocloak.GetClientsParams{})
+	if err != nil {
+		logger.Error(err, "failed to get clients")
+		return err
+	}
+	for _, kc := range clients {
+		if *kc.ClientID == clientID {
+			if err = p.keycloak.DeleteClient(ctx, token.AccessToken, realmName, *kc.ID); err != nil {
+				logger.Error(err, "failed to delete client")
+				return err
+			}
+		}
+	}
+
+	// TODO: 더 이상 사용되지 않는 realm(namespace) 정리: 일정 주기로 realm을 감시하는 백그라운드 잡?
+	return nil
+}
+
+// findUser returns the realm user whose username is exactly the username, or nil if not exists
+func (p *keycloakProvider) findUser(ctx context.Context, token *gocloak.JWT, realmName, username string) (*gocloak.User, error) {
+	exact := true
+	users, err := p.keycloak.GetUsers(ctx, token.AccessToken, realmName, gocloak.GetUsersParams{
+		Username: &username,
+		Exact:    &exact,
+	})
+	if err != nil {
+		apiError, ok := err.(*gocloak.APIError)
+		if ok && apiError.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, u := range users {
+		if u.Username != nil && *u.Username == username {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ProvisionUser creates the user in the realm of registry's namespace and sets its password.
+// Users are shared by registries in a realm, so the username must be unique in the namespace.
+func (p *keycloakProvider) ProvisionUser(ctx context.Context, reg *regv1.Registry, username, password string) error {
+	token, err := p.login(ctx)
+	if err != nil {
+		logger.Error(err, "failed to login keycloak")
+		return err
+	}
+
+	realmName := reg.Namespace
+	user, err := p.findUser(ctx, token, realmName, username)
+	if err != nil {
+		logger.Error(err, "failed to get user", "username", username)
+		return err
+	}
+
+	var userID string
+	if user == nil {
+		enabled := true
+		if userID, err = p.keycloak.CreateUser(ctx, token.AccessToken, realmName, gocloak.User{
+			Username: &username,
+			Enabled:  &enabled,
+		}); err != nil {
+			logger.Error(err, "failed to create user", "username", username)
+			return err
+		}
+	} else {
+		userID = *user.ID
+	}
+
+	if err = p.keycloak.SetPassword(ctx, token.AccessToken, userID, realmName, password, false); err != nil {
+		logger.Error(err, "failed to set password", "username", username)
+		return err
+	}
+
+	return nil
+}
+
+func (p *keycloakProvider) RevokeUser(ctx context.Context, namespace, registry, username string) error {
+	token, err := p.login(ctx)
+	if err != nil {
+		logger.Error(err, "failed to login keycloak")
+		return err
+	}
+
+	user, err := p.findUser(ctx, token, namespace, username)
+	if err != nil {
+		logger.Error(err, "failed to get user", "username", username)
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+	if err = p.keycloak.DeleteUser(ctx, token.AccessToken, namespace, *user.ID); err != nil {
+		logger.Error(err, "failed to delete user", "username", username)
+		return err
+	}
+
+	return nil
+}
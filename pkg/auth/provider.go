@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = log.Log.WithName("auth-provider")
+
+const (
+	// ProviderKeycloak issues registry tokens by Keycloak's docker-v2 protocol
+	ProviderKeycloak = "keycloak"
+	// ProviderBuiltin issues registry tokens by the operator's token service
+	ProviderBuiltin = "builtin"
+)
+
+// Provider is a token authentication provider of registries
+type Provider interface {
+	// AuthConfig returns token auth config(realm, service and issuer) of the registry
+	AuthConfig(reg *regv1.Registry) *regv1.AuthConfig
+	// Prepare prepares everything to issue tokens for the registry, including the secret of token certificate bundle
+	Prepare(ctx context.Context, c client.Client, reg *regv1.Registry) error
+	// Cleanup removes what is prepared for the deleted registry
+	Cleanup(ctx context.Context, c client.Client, namespace, name string) error
+	// ProvisionUser creates the user of the registry or resets its password, if it already exists
+	ProvisionUser(ctx context.Context, reg *regv1.Registry, username, password string) error
+	// RevokeUser deletes the user of the registry, if it exists
+	RevokeUser(ctx context.Context, namespace, registry, username string) error
+}
+
+var (
+	provider     Provider
+	providerOnce sync.Once
+)
+
+// GetProvider returns the auth provider of token.provider config
+func GetProvider() Provider {
+	providerOnce.Do(func() {
+		name := config.Config.GetString(config.ConfigTokenServiceProvider)
+		switch name {
+		case ProviderBuiltin:
+			provider = &builtinProvider{}
+		default:
+			if name != ProviderKeycloak {
+				logger.Info("unknown auth provider, use keycloak", "provider", name)
+			}
+			provider = newKeycloakProvider()
+		}
+	})
+
+	return provider
+}
+
+// ValidateConfig checks token service config. The builtin provider requires the operator's server to be served over TLS,
+// since docker clients send passwords and kubernetes tokens to it by basic auth.
+func ValidateConfig() error {
+	if config.Config.GetString(config.ConfigTokenServiceProvider) != ProviderBuiltin {
+		return nil
+	}
+	if config.Config.GetString(config.ConfigServerTLSCertFile) == "" || config.Config.GetString(config.ConfigServerTLSKeyFile) == "" {
+		return fmt.Errorf("builtin token provider requires SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE")
+	}
+	if !strings.HasPrefix(config.Config.GetString(config.ConfigTokenServiceAddr), "https://") {
+		return fmt.Errorf("builtin token provider requires https token url")
+	}
+	return nil
+}
+
+// KeepsPreviousPassword returns true if the provider accepts the previous login password of a registry
+// during grace period after it is changed. Keycloak resets the password of the user immediately, so only builtin does.
+func KeepsPreviousPassword() bool {
+	return config.Config.GetString(config.ConfigTokenServiceProvider) == ProviderBuiltin
+}
+
+// tokenServiceURL returns token.url config joined with the path
+func tokenServiceURL(path string) string {
+	base := config.Config.GetString(config.ConfigTokenServiceAddr)
+	return fmt.Sprintf("%s/%s", strings.TrimRight(base, "/"), strings.TrimLeft(path, "/"))
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	keys := []string{config.ConfigTokenServiceProvider, config.ConfigTokenServiceAddr, config.ConfigServerTLSCertFile, config.ConfigServerTLSKeyFile}
+	for _, key := range keys {
+		defer config.Config.Set(key, config.Config.GetString(key))
+	}
+
+	type suite struct {
+		provider, url, cert, key string
+		expError                 bool
+	}
+	testCases := []suite{
+		{provider: ProviderKeycloak, url: "http://keycloak"},
+		{provider: ProviderBuiltin, url: "https://operator", cert: "tls.crt", key: "tls.key"},
+		// passwords are not sent to the token service over plain http
+		{provider: ProviderBuiltin, url: "https://operator", expError: true},
+		{provider: ProviderBuiltin, url: "http://operator", cert: "tls.crt", key: "tls.key", expError: true},
+	}
+	for _, c := range testCases {
+		config.Config.Set(config.ConfigTokenServiceProvider, c.provider)
+		config.Config.Set(config.ConfigTokenServiceAddr, c.url)
+		config.Config.Set(config.ConfigServerTLSCertFile, c.cert)
+		config.Config.Set(config.ConfigServerTLSKeyFile, c.key)
+		assert.Equal(t, c.expError, ValidateConfig() != nil)
+	}
+}
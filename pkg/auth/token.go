@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/registry/auth/token"
+	"github.com/docker/libtrust"
+	"github.com/tmax-cloud/registry-operator/internal/common/certs"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrUnauthorized is returned if credentials are invalid
+	ErrUnauthorized = errors.New("invalid username or password")
+	// ErrInvalidScope is returned if a requested scope is malformed
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// TokenRequest is a request for a registry token
+type TokenRequest struct {
+	// Namespace of the registry
+	Namespace string
+	// Service is the registry name
+	Service string
+	// Username and Password are basic auth credentials. Both are empty for anonymous requests.
+	Username string
+	Password string
+	// Scopes are requested scopes like repository:library/alpine:pull,push
+	Scopes []string
+}
+
+// Token is a response of the token service
+type Token struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IssuedAt    string `json:"issued_at"`
+}
+
+// TokenService issues registry tokens signed with the system root CA key
+type TokenService struct {
+	client client.Client
+}
+
+// NewTokenService returns a token service
+func NewTokenService(c client.Client) *TokenService {
+	return &TokenService{client: c}
+}
+
+// Issue authenticates the request and issues a token granting the requested access which the requester is allowed.
+// The registry's login user is allowed everything, and others are authorized by RepositoryPermissions and RBAC.
+func (s *TokenService) Issue(req *TokenRequest) (*Token, error) {
+	access := []*token.ResourceActions{}
+	for _, scope := range req.Scopes {
+		resource, err := ParseScope(scope)
+		if err != nil {
+			return nil, err
+		}
+		access = append(access, resource)
+	}
+
+	identity, err := s.authenticate(req)
+	if err != nil {
+		return nil, err
+	}
+	access, err = s.authorize(identity, req.Namespace, req.Service, access)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCA, err := certs.GetSystemRootCASecret(s.client)
+	if err != nil {
+		return nil, err
+	}
+	_, key, err := certs.ParseCA(rootCA)
+	if err != nil {
+		return nil, err
+	}
+
+	expiration := config.Config.GetDuration(config.ConfigTokenServiceExpiration)
+	if expiration <= 0 {
+		expiration = 5 * time.Minute
+	}
+	now := time.Now()
+	claims := &token.ClaimSet{
+		Issuer:     TokenRealm(req.Namespace),
+		Subject:    identity.Subject(),
+		Audience:   req.Service,
+		Expiration: now.Add(expiration).Unix(),
+		NotBefore:  now.Unix(),
+		IssuedAt:   now.Unix(),
+		JWTID:      utils.RandomString(16),
+		Access:     access,
+	}
+	signed, err := signToken(key, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		Token:       signed,
+		AccessToken: signed,
+		ExpiresIn:   int(expiration.Seconds()),
+		IssuedAt:    now.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ParseScope parses a scope of token request like repository:library/alpine:pull,push
+func ParseScope(scope string) (*token.ResourceActions, error) {
+	first, last := strings.Index(scope, ":"), strings.LastIndex(scope, ":")
+	if first < 0 || first == last {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+	}
+
+	resource := &token.ResourceActions{
+		Type: scope[:first],
+		Name: scope[first+1 : last],
+	}
+	// type may have a class like repository(plugin)
+	if i := strings.Index(resource.Type, "("); i > 0 && strings.HasSuffix(resource.Type, ")") {
+		resource.Class = resource.Type[i+1 : len(resource.Type)-1]
+		resource.Type = resource.Type[:i]
+	}
+	for _, action := range strings.Split(scope[last+1:], ",") {
+		if action != "" {
+			resource.Actions = append(resource.Actions, action)
+		}
+	}
+
+	return resource, nil
+}
+
+// signToken signs claims with RS256. Key id is libtrust's one so that the registry finds the key from its root cert bundle.
+func signToken(key *rsa.PrivateKey, claims *token.ClaimSet) (string, error) {
+	signingKey, err := libtrust.FromCryptoPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	header := &token.Header{
+		Type:       "JWT",
+		SigningAlg: "RS256",
+		KeyID:      signingKey.KeyID(),
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(headerJSON) + token.TokenSeparator + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature, _, err := signingKey.Sign(bytes.NewBufferString(payload), crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return payload + token.TokenSeparator + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/docker/distribution/registry/auth/token"
+	"github.com/docker/libtrust"
+)
+
+func TestParseScope(t *testing.T) {
+	resource, err := ParseScope("repository:library/alpine:pull,push")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &token.ResourceActions{Type: "repository", Name: "library/alpine", Actions: []string{"pull", "push"}}, resource)
+
+	resource, err = ParseScope("repository(plugin):sample/plugin:pull")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &token.ResourceActions{Type: "repository", Class: "plugin", Name: "sample/plugin", Actions: []string{"pull"}}, resource)
+
+	resource, err = ParseScope("registry:catalog:*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "catalog", resource.Name)
+
+	_, err = ParseScope("repository:alpine")
+	assert.NotEqual(t, nil, err)
+}
+
+// TestSignToken checks if the signed token is verified the same way as the registry does with its root cert bundle
+func TestSignToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "registry-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Equal(t, nil, err)
+	crt, err := x509.ParseCertificate(der)
+	assert.Equal(t, nil, err)
+
+	now := time.Now()
+	signed, err := signToken(key, &token.ClaimSet{
+		Issuer:     "https://auth.example.com/token/reg-test",
+		Subject:    "tmax",
+		Audience:   "tmax-registry",
+		Expiration: now.Add(time.Minute).Unix(),
+		NotBefore:  now.Unix(),
+		IssuedAt:   now.Unix(),
+		JWTID:      "test",
+		Access:     []*token.ResourceActions{{Type: "repository", Name: "alpine", Actions: []string{"pull"}}},
+	})
+	assert.Equal(t, nil, err)
+
+	pubKey, err := libtrust.FromCryptoPublicKey(crt.PublicKey)
+	assert.Equal(t, nil, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(crt)
+
+	parsed, err := token.NewToken(signed)
+	assert.Equal(t, nil, err)
+	err = parsed.Verify(token.VerifyOptions{
+		TrustedIssuers:    []string{"https://auth.example.com/token/reg-test"},
+		AcceptedAudiences: []string{"tmax-registry"},
+		Roots:             roots,
+		TrustedKeys:       map[string]libtrust.PublicKey{pubKey.KeyID(): pubKey},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tmax", parsed.Claims.Subject)
+}
@@ -0,0 +1,173 @@
+package image
+
+import (
+	"encoding/json"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/ocischema"
+	"github.com/docker/distribution/manifest/schema2"
+	contv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// detailsMediaTypes are media types of manifests accepted to get details, including manifest lists
+var detailsMediaTypes = []string{
+	manifestlist.MediaTypeManifestList,
+	contv1.MediaTypeImageIndex,
+	schema2.MediaTypeManifest,
+	contv1.MediaTypeImageManifest,
+}
+
+// unknownPlatform is the platform of manifests which are not images, e.g. attestations in manifest lists
+const unknownPlatform = "unknown"
+
+// ImageDetails is metadata of an image described by its manifest and config
+type ImageDetails struct {
+	Digest    string
+	MediaType string
+	// Size is the total compressed size of manifests, configs and layers. Blobs shared by platforms are counted once.
+	Size int64
+	// Blobs are sizes of the manifests, configs and layers which Size sums up, by their digests
+	Blobs map[string]int64
+	// Platforms are platforms of the image, or of the manifests in a manifest list
+	Platforms []Platform
+	// Labels are config labels of the image, or of the first image in a manifest list
+	Labels map[string]string
+}
+
+// Platform is an os and cpu architecture which an image runs on
+type Platform struct {
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// imageConfig is the part of docker and oci image config used for details
+type imageConfig struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+	Config       struct {
+		Labels map[string]string `json:"Labels,omitempty"`
+	} `json:"config"`
+}
+
+// GetDetails gets the manifest, and configs of the image's manifests, and returns details of the image
+func (r *Image) GetDetails() (*ImageDetails, error) {
+	tag, digest := r.Tag, r.Digest
+	defer func() {
+		r.Tag, r.Digest = tag, digest
+	}()
+
+	mf, err := r.fetchManifest(detailsMediaTypes...)
+	if err != nil {
+		Logger.Error(err, "failed to get manifest")
+		return nil, err
+	}
+
+	details := &ImageDetails{
+		Digest:    mf.Digest,
+		MediaType: mf.MediaType,
+		Size:      mf.ContentLength,
+		Blobs:     map[string]int64{mf.Digest: mf.ContentLength},
+	}
+
+	list, isList := mf.Manifest.(*manifestlist.DeserializedManifestList)
+	if !isList {
+		config, err := r.imageConfig(mf, details)
+		if err != nil {
+			return nil, err
+		}
+		if config != nil {
+			details.Platforms = []Platform{{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}}
+			details.Labels = config.Config.Labels
+		}
+		return details, nil
+	}
+
+	for _, desc := range list.Manifests {
+		r.Tag, r.Digest = "", desc.Digest.String()
+		child, err := r.fetchManifest(detailsMediaTypes...)
+		if err != nil {
+			Logger.Error(err, "failed to get manifest", "digest", desc.Digest.String())
+			return nil, err
+		}
+		details.addBlob(child.Digest, child.ContentLength)
+
+		config, err := r.imageConfig(child, details)
+		if err != nil {
+			return nil, err
+		}
+		if desc.Platform.OS == unknownPlatform {
+			continue
+		}
+		details.Platforms = append(details.Platforms, Platform{
+			OS:           desc.Platform.OS,
+			Architecture: desc.Platform.Architecture,
+			Variant:      desc.Platform.Variant,
+		})
+		if details.Labels == nil && config != nil {
+			details.Labels = config.Config.Labels
+		}
+	}
+
+	return details, nil
+}
+
+// TotalSize returns the size of unique manifests, configs and layers of the images.
+// Blobs shared by the images are counted once.
+func TotalSize(images ...*ImageDetails) int64 {
+	blobs := map[string]bool{}
+	var size int64
+	for _, details := range images {
+		for digest, s := range details.Blobs {
+			if !blobs[digest] {
+				blobs[digest] = true
+				size += s
+			}
+		}
+	}
+	return size
+}
+
+func (d *ImageDetails) addBlob(digest string, size int64) {
+	if _, ok := d.Blobs[digest]; ok {
+		return
+	}
+	d.Blobs[digest] = size
+	d.Size += size
+}
+
+// imageConfig adds sizes of blobs referenced by the image manifest to details, and pulls its config.
+// It returns nil config for manifests without config, e.g. schema1 manifests.
+func (r *Image) imageConfig(mf *ImageManifest, details *ImageDetails) (*imageConfig, error) {
+	for _, ref := range mf.Manifest.References() {
+		details.addBlob(ref.Digest.String(), ref.Size)
+	}
+
+	var configDesc distribution.Descriptor
+	switch m := mf.Manifest.(type) {
+	case *schema2.DeserializedManifest:
+		configDesc = m.Config
+	case *ocischema.DeserializedManifest:
+		configDesc = m.Config
+	default:
+		return nil, nil
+	}
+
+	r.Tag, r.Digest = "", configDesc.Digest.String()
+	blob, _, err := r.PullBlob()
+	if err != nil {
+		Logger.Error(err, "failed to pull config", "digest", configDesc.Digest.String())
+		return nil, err
+	}
+	defer blob.Close()
+
+	config := &imageConfig{}
+	if err := json.NewDecoder(blob).Decode(config); err != nil {
+		Logger.Error(err, "failed to decode config", "digest", configDesc.Digest.String())
+		return nil, err
+	}
+
+	return config, nil
+}
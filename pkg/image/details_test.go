@@ -0,0 +1,95 @@
+package image
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestGetDetails(t *testing.T) {
+	// digest => (media type, content)
+	contents := map[string][2]string{}
+	add := func(mediaType string, v interface{}) (string, int) {
+		b, _ := json.Marshal(v)
+		d := digest.FromBytes(b).String()
+		contents[d] = [2]string{mediaType, string(b)}
+		return d, len(b)
+	}
+	desc := func(mediaType, d string, size int) map[string]interface{} {
+		return map[string]interface{}{"mediaType": mediaType, "digest": d, "size": size}
+	}
+	image := func(arch string, labels map[string]string, layer string) map[string]interface{} {
+		configDigest, configSize := add("application/octet-stream", map[string]interface{}{
+			"os": "linux", "architecture": arch, "config": map[string]interface{}{"Labels": labels},
+		})
+		return map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     schema2.MediaTypeManifest,
+			"config":        desc(schema2.MediaTypeImageConfig, configDigest, configSize),
+			"layers":        []interface{}{desc(schema2.MediaTypeLayer, layer, 100)},
+		}
+	}
+	shared := digest.FromString("shared layer").String()
+	amdDigest, amdSize := add(schema2.MediaTypeManifest, image("amd64", map[string]string{"org.opencontainers.image.version": "1.0"}, shared))
+	armDigest, armSize := add(schema2.MediaTypeManifest, image("arm64", nil, shared))
+	listDigest, listSize := add(manifestlist.MediaTypeManifestList, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     manifestlist.MediaTypeManifestList,
+		"manifests": []interface{}{
+			map[string]interface{}{"mediaType": schema2.MediaTypeManifest, "digest": amdDigest, "size": amdSize, "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+			map[string]interface{}{"mediaType": schema2.MediaTypeManifest, "digest": armDigest, "size": armSize, "platform": map[string]string{"os": "linux", "architecture": "arm64", "variant": "v8"}},
+		},
+	})
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ref := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if ref == "v2" {
+			return
+		}
+		if ref == "1.0" {
+			ref = listDigest
+		}
+		content, ok := contents[ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", content[0])
+		w.Header().Set("Docker-Content-Digest", ref)
+		w.Header().Set("Content-Length", strconv.Itoa(len(content[1])))
+		w.Write([]byte(content[1]))
+	}))
+	defer server.Close()
+
+	img, err := NewImage("", server.URL, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, nil, img.SetImage("library/app:1.0"))
+
+	details, err := img.GetDetails()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, listDigest, details.Digest)
+	assert.Equal(t, manifestlist.MediaTypeManifestList, details.MediaType)
+	configs := int64(0)
+	for _, c := range contents {
+		if c[0] == "application/octet-stream" {
+			configs += int64(len(c[1]))
+		}
+	}
+	assert.Equal(t, int64(listSize+amdSize+armSize)+configs+100, details.Size)
+	assert.Equal(t, details.Size, TotalSize(details, details))
+	assert.Equal(t, []Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64", Variant: "v8"}}, details.Platforms)
+	assert.Equal(t, map[string]string{"org.opencontainers.image.version": "1.0"}, details.Labels)
+	assert.Equal(t, "1.0", img.Tag)
+}
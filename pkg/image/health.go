@@ -0,0 +1,46 @@
+package image
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/tmax-cloud/registry-operator/internal/common/auth"
+)
+
+// Ping checks the registry serves /v2/ api.
+// Unauthorized response also means the api is reachable, because it is the challenge to get a token.
+func (r *Image) Ping() error {
+	u, err := pingURL(r.ServerURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := r.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("%s responded with %s", u.String(), res.Status)
+	}
+	return nil
+}
+
+// GetCatalogToken gets a token to list repositories of the registry
+func (r *Image) GetCatalogToken() (auth.Token, error) {
+	return r.GetToken(catalogScope())
+}
+
+// PushTestBlob uploads a small blob to the repository of the image to check the storage is writable
+func (r *Image) PushTestBlob() error {
+	blob := []byte("registry-operator health probe")
+	r.Digest = digest.FromBytes(blob).String()
+	_, _, err := r.PushBlob(blob, int64(len(blob)))
+	return err
+}
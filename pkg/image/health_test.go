@@ -0,0 +1,31 @@
+package image
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestPing(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	image, err := NewImage("", server.URL, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, nil, image.Ping())
+
+	status = http.StatusOK
+	assert.Equal(t, nil, image.Ping())
+
+	status = http.StatusServiceUnavailable
+	assert.NotEqual(t, nil, image.Ping())
+}
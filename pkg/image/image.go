@@ -34,6 +34,9 @@ const (
 	LegacyV1Server = "https://index.docker.io/v1"
 	// LegacyV2Server is FQDN of legacy v2 server
 	LegacyV2Server = "https://index.docker.io/v2"
+
+	// UserAgent is the user agent of requests of the operator, used to tell them from users' requests in registry events
+	UserAgent = "registry-operator"
 )
 
 type Image struct {
@@ -115,14 +118,27 @@ func NewImage(uri, registryServer, basicAuth string, ca []byte) (*Image, error)
 		}
 	}
 	r.HttpClient = http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: tlsConfig,
+		Transport: &userAgentTransport{
+			base: &http.Transport{
+				TLSClientConfig: tlsConfig,
+			},
 		},
 	}
 
 	return r, nil
 }
 
+// userAgentTransport sets the operator's user agent to requests
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", UserAgent)
+	return t.base.RoundTrip(req)
+}
+
 // SetServerURL sets registry server URL
 func (r *Image) SetServerURL(url string) {
 	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
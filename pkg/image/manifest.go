@@ -14,11 +14,20 @@ import (
 
 type ImageManifest struct {
 	Digest        string
+	MediaType     string
 	ContentLength int64
 	Manifest      distribution.Manifest
 }
 
 func (r *Image) manifest(schemaVersion int) (*ImageManifest, error) {
+	if schemaVersion == 2 {
+		return r.fetchManifest(schema2.MediaTypeManifest)
+	}
+	return r.fetchManifest()
+}
+
+// fetchManifest gets the manifest of the image accepting the media types
+func (r *Image) fetchManifest(accepts ...string) (*ImageManifest, error) {
 	ref := r.Tag
 	if ref == "" {
 		ref = r.Digest
@@ -41,8 +50,8 @@ func (r *Image) manifest(schemaVersion int) (*ImageManifest, error) {
 		return nil, err
 	}
 
-	if schemaVersion == 2 {
-		req.Header.Set("Accept", schema2.MediaTypeManifest)
+	for _, accept := range accepts {
+		req.Header.Add("Accept", accept)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("%s %s", token.Type, token.Value))
 
@@ -83,6 +92,7 @@ func (r *Image) manifest(schemaVersion int) (*ImageManifest, error) {
 
 	return &ImageManifest{
 		Digest:        digest,
+		MediaType:     mediaType,
 		ContentLength: int64(length),
 		Manifest:      manifest,
 	}, nil
@@ -145,6 +145,8 @@ func SyncRegistry(c client.Client, reg *regv1.Registry, scheme *runtime.Scheme)
 		ca = append(ca, kca...)
 	}
 
+	ca = append(ca, certs.ExternalCAData(c, reg.Spec.TLS, types.NamespacedName{Name: schemes.RegistryTLSSecretName(reg), Namespace: reg.Namespace})...)
+
 	syncFactory := factory.NewRegistryFactory(
 		c,
 		types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace},
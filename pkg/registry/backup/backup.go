@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+)
+
+// Backuper backs up images of a registry into a store
+type Backuper struct {
+	src      Source
+	host     string
+	store    Store
+	name     string
+	index    *Index
+	progress regv1.BackupProgress
+	notify   func(regv1.BackupProgress)
+}
+
+// NewBackuper returns a Backuper. Backup is stored under the name in the store.
+// host is registry server's host to make image references and notify is called whenever progress is made.
+func NewBackuper(src Source, host string, store Store, name string, notify func(regv1.BackupProgress)) *Backuper {
+	return &Backuper{
+		src:   src,
+		host:  host,
+		store: store,
+		name:  name,
+		index: &Index{
+			CreatedAt: time.Now(),
+			Manifests: map[string]Manifest{},
+			Blobs:     map[string]int64{},
+			Objects:   map[string]string{},
+		},
+		notify: notify,
+	}
+}
+
+// Backup backs up all tagged images and kubernetes objects which are {key: object}.
+// It returns the index of the backup and its checksum.
+func (b *Backuper) Backup(reg *regv1.Registry, objects map[string]interface{}) (*Index, string, error) {
+	b.index.Registry = reg.Name
+	b.index.Namespace = reg.Namespace
+	b.index.Host = b.host
+
+	repos := b.src.ListRepositories()
+	if repos == nil {
+		return nil, "", errors.New("failed to get repository list")
+	}
+
+	images := []Image{}
+	for _, repo := range repos.Repositories {
+		tags := b.src.ListTags(repo)
+		if tags == nil {
+			return nil, "", fmt.Errorf("failed to get tag list of %s", repo)
+		}
+		for _, tag := range tags.Tags {
+			images = append(images, Image{Repository: repo, Tag: tag})
+		}
+	}
+	b.progress.TotalImages = int32(len(images))
+	b.notify(b.progress)
+
+	for _, img := range images {
+		digest, err := b.backupManifest(fmt.Sprintf("%s/%s:%s", b.host, img.Repository, img.Tag))
+		if err != nil {
+			return nil, "", err
+		}
+		img.Digest = digest
+		b.index.Images = append(b.index.Images, img)
+
+		b.progress.CompletedImages++
+		b.notify(b.progress)
+	}
+
+	for key, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, "", err
+		}
+		sum, err := putBytes(b.store, objectKey(b.name, key), data)
+		if err != nil {
+			return nil, "", err
+		}
+		b.index.Objects[key] = sum
+	}
+
+	data, err := json.Marshal(b.index)
+	if err != nil {
+		return nil, "", err
+	}
+	sum, err := putBytes(b.store, path.Join(b.name, indexKey), data)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := putBytes(b.store, path.Join(b.name, indexChecksumKey), []byte(sum)); err != nil {
+		return nil, "", err
+	}
+
+	return b.index, sum, nil
+}
+
+// backupManifest backs up the manifest of the image and everything it references, and returns its digest
+func (b *Backuper) backupManifest(image string) (string, error) {
+	named, err := reference.ParseNamed(image)
+	if err != nil {
+		return "", err
+	}
+
+	mf, err := b.src.GetManifest(image)
+	if err != nil {
+		logger.Error(err, "failed to get manifest", "image", image)
+		return "", err
+	}
+	if _, ok := b.index.Manifests[mf.Digest]; ok {
+		return mf.Digest, nil
+	}
+
+	mediaType, payload, err := mf.Manifest.Payload()
+	if err != nil {
+		return "", err
+	}
+
+	entry := Manifest{MediaType: mediaType}
+	for _, descriptor := range mf.Manifest.References() {
+		digest := descriptor.Digest.String()
+		switch {
+		case isManifestMediaType(descriptor.MediaType):
+			if _, err := b.backupManifest(named.Name() + "@" + digest); err != nil {
+				return "", err
+			}
+			entry.Manifests = append(entry.Manifests, digest)
+		case isForeignMediaType(descriptor.MediaType):
+			// foreign layers are not stored in registry, but pulled from their urls
+		default:
+			// configs and layers of any media type, e.g. helm charts or signatures
+			if err := b.backupBlob(named.Name(), digest); err != nil {
+				return "", err
+			}
+			entry.Blobs = append(entry.Blobs, digest)
+		}
+	}
+
+	file, err := tempFile()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file)
+	if err := ioutil.WriteFile(file, payload, 0644); err != nil {
+		return "", err
+	}
+
+	if !isSchema1(mediaType) {
+		if err := verify(file, mf.Digest); err != nil {
+			return "", fmt.Errorf("%s: %s", image, err.Error())
+		}
+	}
+	if err := b.store.Put(manifestKey(b.name, mf.Digest), file); err != nil {
+		return "", err
+	}
+	b.index.Manifests[mf.Digest] = entry
+
+	return mf.Digest, nil
+}
+
+func (b *Backuper) backupBlob(repository, digest string) error {
+	if _, ok := b.index.Blobs[digest]; ok {
+		return nil
+	}
+
+	file, size, err := b.src.PullBlob(repository, digest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(file); err != nil {
+			logger.Error(err, "failed to remove file", "file", file)
+		}
+	}()
+
+	if err := verify(file, digest); err != nil {
+		return fmt.Errorf("%s@%s: %s", repository, digest, err.Error())
+	}
+	if err := b.store.Put(blobKey(b.name, digest), file); err != nil {
+		return err
+	}
+	b.index.Blobs[digest] = size
+
+	b.progress.Blobs++
+	b.progress.Bytes += size
+	b.notify(b.progress)
+
+	return nil
+}
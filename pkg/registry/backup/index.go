@@ -0,0 +1,207 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	contv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/base"
+)
+
+const (
+	indexKey         = "index.json"
+	indexChecksumKey = "index.json.sha256"
+)
+
+// Source is a registry which can be backed up or restored
+type Source interface {
+	base.Readable
+	base.Replicatable
+}
+
+// Index describes all contents of a backup.
+// Manifests and blobs are stored by their digests, so that their checksums are the digests themselves.
+type Index struct {
+	// Registry is the name of backed up registry
+	Registry string `json:"registry"`
+	// Namespace is the namespace of backed up registry
+	Namespace string `json:"namespace"`
+	// Host is the host of backed up registry server, which signer keys' targets are prefixed with
+	Host string `json:"host"`
+	// CreatedAt is the time when the backup is created
+	CreatedAt time.Time `json:"createdAt"`
+	// Images are tagged images
+	Images []Image `json:"images"`
+	// Manifests are {digest: manifest}
+	Manifests map[string]Manifest `json:"manifests"`
+	// Blobs are {digest: size}
+	Blobs map[string]int64 `json:"blobs"`
+	// Objects are {key: sha256 checksum} of kubernetes objects
+	Objects map[string]string `json:"objects"`
+}
+
+// Image is a tagged image
+type Image struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+}
+
+// Manifest is a manifest and digests which it references
+type Manifest struct {
+	MediaType string `json:"mediaType"`
+	// Manifests are digests of child manifests if it is a manifest list
+	Manifests []string `json:"manifests,omitempty"`
+	// Blobs are digests of config and layers
+	Blobs []string `json:"blobs,omitempty"`
+}
+
+// Repositories returns names of backed up repositories
+func (i *Index) Repositories() []string {
+	repos := []string{}
+	found := map[string]bool{}
+	for _, img := range i.Images {
+		if !found[img.Repository] {
+			found[img.Repository] = true
+			repos = append(repos, img.Repository)
+		}
+	}
+
+	return repos
+}
+
+func manifestKey(name, digest string) string {
+	return path.Join(name, "manifests", strings.Replace(digest, ":", "/", 1))
+}
+
+func blobKey(name, digest string) string {
+	return path.Join(name, "blobs", strings.Replace(digest, ":", "/", 1))
+}
+
+func objectKey(name, key string) string {
+	return path.Join(name, "objects", key+".json")
+}
+
+func isManifestMediaType(mediaType string) bool {
+	switch mediaType {
+	case contv1.MediaTypeImageIndex, manifestlist.MediaTypeManifestList, contv1.MediaTypeImageManifest, schema2.MediaTypeManifest,
+		schema1.MediaTypeSignedManifest, schema1.MediaTypeManifest:
+		return true
+	}
+	return false
+}
+
+func isForeignMediaType(mediaType string) bool {
+	switch mediaType {
+	case schema2.MediaTypeForeignLayer, contv1.MediaTypeImageLayerNonDistributable, contv1.MediaTypeImageLayerNonDistributableGzip:
+		return true
+	}
+	return false
+}
+
+// isSchema1 returns true if the manifest is schema1, whose digest is calculated without signatures
+func isSchema1(mediaType string) bool {
+	return mediaType == schema1.MediaTypeSignedManifest || mediaType == schema1.MediaTypeManifest
+}
+
+// verify checks if sha256 checksum of the file is the expected one.
+// expected can be either a digest like "sha256:abcd" or a hex encoded checksum.
+func verify(file, expected string) error {
+	if strings.Contains(expected, ":") {
+		if !strings.HasPrefix(expected, "sha256:") {
+			// only sha256 is supported by registry
+			return nil
+		}
+		expected = strings.TrimPrefix(expected, "sha256:")
+	}
+
+	sum, err := checksum(file)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, sum)
+	}
+
+	return nil
+}
+
+// putBytes stores data to the key and returns its sha256 checksum
+func putBytes(store Store, key string, data []byte) (string, error) {
+	file, err := tempFile()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file)
+
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return "", err
+	}
+	sum, err := checksum(file)
+	if err != nil {
+		return "", err
+	}
+
+	return sum, store.Put(key, file)
+}
+
+// getBytes fetches data of the key. If expected checksum is not empty, it is verified.
+func getBytes(store Store, key, expected string) ([]byte, error) {
+	file, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file)
+
+	if expected != "" {
+		if err := verify(file, expected); err != nil {
+			return nil, fmt.Errorf("%s: %s", key, err.Error())
+		}
+	}
+
+	return ioutil.ReadFile(file)
+}
+
+// ReadIndex reads the index of the backup and verifies its checksum. It returns the index and its checksum.
+func ReadIndex(store Store, name string) (*Index, string, error) {
+	sum, err := getBytes(store, path.Join(name, indexChecksumKey), "")
+	if err != nil {
+		return nil, "", err
+	}
+	checksum := strings.TrimSpace(string(sum))
+
+	data, err := getBytes(store, path.Join(name, indexKey), checksum)
+	if err != nil {
+		return nil, "", err
+	}
+
+	index := &Index{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, "", err
+	}
+
+	return index, checksum, nil
+}
+
+// ReadObject reads the kubernetes object stored with the key and verifies its checksum
+func ReadObject(store Store, name string, index *Index, key string, obj interface{}) error {
+	sum, ok := index.Objects[key]
+	if !ok {
+		return fmt.Errorf("%s is not found in backup %s", key, name)
+	}
+
+	data, err := getBytes(store, objectKey(name, key), sum)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, obj)
+}
@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
+	k8sCmd "github.com/tmax-cloud/registry-operator/internal/common/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	agentContainerName = "agent"
+	agentMountPath     = "/backup"
+	agentStartTimeout  = 3 * time.Minute
+)
+
+// pvcStore stores backup in a pvc. The pvc is mounted to an agent pod and contents are streamed through pod exec.
+type pvcStore struct {
+	c    client.Client
+	pod  *corev1.Pod
+	root string
+}
+
+func newPVCStore(c client.Client, kind string, owner metav1.Object, pvc *regv1.BackupPVC) (*pvcStore, error) {
+	pod := agentPod(kind, owner, pvc.ClaimName)
+	if err := c.Create(context.TODO(), pod); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	logger.Info("wait for backup agent pod", "namespace", pod.Namespace, "name", pod.Name)
+	if err := wait.PollImmediate(2*time.Second, agentStartTimeout, func() (bool, error) {
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod); err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("backup agent pod %s is terminated", pod.Name)
+		}
+		return pod.Status.Phase == corev1.PodRunning, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &pvcStore{
+		c:    c,
+		pod:  pod,
+		root: path.Join(agentMountPath, pvc.Path),
+	}, nil
+}
+
+// agentPod is named by the kind and uid of the owner, so that a backup and a restore of the same name do not share it
+func agentPod(kind string, owner metav1.Object, claimName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%sbackup-agent-%s-%s", regv1.K8sPrefix, strings.ToLower(kind), owner.GetUID()),
+			Namespace: owner.GetNamespace(),
+			Labels: map[string]string{
+				"app": "registry-backup-agent",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    agentContainerName,
+					Image:   config.Config.GetString(config.ConfigRegistryImage),
+					Command: []string{"sh", "-c", "tail -f /dev/null"},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "backup",
+							MountPath: agentMountPath,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "backup",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *pvcStore) Put(key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dst := path.Join(s.root, key)
+	stderr := &bytes.Buffer{}
+	script := `mkdir -p "$1" && cat > "$2"`
+	if err := k8sCmd.ExecStreamArgs(s.pod.Name, agentContainerName, s.pod.Namespace, script, []string{path.Dir(dst), dst}, f, &bytes.Buffer{}, stderr); err != nil {
+		return fmt.Errorf("failed to put %s: %s %s", key, err.Error(), stderr.String())
+	}
+
+	return nil
+}
+
+func (s *pvcStore) Get(key string) (string, error) {
+	file, err := tempFile()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	stderr := &bytes.Buffer{}
+	script := `cat "$1"`
+	if err := k8sCmd.ExecStreamArgs(s.pod.Name, agentContainerName, s.pod.Namespace, script, []string{path.Join(s.root, key)}, nil, f, stderr); err != nil {
+		os.Remove(file)
+		return "", fmt.Errorf("failed to get %s: %s %s", key, err.Error(), stderr.String())
+	}
+
+	return file, nil
+}
+
+// Close deletes the agent pod
+func (s *pvcStore) Close() error {
+	if err := s.c.Delete(context.TODO(), s.pod); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/distribution"
+	_ "github.com/docker/distribution/manifest/ocischema"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+)
+
+// Restorer restores images from a backup into a registry
+type Restorer struct {
+	dst      Source
+	host     string
+	store    Store
+	name     string
+	index    *Index
+	restored map[string]bool
+	progress regv1.BackupProgress
+	verified int32
+	notify   func(regv1.BackupProgress, int32)
+}
+
+// NewRestorer returns a Restorer of the backup whose index is already read.
+// notify is called with progress and the number of verified contents whenever progress is made.
+func NewRestorer(dst Source, host string, store Store, name string, index *Index, notify func(regv1.BackupProgress, int32)) *Restorer {
+	return &Restorer{
+		dst:      dst,
+		host:     host,
+		store:    store,
+		name:     name,
+		index:    index,
+		restored: map[string]bool{},
+		notify:   notify,
+	}
+}
+
+// Restore pushes all backed up images into the registry
+func (r *Restorer) Restore() error {
+	r.progress.TotalImages = int32(len(r.index.Images))
+	r.notify(r.progress, r.verified)
+
+	for _, img := range r.index.Images {
+		repository := fmt.Sprintf("%s/%s", r.host, img.Repository)
+		if err := r.restoreManifest(repository, img.Digest, repository+":"+img.Tag); err != nil {
+			return err
+		}
+
+		r.progress.CompletedImages++
+		r.notify(r.progress, r.verified)
+	}
+
+	return nil
+}
+
+// restoreManifest pushes everything the manifest references and then pushes the manifest with the reference
+func (r *Restorer) restoreManifest(repository, digest, ref string) error {
+	entry, ok := r.index.Manifests[digest]
+	if !ok {
+		return fmt.Errorf("manifest %s is not found in backup %s", digest, r.name)
+	}
+
+	for _, child := range entry.Manifests {
+		if r.restored[repository+"@"+child] {
+			continue
+		}
+		if err := r.restoreManifest(repository, child, repository+"@"+child); err != nil {
+			return err
+		}
+		r.restored[repository+"@"+child] = true
+	}
+	for _, blob := range entry.Blobs {
+		if err := r.restoreBlob(repository, blob); err != nil {
+			return err
+		}
+	}
+
+	payload, err := getBytes(r.store, manifestKey(r.name, digest), "")
+	if err != nil {
+		return err
+	}
+	manifest, desc, err := distribution.UnmarshalManifest(entry.MediaType, payload)
+	if err != nil {
+		return err
+	}
+	if desc.Digest.String() != digest && !isSchema1(entry.MediaType) {
+		return fmt.Errorf("manifest %s: checksum mismatch: got %s", digest, desc.Digest.String())
+	}
+	r.verified++
+
+	return r.dst.PutManifest(ref, &image.ImageManifest{
+		Digest:        digest,
+		ContentLength: desc.Size,
+		Manifest:      manifest,
+	})
+}
+
+func (r *Restorer) restoreBlob(repository, digest string) error {
+	if r.restored[repository+"@"+digest] {
+		return nil
+	}
+
+	exist, err := r.dst.ExistBlob(repository, digest)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		file, err := r.store.Get(blobKey(r.name, digest))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.Remove(file); err != nil {
+				logger.Error(err, "failed to remove file", "file", file)
+			}
+		}()
+
+		if err := verify(file, digest); err != nil {
+			return fmt.Errorf("blob %s: %s", digest, err.Error())
+		}
+		r.verified++
+
+		size := r.index.Blobs[digest]
+		if err := r.dst.PushBlob(repository, digest, file, size); err != nil {
+			return err
+		}
+		r.progress.Blobs++
+		r.progress.Bytes += size
+		r.notify(r.progress, r.verified)
+	}
+	r.restored[repository+"@"+digest] = true
+
+	return nil
+}
@@ -0,0 +1,456 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+)
+
+const (
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	s3DateFormat  = "20060102T150405Z"
+	s3ShortFormat = "20060102"
+
+	// s3MaxPutSize is the maximum size of an object uploaded by a single PUT
+	s3MaxPutSize = 5 << 30
+	// s3PartSize is the size of each part of multipart upload, which grows if the object needs more than s3MaxParts
+	s3PartSize = 64 << 20
+	// s3MaxParts is the maximum number of parts of multipart upload
+	s3MaxParts = 10000
+)
+
+// s3Store stores backup in S3-compatible bucket. Requests are signed with AWS signature version 4.
+type s3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	prefix    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+
+	// maxPutSize is the size over which objects are uploaded in parts
+	maxPutSize int64
+	partSize   int64
+}
+
+func newS3Store(s3 *regv1.S3Storage, accessKey, secretKey string) (*s3Store, error) {
+	region := s3.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := s3.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		scheme := "https"
+		if s3.Insecure {
+			scheme = "http"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Store{
+		endpoint:  u,
+		bucket:    s3.Bucket,
+		region:    region,
+		prefix:    strings.Trim(s3.RootDirectory, "/"),
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: s3.SkipVerify},
+			},
+		},
+		maxPutSize: s3MaxPutSize,
+		partSize:   s3PartSize,
+	}, nil
+}
+
+// objectURL returns path-style url of the object
+func (s *s3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = "/" + path.Join(s.bucket, s.prefix, key)
+	return &u
+}
+
+// objectQueryURL returns url of the object with the query, such as upload id of multipart upload
+func (s *s3Store) objectQueryURL(key string, query url.Values) *url.URL {
+	u := s.objectURL(key)
+	u.RawQuery = query.Encode()
+	return u
+}
+
+// do signs and sends the request, and returns the response if its status is 200 OK
+func (s *s3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s", resp.Status, string(body))
+	}
+
+	return resp, nil
+}
+
+func (s *s3Store) Put(key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > s.maxPutSize {
+		if err := s.putMultipart(key, f, info.Size()); err != nil {
+			return fmt.Errorf("failed to put %s: %s", key, err.Error())
+		}
+		return nil
+	}
+
+	sum, err := checksum(file)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key).String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := s.do(req, sum)
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %s", key, err.Error())
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+type s3InitiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletedPart `xml:"Part"`
+}
+
+// s3Error is an error S3 may respond with 200 OK while completing multipart upload
+type s3Error struct {
+	XMLName xml.Name
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// putMultipart uploads the file in parts, since an object larger than s3MaxPutSize cannot be uploaded by a single PUT.
+// The upload is aborted if it fails, so that the bucket does not keep the parts uploaded.
+func (s *s3Store) putMultipart(key string, f *os.File, size int64) error {
+	partSize := s.partSize
+	if min := (size + s3MaxParts - 1) / s3MaxParts; partSize < min {
+		partSize = min
+	}
+
+	uploadID, err := s.createMultipartUpload(key)
+	if err != nil {
+		return err
+	}
+
+	parts := []s3CompletedPart{}
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		n := partSize
+		if size-offset < n {
+			n = size - offset
+		}
+		etag, err := s.uploadPart(key, uploadID, number, io.NewSectionReader(f, offset, n))
+		if err != nil {
+			s.abortMultipartUpload(key, uploadID)
+			return err
+		}
+		parts = append(parts, s3CompletedPart{PartNumber: number, ETag: etag})
+	}
+
+	if err := s.completeMultipartUpload(key, uploadID, parts); err != nil {
+		s.abortMultipartUpload(key, uploadID)
+		return err
+	}
+
+	return nil
+}
+
+func (s *s3Store) createMultipartUpload(key string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, s.objectQueryURL(key, url.Values{"uploads": {""}}).String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	result := &s3InitiateMultipartUploadResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+		return "", err
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("upload id is not given")
+	}
+
+	return result.UploadID, nil
+}
+
+// uploadPart uploads a part and returns its etag
+func (s *s3Store) uploadPart(key, uploadID string, number int, part *io.SectionReader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, part); err != nil {
+		return "", err
+	}
+	if _, err := part.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	req, err := http.NewRequest(http.MethodPut, s.objectQueryURL(key, query).String(), part)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = part.Size()
+
+	resp, err := s.do(req, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("ETag"), nil
+}
+
+func (s *s3Store) completeMultipartUpload(key, uploadID string, parts []s3CompletedPart) error {
+	body, err := xml.Marshal(&s3CompleteMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.objectQueryURL(key, url.Values{"uploadId": {uploadID}}).String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	resp, err := s.do(req, hexSHA256(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	result := &s3Error{}
+	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+		return err
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("%s: %s", result.Code, result.Message)
+	}
+
+	return nil
+}
+
+// abortMultipartUpload removes parts uploaded. Failure is ignored, since it is called while handling another error.
+func (s *s3Store) abortMultipartUpload(key, uploadID string) {
+	req, err := http.NewRequest(http.MethodDelete, s.objectQueryURL(key, url.Values{"uploadId": {uploadID}}).String(), nil)
+	if err != nil {
+		return
+	}
+
+	s.sign(req, emptyPayloadHash, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func (s *s3Store) Get(key string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return "", err
+	}
+	s.sign(req, emptyPayloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to get %s: %s %s", key, resp.Status, string(body))
+	}
+
+	file, err := tempFile()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		os.Remove(file)
+		return "", err
+	}
+
+	return file, nil
+}
+
+func (s *s3Store) Close() error {
+	return nil
+}
+
+// emptyPayloadHash is sha256 of empty payload
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign sets authorization header of AWS signature version 4
+func (s *s3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(s3DateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// canonical headers
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(s3ShortFormat), s.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3DateFormat),
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(s3ShortFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := []string{}
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes every byte except unreserved characters of RFC 3986
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/base"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = log.Log.WithName("registry-backup")
+
+// Store is a storage where registry backup is stored
+type Store interface {
+	// Put stores the local file to the key
+	Put(key, file string) error
+	// Get fetches content of the key into a temporal local file and returns its path
+	Get(key string) (string, error)
+	// Close releases resources used by the store
+	Close() error
+}
+
+// NewStore returns a store of the backup storage.
+// kind and owner are used to name resources created for the store like pvc agent pod.
+func NewStore(c client.Client, kind string, owner metav1.Object, storage *regv1.BackupStorage) (Store, error) {
+	namespace := owner.GetNamespace()
+	switch {
+	case storage.S3 != nil:
+		secret := &corev1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: storage.S3.CredentialSecret, Namespace: namespace}, secret); err != nil {
+			return nil, err
+		}
+		return newS3Store(storage.S3, string(secret.Data[regv1.S3AccessKey]), string(secret.Data[regv1.S3SecretKey]))
+	case storage.PVC != nil:
+		return newPVCStore(c, kind, owner, storage.PVC)
+	}
+
+	return nil, errors.New("either pvc or s3 must be set to backup storage")
+}
+
+// tempFile returns a new temporal file path to download content
+func tempFile() (string, error) {
+	f, err := ioutil.TempFile(base.TempBlobsDir, "backup-")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return f.Name(), nil
+}
+
+// checksum returns hex encoded sha256 checksum of the file
+func checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -118,7 +118,7 @@ func GetCertSecret(client client.Client, registry types.NamespacedName, registry
 		if err := client.Get(context.TODO(), registry, reg); err != nil {
 			return "", err
 		}
-		return schemes.SubresourceName(reg, schemes.SubTypeRegistryTLSSecret), nil
+		return schemes.RegistryTLSSecretName(reg), nil
 
 	case regv1.RegistryTypeDockerHub, regv1.RegistryTypeDocker, regv1.RegistryTypeHarborV2:
 		exreg := &regv1.ExternalRegistry{}
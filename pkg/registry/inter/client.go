@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/internal/common/certs"
@@ -23,6 +24,9 @@ import (
 
 var Logger = log.Log.WithName("inter-registry")
 
+// ProbeRepository is the repository which health probe uploads a test blob to. It is hidden from repository list.
+const ProbeRepository = "registry-operator/health-probe"
+
 type Client struct {
 	Name, Namespace string
 
@@ -70,6 +74,8 @@ func GetClient(c client.Client, reg *regv1.Registry, scheme *runtime.Scheme) (*C
 		ca = append(ca, kca...)
 	}
 
+	ca = append(ca, certs.ExternalCAData(c, reg.Spec.TLS, types.NamespacedName{Name: schemes.RegistryTLSSecretName(reg), Namespace: reg.Namespace})...)
+
 	httpClient := cmhttp.NewHTTPClient(
 		reg.Status.ServerURL,
 		username, password,
@@ -82,7 +88,43 @@ func GetClient(c client.Client, reg *regv1.Registry, scheme *runtime.Scheme) (*C
 
 // ListRepositories get repository list from registry server
 func (c *Client) ListRepositories() *image.APIRepositories {
-	return c.imageClient.Catalog()
+	repos := c.imageClient.Catalog()
+	if repos == nil {
+		return nil
+	}
+
+	filtered := []string{}
+	for _, repo := range repos.Repositories {
+		if repo != ProbeRepository {
+			filtered = append(filtered, repo)
+		}
+	}
+	repos.Repositories = filtered
+	return repos
+}
+
+// SetTimeout sets the time limit of requests to registry server
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.imageClient.HttpClient.Timeout = timeout
+}
+
+// Ping checks registry server serves its api
+func (c *Client) Ping() error {
+	return c.imageClient.Ping()
+}
+
+// CheckAuth checks a token can be acquired with the login credential of registry
+func (c *Client) CheckAuth() error {
+	_, err := c.imageClient.GetCatalogToken()
+	return err
+}
+
+// CheckStorage checks registry can write to its storage by uploading a test blob to ProbeRepository
+func (c *Client) CheckStorage() error {
+	if err := c.imageClient.SetImage(ProbeRepository); err != nil {
+		return err
+	}
+	return c.imageClient.PushTestBlob()
 }
 
 // ListTags get tag list of repository from registry server
@@ -111,7 +153,7 @@ func (c *Client) Synchronize() error {
 		repoList.AddRepository(*tags)
 	}
 
-	if err := sync.Registry(c.kClient, c.Name, c.Namespace, c.scheme, repoList); err != nil {
+	if err := sync.Registry(c.kClient, c.Name, c.Namespace, c.scheme, repoList, c.GetImageDetails); err != nil {
 		Logger.Error(err, "failed to synchronize external registry")
 		return err
 	}
@@ -128,6 +170,15 @@ func (c *Client) GetManifest(image string) (*image.ImageManifest, error) {
 	return c.imageClient.GetManifest()
 }
 
+// GetImageDetails gets digest, size, platforms and labels of image in the registry
+func (c *Client) GetImageDetails(image string) (*image.ImageDetails, error) {
+	if err := c.imageClient.SetImage(image); err != nil {
+		Logger.Error(err, "failed to set image")
+		return nil, err
+	}
+	return c.imageClient.GetDetails()
+}
+
 // DeleteManifest deletes manifest in the registry
 func (c *Client) DeleteManifest(image string, manifest *image.ImageManifest) error {
 	if err := c.imageClient.SetImage(image); err != nil {
@@ -137,6 +188,17 @@ func (c *Client) DeleteManifest(image string, manifest *image.ImageManifest) err
 	return c.imageClient.DeleteManifest(manifest)
 }
 
+// DeleteTag deletes the tag in the registry, keeping the manifest which other tags may refer to.
+// It needs the registry to support deleting tags by the manifest api.
+func (c *Client) DeleteTag(repository, tag string) error {
+	return c.DeleteManifest(fmt.Sprintf("%s:%s", repository, tag), nil)
+}
+
+// DeleteDigest deletes the manifest of the digest in the registry, and all tags which refer to it
+func (c *Client) DeleteDigest(repository, digest string) error {
+	return c.DeleteManifest(fmt.Sprintf("%s@%s", repository, digest), nil)
+}
+
 // PutManifest updates manifest in the registry
 func (c *Client) PutManifest(image string, manifest *image.ImageManifest) error {
 	if err := c.imageClient.SetImage(image); err != nil {
@@ -2,6 +2,10 @@ package inter
 
 import (
 	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	k8sCmd "github.com/tmax-cloud/registry-operator/internal/common/k8s"
 )
@@ -28,9 +32,18 @@ func NewCommander(podName, namespace string) *Commander {
 	return &Commander{pod: podName, ns: namespace}
 }
 
-func (c *Commander) GarbageCollect() (out *Exec, err error) {
+// GarbageCollect executes registry garbage-collect. If dryRun is set, nothing is deleted.
+// If deleteUntagged is set, manifests which are not referenced by any tag are also deleted.
+func (c *Commander) GarbageCollect(dryRun, deleteUntagged bool) (out *Exec, err error) {
 	out = NewExec()
-	out.Command = "/bin/registry garbage-collect /etc/docker/registry/config.yml"
+	out.Command = "/bin/registry garbage-collect"
+	if dryRun {
+		out.Command += " --dry-run"
+	}
+	if deleteUntagged {
+		out.Command += " --delete-untagged"
+	}
+	out.Command += " /etc/docker/registry/config.yml"
 	if err := c.execCmd(out); err != nil {
 		return out, err
 	}
@@ -38,6 +51,105 @@ func (c *Commander) GarbageCollect() (out *Exec, err error) {
 	return out, nil
 }
 
+// DiskFree executes df on the path where registry data is stored
+func (c *Commander) DiskFree(path string) (out *Exec, err error) {
+	out = NewExec()
+	out.Command = "df -P -k " + path
+	if err := c.execCmd(out); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+// ParseDiskFree parses output of `df -P -k` and returns total, used and available bytes
+func ParseDiskFree(out string) (total, used, avail int64, err error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return 0, 0, 0, fmt.Errorf("unexpected df output: %s", out)
+	}
+
+	// Filesystem 1024-blocks Used Available Capacity Mounted on
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, 0, 0, fmt.Errorf("unexpected df output: %s", out)
+	}
+
+	values := []int64{}
+	for _, f := range fields[1:4] {
+		v, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		values = append(values, v*1024)
+	}
+
+	return values[0], values[1], values[2], nil
+}
+
+// DiskUsage executes du on the paths and returns output whose last line is the total
+func (c *Commander) DiskUsage(paths []string) (out *Exec, err error) {
+	out = NewExec()
+	out.Command = "du -s -k -c " + strings.Join(paths, " ")
+	if err := c.execCmd(out); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+// ParseDiskUsage parses output of `du -s -k -c` and returns total bytes
+func ParseDiskUsage(out string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 2 || fields[1] != "total" {
+		return 0, fmt.Errorf("unexpected du output: %s", out)
+	}
+
+	total, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return total * 1024, nil
+}
+
+// GarbageCollectResult is the summary of registry garbage-collect output
+type GarbageCollectResult struct {
+	BlobsMarked       int
+	BlobsEligible     int
+	ManifestsEligible int
+	// EligibleBlobs are digests of blobs eligible for deletion
+	EligibleBlobs []string
+}
+
+var gcSummaryRegexp = regexp.MustCompile(`(\d+) blobs marked, (\d+) blobs and (\d+) manifests eligible for deletion`)
+
+// ParseGarbageCollect parses output of registry garbage-collect
+func ParseGarbageCollect(out string) (*GarbageCollectResult, error) {
+	res := &GarbageCollectResult{}
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if m := gcSummaryRegexp.FindStringSubmatch(line); m != nil {
+			res.BlobsMarked, _ = strconv.Atoi(m[1])
+			res.BlobsEligible, _ = strconv.Atoi(m[2])
+			res.ManifestsEligible, _ = strconv.Atoi(m[3])
+			found = true
+			continue
+		}
+		if strings.HasPrefix(line, "blob eligible for deletion: ") {
+			res.EligibleBlobs = append(res.EligibleBlobs, strings.TrimPrefix(line, "blob eligible for deletion: "))
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unexpected garbage-collect output: %s", out)
+	}
+
+	return res, nil
+}
+
 func (c *Commander) execCmd(e *Exec) error {
 	if err := k8sCmd.ExecCmd(c.pod, RegistryContainerName, c.ns, e.Command, nil, e.Outbuf, e.Errbuf); err != nil {
 		return err
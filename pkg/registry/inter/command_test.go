@@ -0,0 +1,65 @@
+package inter
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParseDiskFree(t *testing.T) {
+	type suite struct {
+		out      string
+		expTotal int64
+		expUsed  int64
+		expAvail int64
+		expError bool
+	}
+	testCases := []suite{
+		{
+			out:      "Filesystem           1024-blocks    Used Available Capacity Mounted on\r\n/dev/rbd0             10255636   2048  10237204   0% /var/lib/registry\r\n",
+			expTotal: 10255636 * 1024,
+			expUsed:  2048 * 1024,
+			expAvail: 10237204 * 1024,
+		},
+		{
+			out:      "df: /var/lib/registry: No such file or directory",
+			expError: true,
+		},
+		{
+			out:      "Filesystem 1024-blocks Used Available Capacity Mounted on\nnfs:/export a b c 0% /data",
+			expError: true,
+		},
+	}
+
+	for _, c := range testCases {
+		total, used, avail, err := ParseDiskFree(c.out)
+		if c.expError {
+			assert.NotEqual(t, nil, err)
+			continue
+		}
+		assert.Equal(t, nil, err)
+		assert.Equal(t, c.expTotal, total)
+		assert.Equal(t, c.expUsed, used)
+		assert.Equal(t, c.expAvail, avail)
+	}
+}
+
+func TestParseGarbageCollect(t *testing.T) {
+	out := "tmax/alpine\r\n" +
+		"tmax/alpine: marking manifest sha256:0a2e4f5e1c2b\r\n" +
+		"tmax/alpine: marking blob sha256:1b3c5d7e9f00\r\n" +
+		"\r\n" +
+		"2 blobs marked, 2 blobs and 1 manifests eligible for deletion\r\n" +
+		"blob eligible for deletion: sha256:2c4e6a8b0d11\r\n" +
+		"blob eligible for deletion: sha256:3d5f7b9c1e22\r\n"
+
+	res, err := ParseGarbageCollect(out)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res.BlobsMarked)
+	assert.Equal(t, 2, res.BlobsEligible)
+	assert.Equal(t, 1, res.ManifestsEligible)
+	assert.Equal(t, []string{"sha256:2c4e6a8b0d11", "sha256:3d5f7b9c1e22"}, res.EligibleBlobs)
+
+	_, err = ParseGarbageCollect("configuration error: open /etc/docker/registry/config.yml: no such file or directory")
+	assert.NotEqual(t, nil, err)
+}
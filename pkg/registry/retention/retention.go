@@ -0,0 +1,176 @@
+// Package retention selects tags to delete by the rules of a retention policy
+package retention
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rules are compiled rules of a retention policy
+type Rules struct {
+	repositories []string
+	keepLast     *int32
+	olderThan    *time.Duration
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+}
+
+// NewRules compiles the rules of the retention policy spec
+func NewRules(spec *v1.RetentionPolicySpec) (*Rules, error) {
+	rules := &Rules{
+		repositories: spec.Repositories,
+		keepLast:     spec.KeepLast,
+	}
+	if spec.OlderThan != nil {
+		d := spec.OlderThan.Duration
+		rules.olderThan = &d
+	}
+
+	var err error
+	if rules.include, err = compile(spec.IncludeTags); err != nil {
+		return nil, err
+	}
+	if rules.exclude, err = compile(spec.ExcludeTags); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func compile(exprs []string) ([]*regexp.Regexp, error) {
+	res := []*regexp.Regexp{}
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// MatchRepository returns true if the policy is applied to the repository
+func (r *Rules) MatchRepository(name string) bool {
+	if len(r.repositories) == 0 {
+		return true
+	}
+	for _, pattern := range r.repositories {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rules) matchTag(tag string) bool {
+	for _, re := range r.exclude {
+		if re.MatchString(tag) {
+			return false
+		}
+	}
+	if len(r.include) == 0 {
+		return true
+	}
+	for _, re := range r.include {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns versions of a repository to delete at the time, and the number of versions which match the rules
+// but are protected. Signed versions and versions for which inUse returns true are protected.
+// Versions already being deleted are ignored.
+func (r *Rules) Select(versions []v1.ImageVersion, now time.Time, inUse func(tag string) bool) ([]v1.ImageVersion, int32) {
+	matched := []v1.ImageVersion{}
+	for _, v := range versions {
+		if v.Delete || !r.matchTag(v.Version) {
+			continue
+		}
+		matched = append(matched, v)
+	}
+
+	// newest first
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(&matched[j].CreatedAt) {
+			return matched[i].Version > matched[j].Version
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt.Time)
+	})
+
+	candidates := []v1.ImageVersion{}
+	protected := int32(0)
+	for i, v := range matched {
+		if r.keepLast != nil && i < int(*r.keepLast) {
+			continue
+		}
+		if r.olderThan != nil && !v.CreatedAt.Time.Before(now.Add(-*r.olderThan)) {
+			continue
+		}
+		if v.Signer != "" || inUse(v.Version) {
+			protected++
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+
+	return candidates, protected
+}
+
+// ImagesInUse returns images and manifest digests of the containers of pods which are not terminated.
+// Images without tag and digest are returned with `latest` tag. Digests are of the images which containers run,
+// so that images pulled by another host name or by digest are found. Images are kept for pods not started yet.
+func ImagesInUse(pods []corev1.Pod) map[string]bool {
+	images := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			images[normalizeImage(c.Image)] = true
+			if digest := imageDigest(c.Image); digest != "" {
+				images[digest] = true
+			}
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, s := range statuses {
+			if digest := imageDigest(s.ImageID); digest != "" {
+				images[digest] = true
+			}
+		}
+	}
+	return images
+}
+
+// imageDigest returns the digest of the image reference like `docker-pullable://host/repo@sha256:...`,
+// or empty if it has no digest. Image ids without repository are digests of configs, not of manifests.
+func imageDigest(ref string) string {
+	i := strings.LastIndex(ref, "@")
+	if i < 0 {
+		return ""
+	}
+	return ref[i+1:]
+}
+
+// ImageName returns the image name of the tag of the repository in the registry host
+func ImageName(host, repository, tag string) string {
+	return host + "/" + repository + ":" + tag
+}
+
+func normalizeImage(image string) string {
+	if strings.Contains(image, "@") {
+		return image
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image
+	}
+	return image + ":latest"
+}
@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	v1 "github.com/tmax-cloud/registry-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelect(t *testing.T) {
+	now := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	day := func(d int) metav1.Time { return metav1.NewTime(time.Date(2021, 1, d, 0, 0, 0, 0, time.UTC)) }
+	versions := []v1.ImageVersion{
+		{Version: "v1", CreatedAt: day(1)},
+		{Version: "v2", CreatedAt: day(2), Signer: "signer"},
+		{Version: "v3", CreatedAt: day(3)},
+		{Version: "v4", CreatedAt: day(4), Delete: true},
+		{Version: "v5", CreatedAt: day(29)},
+		{Version: "v6", CreatedAt: day(30)},
+		{Version: "latest", CreatedAt: day(30)},
+		{Version: "release-1", CreatedAt: day(1)},
+	}
+	inUse := func(tag string) bool { return tag == "v3" }
+	tags := func(vs []v1.ImageVersion) []string {
+		res := []string{}
+		for _, v := range vs {
+			res = append(res, v.Version)
+		}
+		return res
+	}
+
+	keep := int32(2)
+	rules, err := NewRules(&v1.RetentionPolicySpec{
+		KeepLast:    &keep,
+		IncludeTags: []string{`^v\d+$`},
+	})
+	assert.Equal(t, nil, err)
+	candidates, protected := rules.Select(versions, now, inUse)
+	assert.Equal(t, []string{"v1"}, tags(candidates))
+	assert.Equal(t, int32(2), protected)
+
+	rules, err = NewRules(&v1.RetentionPolicySpec{
+		OlderThan:   &metav1.Duration{Duration: 7 * 24 * time.Hour},
+		ExcludeTags: []string{`^latest$`, `^release-`},
+	})
+	assert.Equal(t, nil, err)
+	candidates, protected = rules.Select(versions, now, inUse)
+	assert.Equal(t, []string{"v1"}, tags(candidates))
+	assert.Equal(t, int32(2), protected)
+
+	_, err = NewRules(&v1.RetentionPolicySpec{IncludeTags: []string{"("}})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMatchRepository(t *testing.T) {
+	rules, _ := NewRules(&v1.RetentionPolicySpec{Repositories: []string{"library/*"}})
+	assert.Equal(t, true, rules.MatchRepository("library/alpine"))
+	assert.Equal(t, false, rules.MatchRepository("library/tools/alpine"))
+	assert.Equal(t, false, rules.MatchRepository("alpine"))
+
+	rules, _ = NewRules(&v1.RetentionPolicySpec{})
+	assert.Equal(t, true, rules.MatchRepository("alpine"))
+}
+
+func TestImagesInUse(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Image: "reg.example.com:443/library/init"}},
+				Containers:     []corev1.Container{{Image: "reg.example.com:443/library/app:v1"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Image: "reg.example.com:443/library/app:v2"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		{
+			// pulled by another host name and by digest
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Image: "reg.example.com/library/web:v3"},
+				{Image: "reg.example.com/library/db@sha256:db"},
+			}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{ImageID: "docker-pullable://reg.example.com/library/web@sha256:web"},
+					{ImageID: "sha256:config"},
+				},
+			},
+		},
+	}
+
+	images := ImagesInUse(pods)
+	assert.Equal(t, true, images[ImageName("reg.example.com:443", "library/init", "latest")])
+	assert.Equal(t, true, images[ImageName("reg.example.com:443", "library/app", "v1")])
+	assert.Equal(t, false, images[ImageName("reg.example.com:443", "library/app", "v2")])
+	assert.Equal(t, false, images[ImageName("reg.example.com:443", "library/web", "v3")])
+	assert.Equal(t, true, images["sha256:web"])
+	assert.Equal(t, true, images["sha256:db"])
+	assert.Equal(t, false, images["sha256:config"])
+}
@@ -18,8 +18,12 @@ import (
 
 var logger = log.Log.WithName("sync-registry")
 
-// Registry synchronizes custom resource repository based on all repositories in registry server
-func Registry(c client.Client, registry, namespace string, scheme *runtime.Scheme, repos *image.APIRepositoryList) error {
+// DetailsFunc gets details of the image("<repository>:<tag>") in registry server
+type DetailsFunc func(image string) (*image.ImageDetails, error)
+
+// Registry synchronizes custom resource repository based on all repositories in registry server,
+// and records details of versions which are not recorded yet to repository status
+func Registry(c client.Client, registry, namespace string, scheme *runtime.Scheme, repos *image.APIRepositoryList, details DetailsFunc) error {
 	syncLog := logger.WithValues("registry_name", registry, "registry_ns", namespace)
 
 	crImages, crImageNames, err := crImages(c, registry, namespace)
@@ -69,6 +73,11 @@ func Registry(c client.Client, registry, namespace string, scheme *runtime.Schem
 		return err
 	}
 
+	if err := updateVersionDetails(c, registry, namespace, details); err != nil {
+		syncLog.Error(err, "failed to update version details")
+		return err
+	}
+
 	return nil
 }
 
@@ -267,3 +276,43 @@ func patchRepository(c client.Client, registry, namespace string, existRepositor
 
 	return nil
 }
+
+// updateVersionDetails records details of versions which are not recorded yet to repository status.
+// Versions whose details cannot be got are skipped, and tried again at next synchronization.
+func updateVersionDetails(c client.Client, registry, namespace string, getDetails DetailsFunc) error {
+	syncLog := logger.WithValues("registry_name", registry, "registry_ns", namespace)
+	repoCtl := repoctl.New()
+
+	reposCR, err := getCRRepositories(c, registry, namespace)
+	if err != nil {
+		return err
+	}
+
+	for i := range reposCR.Items {
+		repo := &reposCR.Items[i]
+		recorded := map[string]bool{}
+		for _, s := range repo.Status.Versions {
+			recorded[s.Version] = s.Digest != ""
+		}
+
+		details := map[string]*image.ImageDetails{}
+		for _, ver := range repo.Spec.Versions {
+			if recorded[ver.Version] || ver.Delete {
+				continue
+			}
+			d, err := getDetails(repo.Spec.Name + ":" + ver.Version)
+			if err != nil {
+				syncLog.Error(err, "failed to get image details", "repo", repo.Spec.Name, "version", ver.Version)
+				continue
+			}
+			details[ver.Version] = d
+		}
+
+		if err := repoCtl.UpdateVersionStatus(c, repo, details); err != nil {
+			syncLog.Error(err, "failed to update repository status", "repo", repo.Spec.Name)
+			return err
+		}
+	}
+
+	return nil
+}
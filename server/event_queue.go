@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// eventQueueName is the name of the work queue, which is the name label of workqueue metrics such as workqueue_depth
+const eventQueueName = "registry_event"
+
+// recentEventsPerRegistry is the number of processed events remembered for each registry.
+// Registry sends events again if it does not get the response, so that processed events may come again.
+const recentEventsPerRegistry = 1000
+
+var (
+	eventFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_event_failures_total",
+		Help: "Total number of failed attempts to process registry events",
+	}, []string{"action"})
+	eventDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_event_dropped_total",
+		Help: "Total number of registry events dropped after their retries are exhausted",
+	}, []string{"action"})
+	eventRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "registry_event_rejected_total",
+		Help: "Total number of registry events rejected since the queue is full",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventFailures, eventDropped, eventRejected)
+}
+
+// errEventQueueFull is returned when events cannot be queued until queued events are processed
+var errEventQueueFull = fmt.Errorf("registry event queue is full")
+
+// queuedEvent is an event and the registry which sent it
+type queuedEvent struct {
+	registry types.NamespacedName
+	event    regv1.RegistryEvent
+}
+
+// recentEvents are keys of the latest processed events of a registry, up to recentEventsPerRegistry
+type recentEvents struct {
+	keys  map[string]bool
+	order []string
+	next  int
+}
+
+func (r *recentEvents) add(key string) {
+	if r.keys[key] {
+		return
+	}
+	if len(r.order) < recentEventsPerRegistry {
+		r.order = append(r.order, key)
+	} else {
+		delete(r.keys, r.order[r.next])
+		r.order[r.next] = key
+	}
+	r.next = (r.next + 1) % recentEventsPerRegistry
+	r.keys[key] = true
+}
+
+// eventQueue processes registry events asynchronously and retries failed events with rate limited backoff.
+// Events are keyed by their registry and id, so an event sent again before it is processed is queued once,
+// and an event sent again after it is processed is ignored while it is one of the recent events of the registry.
+type eventQueue struct {
+	queue      workqueue.RateLimitingInterface
+	process    func(types.NamespacedName, regv1.RegistryEvent) error
+	size       int
+	workers    int
+	maxRetries int
+
+	// events are queued events by their keys, including events being processed and waiting for retries
+	events map[string]queuedEvent
+	// recent are the latest processed events of each registry
+	recent map[types.NamespacedName]*recentEvents
+	lock   sync.Mutex
+}
+
+func newEventQueue(size, workers, maxRetries int, process func(types.NamespacedName, regv1.RegistryEvent) error) *eventQueue {
+	return &eventQueue{
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), eventQueueName),
+		process:    process,
+		size:       size,
+		workers:    workers,
+		maxRetries: maxRetries,
+		events:     map[string]queuedEvent{},
+		recent:     map[types.NamespacedName]*recentEvents{},
+	}
+}
+
+// Add queues events of the registry which are not queued yet.
+// If there is no room for all of them, none is queued, so that the registry sends the notification again.
+func (q *eventQueue) Add(reg types.NamespacedName, events []regv1.RegistryEvent) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	newEvents := map[string]queuedEvent{}
+	for _, event := range events {
+		if recent, ok := q.recent[reg]; ok && recent.keys[eventKey(event)] {
+			continue
+		}
+		key := reg.String() + "/" + eventKey(event)
+		if _, ok := q.events[key]; ok {
+			continue
+		}
+		newEvents[key] = queuedEvent{registry: reg, event: event}
+	}
+	if len(q.events)+len(newEvents) > q.size {
+		eventRejected.Add(float64(len(newEvents)))
+		return errEventQueueFull
+	}
+
+	for key, event := range newEvents {
+		q.events[key] = event
+		q.queue.Add(key)
+	}
+	return nil
+}
+
+// Start runs workers until the stop channel is closed
+func (q *eventQueue) Start(stop <-chan struct{}) error {
+	defer q.queue.ShutDown()
+
+	logger.Info("Start registry event workers", "workers", q.workers)
+	for i := 0; i < q.workers; i++ {
+		go wait.Until(q.worker, time.Second, stop)
+	}
+	<-stop
+	return nil
+}
+
+// NeedLeaderElection returns false, since every replica of the operator receives registry events
+func (q *eventQueue) NeedLeaderElection() bool {
+	return false
+}
+
+func (q *eventQueue) worker() {
+	for q.processNext() {
+	}
+}
+
+// processNext processes an event from the queue and returns false if the queue is shut down
+func (q *eventQueue) processNext() bool {
+	key, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(key)
+
+	q.lock.Lock()
+	queued, ok := q.events[key.(string)]
+	q.lock.Unlock()
+	if !ok {
+		q.queue.Forget(key)
+		return true
+	}
+
+	event := queued.event
+	err := q.process(queued.registry, event)
+	if err == nil {
+		q.lock.Lock()
+		recent, ok := q.recent[queued.registry]
+		if !ok {
+			recent = &recentEvents{keys: map[string]bool{}}
+			q.recent[queued.registry] = recent
+		}
+		recent.add(eventKey(event))
+		q.lock.Unlock()
+		q.remove(key)
+		return true
+	}
+
+	eventFailures.WithLabelValues(event.Action).Inc()
+	if q.queue.NumRequeues(key) < q.maxRetries {
+		logz.Error(err, "failed to process registry event, retrying", "registry", queued.registry.String(), "id", event.Id, "action", event.Action, "repository", event.Target.Repository)
+		q.queue.AddRateLimited(key)
+		return true
+	}
+
+	logz.Error(err, "failed to process registry event, dropping", "registry", queued.registry.String(), "id", event.Id, "action", event.Action, "repository", event.Target.Repository)
+	eventDropped.WithLabelValues(event.Action).Inc()
+	q.remove(key)
+	return true
+}
+
+func (q *eventQueue) remove(key interface{}) {
+	q.queue.Forget(key)
+
+	q.lock.Lock()
+	delete(q.events, key.(string))
+	q.lock.Unlock()
+}
+
+// eventKey returns the id of the event, or its content if the registry did not set the id
+func eventKey(event regv1.RegistryEvent) string {
+	if event.Id != "" {
+		return event.Id
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s", event.Source.Addr, event.Timestamp, event.Action, event.Target.Repository, event.Target.Tag, event.Target.Digest)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestEventQueue(t *testing.T) {
+	attempts := map[string]int{}
+	q := newEventQueue(2, 1, 1, func(reg types.NamespacedName, event regv1.RegistryEvent) error {
+		attempts[event.Id]++
+		if event.Action == "delete" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+	defer q.queue.ShutDown()
+
+	push := regv1.RegistryEvent{Id: "push", Action: "push"}
+	del := regv1.RegistryEvent{Id: "delete", Action: "delete"}
+	pull := regv1.RegistryEvent{Id: "pull", Action: "pull"}
+	reg := types.NamespacedName{Namespace: "ns", Name: "reg"}
+
+	// duplicated events are queued once, and events over the size are not queued at all
+	assert.Equal(t, nil, q.Add(reg, []regv1.RegistryEvent{push, push, del}))
+	assert.Equal(t, nil, q.Add(reg, []regv1.RegistryEvent{push}))
+	assert.Equal(t, errEventQueueFull, q.Add(reg, []regv1.RegistryEvent{pull}))
+	assert.Equal(t, 2, q.queue.Len())
+
+	// a failed event is retried up to max retries, and then dropped
+	for len(q.events) > 0 {
+		q.processNext()
+	}
+	assert.Equal(t, map[string]int{"push": 1, "delete": 2}, attempts)
+	assert.Equal(t, nil, q.Add(reg, []regv1.RegistryEvent{pull}))
+
+	// a processed event sent again is ignored, but a dropped one is queued again
+	assert.Equal(t, nil, q.Add(reg, []regv1.RegistryEvent{push, del}))
+	assert.Equal(t, 2, q.queue.Len())
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+	assert.Equal(t, errEventQueueFull, q.Add(other, []regv1.RegistryEvent{push}))
+}
+
+func TestRecentEvents(t *testing.T) {
+	recent := &recentEvents{keys: map[string]bool{}}
+	for i := 0; i < recentEventsPerRegistry+10; i++ {
+		recent.add(fmt.Sprint(i))
+	}
+	recent.add("20")
+
+	assert.Equal(t, recentEventsPerRegistry, len(recent.keys))
+	assert.Equal(t, false, recent.keys["9"])
+	assert.Equal(t, true, recent.keys["10"])
+	assert.Equal(t, true, recent.keys[fmt.Sprint(recentEventsPerRegistry+9)])
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/regctl"
+	"github.com/tmax-cloud/registry-operator/controllers/repoctl"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// enforceQuota updates usage of pushed image's repository and rejects the image if it exceeds quota
+func enforceQuota(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	logger := logz.WithValues("registry", reg.Name, "ns", reg.Namespace)
+	repoName := event.Target.Repository
+	tag := event.Target.Tag
+
+	repo := &regv1.Repository{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.RepositoryName(repoName, reg.Name), Namespace: reg.Namespace}, repo); err != nil {
+		// the tag may be deleted after it is pushed
+		return client.IgnoreNotFound(err)
+	}
+	if reg.Spec.Quota == nil && repo.Spec.Quota == nil {
+		return nil
+	}
+
+	regClient, err := inter.GetClient(k8sClient, reg, scheme)
+	if err != nil {
+		return err
+	}
+
+	tags := []string{}
+	for _, v := range repo.Spec.Versions {
+		tags = append(tags, v.Version)
+	}
+	used, details, err := regctl.RepositoryUsage(regClient, repoName, tags)
+	if err != nil {
+		return fmt.Errorf("failed to get repository usage: %s", err.Error())
+	}
+	if err := patchUsedBytes(repo, used); err != nil {
+		return err
+	}
+
+	reason, err := regctl.CheckRepositoryQuota(k8sClient, reg, repo)
+	if err != nil {
+		return fmt.Errorf("failed to check repository quota: %s", err.Error())
+	}
+	if reason != "" {
+		// a tag which existed before is not deleted, since its previous content is already overwritten
+		if !isPushedVersion(repo, event) {
+			logger.Info("keep overwritten image", "repository", repoName, "ver", tag, "reason", reason)
+			recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonQuotaExceeded, fmt.Sprintf("%s:%s is overwritten over quota, but kept: %s", repoName, tag, reason))
+		} else {
+			logger.Info("reject image", "repository", repoName, "ver", tag, "reason", reason)
+			recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonQuotaExceeded, fmt.Sprintf("%s:%s is rejected: %s", repoName, tag, reason))
+			if err := rejectImage(regClient, reg, repo, tag, details); err != nil {
+				return fmt.Errorf("failed to reject image: %s", err.Error())
+			}
+			return nil
+		}
+	}
+
+	changed, err := regctl.UpdateRegistryQuota(k8sClient, reg, regClient)
+	if err != nil {
+		return fmt.Errorf("failed to update registry quota: %s", err.Error())
+	}
+	if err := k8sClient.Status().Update(context.TODO(), reg); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	if reg.Status.Quota.IsExceeded() {
+		recorder.Event(reg, corev1.EventTypeWarning, regctl.EventReasonQuotaExceeded, reg.Status.Quota.Message+", registry is switched to read-only")
+	} else {
+		recorder.Event(reg, corev1.EventTypeNormal, regctl.EventReasonQuotaRecovered, "registry is switched back from read-only")
+	}
+	if err := regctl.SetDeploymentReadOnly(k8sClient, reg, reg.Spec.ReadOnly || reg.Status.Quota.IsExceeded()); err != nil {
+		return fmt.Errorf("failed to switch registry read-only: %s", err.Error())
+	}
+	return nil
+}
+
+// patchUsedBytes updates the usage of the repository in its status
+func patchUsedBytes(repo *regv1.Repository, used int64) error {
+	latest := &regv1.Repository{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: repo.Name, Namespace: repo.Namespace}, latest); err != nil {
+		return err
+	}
+	if latest.Status.UsedBytes == used {
+		return nil
+	}
+
+	original := latest.DeepCopy()
+	latest.Status.UsedBytes = used
+	return k8sClient.Status().Patch(context.TODO(), latest, client.MergeFrom(original))
+}
+
+// imageDeleter deletes tags and manifests in the registry
+type imageDeleter interface {
+	DeleteTag(repository, tag string) error
+	DeleteDigest(repository, digest string) error
+}
+
+// rejectImage deletes the pushed tag from registry and repository cr, and updates usage of the repository without it.
+// Only the tag is deleted if other tags refer to the same digest, otherwise the manifest is deleted.
+func rejectImage(regClient imageDeleter, reg *regv1.Registry, repo *regv1.Repository, tag string, details map[string]*image.ImageDetails) error {
+	pushed := details[tag]
+	shared := false
+	for t, d := range details {
+		if t != tag && d.Digest == pushed.Digest {
+			shared = true
+			break
+		}
+	}
+
+	if shared {
+		if err := regClient.DeleteTag(repo.Spec.Name, tag); err != nil {
+			return err
+		}
+	} else {
+		if err := regClient.DeleteDigest(repo.Spec.Name, pushed.Digest); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := removeVersion(reg, repo.Spec.Name, tag)
+	if err != nil || deleted {
+		return err
+	}
+
+	// usage without the rejected image
+	remaining := []*image.ImageDetails{}
+	for t, d := range details {
+		if t != tag {
+			remaining = append(remaining, d)
+		}
+	}
+	return patchUsedBytes(repo, image.TotalSize(remaining...))
+}
+
+// removeVersion removes the version from the repository cr with optimistic lock, and returns true if the repository cr
+// is deleted since it has no version left
+func removeVersion(reg *regv1.Registry, repoName, tag string) (bool, error) {
+	deleted := false
+	err := retryOnConflict(func() error {
+		repo := &regv1.Repository{}
+		if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.RepositoryName(repoName, reg.Name), Namespace: reg.Namespace}, repo); err != nil {
+			if errors.IsNotFound(err) {
+				deleted = true
+				return nil
+			}
+			return err
+		}
+
+		versions := []regv1.ImageVersion{}
+		for _, v := range repo.Spec.Versions {
+			if v.Version != tag {
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) == len(repo.Spec.Versions) {
+			return nil
+		}
+		if len(versions) == 0 {
+			deleted = true
+			return deleteRepository(repo)
+		}
+
+		patchRepo := repo.DeepCopy()
+		patchRepo.Spec.Versions = versions
+		return repoctl.New().Patch(k8sClient, repo, patchRepo, client.MergeFromWithOptimisticLock{})
+	})
+	return deleted, err
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeImageDeleter records tags and digests deleted
+type fakeImageDeleter struct {
+	tags    []string
+	digests []string
+}
+
+func (f *fakeImageDeleter) DeleteTag(repository, tag string) error {
+	f.tags = append(f.tags, repository+":"+tag)
+	return nil
+}
+
+func (f *fakeImageDeleter) DeleteDigest(repository, digest string) error {
+	f.digests = append(f.digests, repository+"@"+digest)
+	return nil
+}
+
+func TestRejectImage(t *testing.T) {
+	digests := map[string]string{"v1": "sha256:a", "v2": "sha256:b", "v3": "sha256:a"}
+	details := func() map[string]*image.ImageDetails {
+		d := map[string]*image.ImageDetails{}
+		for tag, digest := range digests {
+			d[tag] = &image.ImageDetails{Digest: digest, Size: 10, Blobs: map[string]int64{digest: 10}}
+		}
+		return d
+	}
+
+	// only the tag is deleted if other tags refer to its digest
+	reg, repo := newTestRepository(t, digests)
+	deleter := &fakeImageDeleter{}
+	assert.Equal(t, nil, rejectImage(deleter, reg, repo, "v3", details()))
+	assert.Equal(t, []string{"library/app:v3"}, deleter.tags)
+	assert.Equal(t, 0, len(deleter.digests))
+	latest := getTestRepository(t, repo)
+	assert.Equal(t, 2, len(latest.Spec.Versions))
+	assert.Equal(t, int64(20), latest.Status.UsedBytes)
+
+	// the manifest is deleted if no other tag refers to it
+	reg, repo = newTestRepository(t, digests)
+	deleter = &fakeImageDeleter{}
+	assert.Equal(t, nil, rejectImage(deleter, reg, repo, "v2", details()))
+	assert.Equal(t, 0, len(deleter.tags))
+	assert.Equal(t, []string{"library/app@sha256:b"}, deleter.digests)
+	latest = getTestRepository(t, repo)
+	assert.Equal(t, 2, len(latest.Spec.Versions))
+	assert.Equal(t, int64(10), latest.Status.UsedBytes)
+}
+
+func TestIsPushedVersion(t *testing.T) {
+	pushedAt := time.Date(2021, 3, 1, 10, 0, 0, 500, time.UTC)
+	repo := &regv1.Repository{}
+	repo.Spec.Versions = []regv1.ImageVersion{
+		{Version: "old", CreatedAt: metav1.NewTime(pushedAt.Add(-time.Hour))},
+		{Version: "new", CreatedAt: metav1.NewTime(pushedAt.Truncate(time.Second))},
+	}
+
+	push := func(tag string) regv1.RegistryEvent {
+		event := regv1.RegistryEvent{Action: "push", Timestamp: pushedAt.Format(time.RFC3339Nano)}
+		event.Target.Tag = tag
+		return event
+	}
+	// a tag overwritten by the push existed before, and is not rejected
+	assert.Equal(t, false, isPushedVersion(repo, push("old")))
+	assert.Equal(t, true, isPushedVersion(repo, push("new")))
+	assert.Equal(t, false, isPushedVersion(repo, push("unknown")))
+}
@@ -2,21 +2,27 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
 	"github.com/tmax-cloud/registry-operator/controllers/repoctl"
 	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -27,8 +33,22 @@ const (
 var logz = log.Log.WithName("server-handler")
 
 func CreateImageHandler(w http.ResponseWriter, r *http.Request) {
+	reg, err := authenticate(r)
+	if err != nil {
+		logz.Error(err, "failed to authenticate registry events")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, err)
+		return
+	}
+	if reg == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "invalid notification token")
+		return
+	}
+
 	regEvents := &regv1.RegistryEvents{}
-	err := json.NewDecoder(r.Body).Decode(regEvents)
+	err = json.NewDecoder(r.Body).Decode(regEvents)
 	if err != nil {
 		logz.Error(err, "decode error")
 		w.WriteHeader(http.StatusBadRequest)
@@ -36,114 +56,209 @@ func CreateImageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, event := range regEvents.Events {
-		if event.Action == "push" {
-			if len(event.Target.Tag) == 0 {
-				logz.Info("tag is nil", "repository", event.Target.Repository)
-				continue
-			}
-			logz.Info("pushed", "image", event.Target.Repository+":"+event.Target.Tag)
-			createImage(event)
-		}
+	if err := events.Add(types.NamespacedName{Name: reg.Name, Namespace: reg.Namespace}, regEvents.Events); err != nil {
+		logz.Error(err, "failed to queue events", "registry", reg.Name, "ns", reg.Namespace, "events", len(regEvents.Events))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func createImage(event regv1.RegistryEvent) {
-	// Get registry
-	reg := registry(k8sClient, event)
-	if reg == nil {
-		logz.Info("registry not found", "registry_pod", strings.Split(event.Source.Addr, ":")[0])
-		return
+// authenticate returns the registry whose notification token is the bearer token of the request,
+// or nil if the token is invalid or the client certificate is required but not verified
+func authenticate(r *http.Request) (*regv1.Registry, error) {
+	if requireClientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		return nil, nil
 	}
-	logger := logz.WithValues("registry", reg.Name, "ns", reg.Namespace)
 
-	// Check if repository cr is exist
-	repositoryCRNotFound := false
-	repository := &regv1.Repository{}
-	repositoryName := event.Target.Repository
-	newImageTag := event.Target.Tag
-	repositoryCRName := schemes.RepositoryName(repositoryName, reg.Name)
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, nil
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+	key, ok := schemes.NotificationTokenRegistry(token)
+	if !ok {
+		return nil, nil
+	}
 
-	err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: repositoryCRName, Namespace: reg.Namespace}, repository)
-	if err != nil {
+	reg := &regv1.Registry{}
+	if err := k8sClient.Get(context.TODO(), key, reg); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.SubresourceName(reg, schemes.SubTypeRegistryOpaqueSecret), Namespace: reg.Namespace}, secret); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	expected := secret.Data[schemes.CredentialSecretNotificationToken]
+	if len(expected) == 0 || subtle.ConstantTimeCompare(expected, []byte(token)) != 1 {
+		return nil, nil
+	}
+
+	return reg, nil
+}
+
+// processEvent applies a registry event to repositories and registry users of the registry which sent it
+func processEvent(key types.NamespacedName, event regv1.RegistryEvent) error {
+	reg := &regv1.Registry{}
+	if err := k8sClient.Get(context.TODO(), key, reg); err != nil {
 		if errors.IsNotFound(err) {
-			repositoryCRNotFound = true
-		} else {
-			logger.Error(err, "Unknown error")
-			return
+			logz.Info("registry not found", "registry", key.Name, "ns", key.Namespace)
+			return nil
 		}
+		return err
 	}
 
+	switch event.Action {
+	case "push":
+		if len(event.Target.Tag) == 0 {
+			break
+		}
+		logz.Info("pushed", "image", event.Target.Repository+":"+event.Target.Tag)
+		if err := createImage(reg, event); err != nil {
+			return err
+		}
+		if err := recordVersionDetails(reg, event); err != nil {
+			return err
+		}
+		if err := enforceQuota(reg, event); err != nil {
+			return err
+		}
+	case "delete":
+		if len(event.Target.Repository) == 0 {
+			break
+		}
+		if err := deleteVersions(reg, event); err != nil {
+			return err
+		}
+	case "pull":
+		if len(event.Target.Repository) == 0 {
+			break
+		}
+		if err := countPulls(reg, event); err != nil {
+			return err
+		}
+	}
+
+	updateLastUsed(reg, event)
+	return nil
+}
+
+// createImage creates or patches repository cr of pushed image.
+// Events of the same repository are processed concurrently, so versions are patched with optimistic lock.
+func createImage(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	logger := logz.WithValues("registry", reg.Name, "ns", reg.Namespace)
+	repositoryName := event.Target.Repository
+	newImageTag := event.Target.Tag
+	repositoryCRName := schemes.RepositoryName(repositoryName, reg.Name)
+	// the time of the push tells the version is created by the event, when the event is processed again
+	createdAt := metav1.NewTime(eventTime(event).Truncate(time.Second))
 	repoCtl := &repoctl.RegistryRepository{}
-	if repositoryCRNotFound {
-		// If not exist, create repository cr
-		logger.Info("create", "repository", repositoryName, "ver", newImageTag)
-		if err := repoCtl.Create(k8sClient, reg, repositoryName, []string{newImageTag}, scheme); err != nil {
-			logger.Error(err, "failed to create repository")
-			return
+
+	return retryOnConflict(func() error {
+		// Check if repository cr is exist
+		repository := &regv1.Repository{}
+		if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: repositoryCRName, Namespace: reg.Namespace}, repository); err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Error(err, "Unknown error")
+				return err
+			}
+
+			// If not exist, create repository cr
+			logger.Info("create", "repository", repositoryName, "ver", newImageTag)
+			repository = schemes.Repository(reg, repositoryName, []string{newImageTag})
+			repository.Spec.Versions[0].CreatedAt = createdAt
+			if err := controllerutil.SetControllerReference(reg, repository, scheme); err != nil {
+				return err
+			}
+			if err := k8sClient.Create(context.TODO(), repository); err != nil {
+				logger.Error(err, "failed to create repository")
+				return err
+			}
+			return nil
 		}
 
-	} else {
 		// Check if new version is exist
 		if isExistVersion(repository.Spec.Versions, newImageTag) {
 			logger.Info("version is already exist", "repository", repositoryName, "ver", newImageTag)
-			return
+			return nil
 		}
 
 		// if exist, patch repository cr
 		patchRepo := repository.DeepCopy()
-		newVersion := regv1.ImageVersion{Version: newImageTag, CreatedAt: metav1.Now()}
+		newVersion := regv1.ImageVersion{Version: newImageTag, CreatedAt: createdAt}
 
 		patchRepo.Spec.Versions = append(patchRepo.Spec.Versions, newVersion)
 		logger.Info("repo_new_version", "repository", repositoryName, "ver", newImageTag)
 
-		err := repoCtl.Patch(k8sClient, repository, patchRepo)
-		if err != nil {
+		if err := repoCtl.Patch(k8sClient, repository, patchRepo, client.MergeFromWithOptimisticLock{}); err != nil {
 			logger.Error(err, "repository patch error")
-			return
+			return err
 		}
+		return nil
+	})
+}
+
+// recordVersionDetails records digest, size, platforms and labels of pushed image to its repository status
+func recordVersionDetails(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	repoName := event.Target.Repository
+	tag := event.Target.Tag
+
+	regClient, err := inter.GetClient(k8sClient, reg, scheme)
+	if err != nil {
+		return err
 	}
 
+	details, err := regClient.GetImageDetails(fmt.Sprintf("%s:%s", repoName, tag))
+	if err != nil {
+		return fmt.Errorf("failed to get image details of %s:%s: %s", repoName, tag, err.Error())
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		repo := &regv1.Repository{}
+		if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.RepositoryName(repoName, reg.Name), Namespace: reg.Namespace}, repo); err != nil {
+			// the tag may be deleted after it is pushed
+			return client.IgnoreNotFound(err)
+		}
+		if !isExistVersion(repo.Spec.Versions, tag) {
+			return nil
+		}
+		return repoctl.New().UpdateVersionStatus(k8sClient, repo, map[string]*image.ImageDetails{tag: details}, client.MergeFromWithOptimisticLock{})
+	})
 }
 
-func isExistVersion(versions []regv1.ImageVersion, version string) bool {
-	for _, ver := range versions {
-		if ver.Version == version {
-			return true
+// retryOnConflict retries the function if the repository is changed or created after it is got
+func retryOnConflict(fn func() error) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}, fn)
+}
+
+// isPushedVersion returns true if the version is created by the push event, not overwritten by it
+func isPushedVersion(repo *regv1.Repository, event regv1.RegistryEvent) bool {
+	for _, v := range repo.Spec.Versions {
+		if v.Version == event.Target.Tag {
+			return !v.CreatedAt.Time.Before(eventTime(event).Truncate(time.Second))
 		}
 	}
 	return false
 }
 
-func registry(c client.Client, event regv1.RegistryEvent) *regv1.Registry {
-	searchPodName := strings.Split(event.Source.Addr, ":")[0]
-	podList := &corev1.PodList{}
-
-	err := c.List(context.TODO(), podList, &client.ListOptions{})
+// eventTime returns the time when the event occurred, or now if the registry did not set it
+func eventTime(event regv1.RegistryEvent) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, event.Timestamp)
 	if err != nil {
-		logz.Error(err, "Failed to list pods.")
-		return nil
+		return time.Now()
 	}
+	return t
+}
 
-	for _, pod := range podList.Items {
-		if pod.Name == searchPodName {
-			// Get registry name
-			regName := pod.Labels["apps"][len(regv1.K8sPrefix):]
-			logz.Info("msg", "registry_name", regName)
-			registry := &regv1.Registry{}
-
-			err := c.Get(context.TODO(), types.NamespacedName{Name: regName, Namespace: pod.Namespace}, registry)
-			if err != nil {
-				logz.Error(err, "Get regsitry is failed")
-				return nil
-			}
-
-			return registry
+func isExistVersion(versions []regv1.ImageVersion, version string) bool {
+	for _, ver := range versions {
+		if ver.Version == version {
+			return true
 		}
 	}
-
-	logz.Info(searchPodName + " pod not found")
-	return nil
+	return false
 }
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// lastUsedResolution is the minimum interval to update lastUsed of a registry user, not to patch it for every layer
+const lastUsedResolution = time.Minute
+
+// updateLastUsed updates lastUsed of the registry user who is the actor of the event
+func updateLastUsed(reg *regv1.Registry, event regv1.RegistryEvent) {
+	if event.Actor.Name == "" || (event.Action != "push" && event.Action != "pull") {
+		return
+	}
+	t, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+	if err != nil {
+		t = time.Now()
+	}
+
+	users := &regv1.RegistryUserList{}
+	if err := k8sClient.List(context.TODO(), users, client.InNamespace(reg.Namespace)); err != nil {
+		logz.Error(err, "failed to list registry users", "ns", reg.Namespace)
+		return
+	}
+	for _, user := range users.Items {
+		if user.Spec.Registry != reg.Name || user.GetUsername() != event.Actor.Name {
+			continue
+		}
+		if user.Status.LastUsed != nil && t.Sub(user.Status.LastUsed.Time) < lastUsedResolution {
+			continue
+		}
+
+		original := user.DeepCopy()
+		user.Status.LastUsed = &metav1.Time{Time: t}
+		if err := k8sClient.Status().Patch(context.TODO(), &user, client.MergeFrom(original)); err != nil {
+			logz.Error(err, "failed to update last used time of registry user", "ns", user.Namespace, "name", user.Name)
+		}
+	}
+}
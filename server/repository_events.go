@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/controllers/repoctl"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/internal/utils"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"github.com/tmax-cloud/registry-operator/pkg/registry/inter"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deleteVersions removes versions deleted by the event from the repository.
+// The event has a tag if a tag is deleted, a digest if a manifest(or a blob) is deleted, or neither if the repository is deleted.
+// If a manifest of a version whose digest is not recorded is deleted, versions are checked with tags in the registry.
+// The repository is changed with optimistic lock, so that versions pushed concurrently are not removed.
+func deleteVersions(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	return retryOnConflict(func() error {
+		return deleteVersionsOnce(reg, event)
+	})
+}
+
+func deleteVersionsOnce(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	repoName := event.Target.Repository
+	repo := &regv1.Repository{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.RepositoryName(repoName, reg.Name), Namespace: reg.Namespace}, repo); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	deletedTags := map[string]bool{}
+	deletedDigest := ""
+	repoDeleted := false
+	switch {
+	case event.Target.Tag != "":
+		deletedTags[event.Target.Tag] = true
+	case event.Target.Digest != "":
+		deletedDigest = event.Target.Digest
+	default:
+		repoDeleted = true
+	}
+
+	digests := map[string]string{}
+	for _, s := range repo.Status.Versions {
+		digests[s.Version] = s.Digest
+	}
+	verify := false
+	for _, v := range repo.Spec.Versions {
+		d := digests[v.Version]
+		if d != "" && d == deletedDigest {
+			deletedTags[v.Version] = true
+		}
+		if d == "" && deletedDigest != "" {
+			verify = true
+		}
+	}
+	if verify && !repoDeleted {
+		regClient, err := inter.GetClient(k8sClient, reg, scheme)
+		if err != nil {
+			return err
+		}
+		// tags are not checked if they cannot be listed, they are removed at next synchronization
+		if tags := regClient.ListTags(repoName); tags != nil {
+			for _, v := range repo.Spec.Versions {
+				if !utils.Contains(tags.Tags, v.Version) {
+					deletedTags[v.Version] = true
+				}
+			}
+		}
+	}
+
+	versions := []regv1.ImageVersion{}
+	for _, v := range repo.Spec.Versions {
+		if !repoDeleted && !deletedTags[v.Version] {
+			versions = append(versions, v)
+		}
+	}
+	repoCtl := repoctl.New()
+	if len(versions) == len(repo.Spec.Versions) {
+		// status of versions removed by a previous attempt may be left
+		return repoCtl.UpdateVersionStatus(k8sClient, repo, nil, client.MergeFromWithOptimisticLock{})
+	}
+
+	if len(versions) == 0 {
+		logz.Info("delete", "repository", repoName)
+		return deleteRepository(repo)
+	}
+
+	logz.Info("delete", "repository", repoName, "versions", len(repo.Spec.Versions)-len(versions))
+	patchRepo := repo.DeepCopy()
+	patchRepo.Spec.Versions = versions
+	if err := repoCtl.Patch(k8sClient, repo, patchRepo, client.MergeFromWithOptimisticLock{}); err != nil {
+		return err
+	}
+	return repoCtl.UpdateVersionStatus(k8sClient, patchRepo, nil, client.MergeFromWithOptimisticLock{})
+}
+
+// deleteRepository deletes the repository cr which has no version left.
+// It fails with conflict if the repository is changed after it is got, e.g. a tag is pushed.
+func deleteRepository(repo *regv1.Repository) error {
+	rv := repo.ResourceVersion
+	if err := k8sClient.Delete(context.TODO(), repo, client.Preconditions{ResourceVersion: &rv}); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return nil
+}
+
+// countPulls adds a pull of a manifest by the event to the versions of the repository.
+// A manifest pulled by digest is counted for all versions which refer to the digest.
+// Blobs pulled are not counted, since they are pulled after their manifest.
+// Requests of the operator itself, e.g. getting manifests to record version details, are not counted either.
+func countPulls(reg *regv1.Registry, event regv1.RegistryEvent) error {
+	if event.Request.Useragent == image.UserAgent || (event.Target.Tag == "" && !isManifest(event.Target.MediaType)) {
+		return nil
+	}
+	pulledAt := eventTime(event)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		repo := &regv1.Repository{}
+		if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: schemes.RepositoryName(event.Target.Repository, reg.Name), Namespace: reg.Namespace}, repo); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		tags := []string{event.Target.Tag}
+		if event.Target.Tag == "" {
+			tags = []string{}
+			for _, s := range repo.Status.Versions {
+				if s.Digest == event.Target.Digest {
+					tags = append(tags, s.Version)
+				}
+			}
+		}
+		pulls := map[string]int64{}
+		times := map[string]time.Time{}
+		for _, tag := range tags {
+			pulls[tag] = 1
+			times[tag] = pulledAt
+		}
+		if len(pulls) == 0 {
+			return nil
+		}
+
+		return repoctl.New().AddPulls(k8sClient, repo, pulls, times)
+	})
+}
+
+// isManifest returns true if the media type is of a manifest, a manifest list or an image index
+func isManifest(mediaType string) bool {
+	return strings.Contains(mediaType, "manifest") || strings.HasSuffix(mediaType, "image.index.v1+json")
+}
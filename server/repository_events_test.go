@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	regv1 "github.com/tmax-cloud/registry-operator/api/v1"
+	"github.com/tmax-cloud/registry-operator/internal/schemes"
+	"github.com/tmax-cloud/registry-operator/pkg/image"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+// newTestRepository returns a registry and its repository whose versions refer to the digests
+func newTestRepository(t *testing.T, digests map[string]string) (*regv1.Registry, *regv1.Repository) {
+	reg := &regv1.Registry{}
+	reg.Name, reg.Namespace = "reg", "ns"
+
+	tags := []string{"v1", "v2", "v3"}
+	repo := schemes.Repository(reg, "library/app", tags)
+	// pulls are patched with optimistic lock
+	repo.ResourceVersion = "1"
+	for _, tag := range tags {
+		repo.Status.Versions = append(repo.Status.Versions, regv1.ImageVersionStatus{Version: tag, Digest: digests[tag]})
+	}
+
+	scheme = runtime.NewScheme()
+	assert.Equal(t, nil, regv1.AddToScheme(scheme))
+	k8sClient = fake.NewFakeClientWithScheme(scheme, repo)
+	return reg, repo
+}
+
+func getTestRepository(t *testing.T, repo *regv1.Repository) *regv1.Repository {
+	latest := &regv1.Repository{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: repo.Name, Namespace: repo.Namespace}, latest); err != nil {
+		assert.Equal(t, true, errors.IsNotFound(err))
+		return nil
+	}
+	return latest
+}
+
+func TestDeleteVersions(t *testing.T) {
+	reg, repo := newTestRepository(t, map[string]string{"v1": "sha256:a", "v2": "sha256:b", "v3": "sha256:a"})
+
+	// a deleted manifest removes all versions which refer to it
+	event := regv1.RegistryEvent{Action: "delete"}
+	event.Target.Repository = "library/app"
+	event.Target.Digest = "sha256:a"
+	assert.Equal(t, nil, deleteVersions(reg, event))
+	latest := getTestRepository(t, repo)
+	assert.Equal(t, 1, len(latest.Spec.Versions))
+	assert.Equal(t, "v2", latest.Spec.Versions[0].Version)
+	assert.Equal(t, []regv1.ImageVersionStatus{{Version: "v2", Digest: "sha256:b"}}, latest.Status.Versions)
+
+	// the repository is deleted with its last version
+	event.Target.Digest, event.Target.Tag = "", "v2"
+	assert.Equal(t, nil, deleteVersions(reg, event))
+	assert.Equal(t, (*regv1.Repository)(nil), getTestRepository(t, repo))
+}
+
+func TestCountPulls(t *testing.T) {
+	reg, repo := newTestRepository(t, map[string]string{"v1": "sha256:a", "v2": "sha256:b", "v3": "sha256:a"})
+	now := time.Now().UTC().Truncate(time.Second)
+
+	pull := func(tag, digest, mediaType, userAgent string) regv1.RegistryEvent {
+		event := regv1.RegistryEvent{Action: "pull", Timestamp: now.Format(time.RFC3339Nano)}
+		event.Target.Repository, event.Target.Tag, event.Target.Digest, event.Target.MediaType = "library/app", tag, digest, mediaType
+		event.Request.Useragent = userAgent
+		return event
+	}
+	events := []regv1.RegistryEvent{
+		pull("v2", "sha256:b", testManifestMediaType, "docker"),
+		// by digest, counted for all versions referring to it
+		pull("", "sha256:a", testManifestMediaType, "docker"),
+		// blobs and requests of the operator are not counted
+		pull("", "sha256:layer", "application/octet-stream", "docker"),
+		pull("v2", "sha256:b", testManifestMediaType, image.UserAgent),
+	}
+	for _, event := range events {
+		assert.Equal(t, nil, countPulls(reg, event))
+	}
+
+	pulls := map[string]int64{}
+	for _, s := range getTestRepository(t, repo).Status.Versions {
+		pulls[s.Version] = s.Pulls
+		assert.Equal(t, now, s.LastPulledAt.Time.UTC())
+	}
+	assert.Equal(t, map[string]int64{"v1": 1, "v2": 1, "v3": 1}, pulls)
+}
+
+func TestCreateImage(t *testing.T) {
+	reg, repo := newTestRepository(t, map[string]string{})
+
+	push := func(repoName, tag string) regv1.RegistryEvent {
+		event := regv1.RegistryEvent{Action: "push"}
+		event.Target.Repository, event.Target.Tag = repoName, tag
+		return event
+	}
+
+	// a new tag is added to the latest versions, and a tag pushed again is not added twice
+	assert.Equal(t, nil, createImage(reg, push("library/app", "v4")))
+	assert.Equal(t, nil, createImage(reg, push("library/app", "v1")))
+	versions := []string{}
+	for _, v := range getTestRepository(t, repo).Spec.Versions {
+		versions = append(versions, v.Version)
+	}
+	assert.Equal(t, []string{"v1", "v2", "v3", "v4"}, versions)
+
+	// a repository is created with its first tag
+	assert.Equal(t, nil, createImage(reg, push("library/new", "latest")))
+	created := getTestRepository(t, schemes.Repository(reg, "library/new", nil))
+	assert.Equal(t, 1, len(created.Spec.Versions))
+	assert.Equal(t, "latest", created.Spec.Versions[0].Version)
+}
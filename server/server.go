@@ -1,10 +1,16 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/tmax-cloud/registry-operator/internal/common/config"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -17,16 +23,76 @@ const (
 var logger = log.Log.WithName("registry-server")
 var scheme *runtime.Scheme
 var k8sClient client.Client
+var recorder record.EventRecorder
+var events *eventQueue
+
+// requireClientCert is true if registry events must be sent with a client certificate signed by the client CA
+var requireClientCert bool
 
 func StartServer(m manager.Manager) {
 	r := mux.NewRouter()
 	scheme = m.GetScheme()
 	k8sClient = m.GetClient()
+	recorder = m.GetEventRecorderFor("registry-server")
+	events = newEventQueue(
+		config.Config.GetInt(config.ConfigRegistryEventQueueSize),
+		config.Config.GetInt(config.ConfigRegistryEventWorkers),
+		config.Config.GetInt(config.ConfigRegistryEventMaxRetries),
+		processEvent,
+	)
+	if err := m.Add(events); err != nil {
+		logger.Error(err, "failed to add registry event workers")
+		return
+	}
 	logger.Info("Handle", "Path", RegistryEventPath)
 	r.HandleFunc(RegistryEventPath, CreateImageHandler).Methods(http.MethodPost)
 
-	logger.Info("Listen", "Port", port)
-	if err := http.ListenAndServe(port, r); err != nil {
+	srv := &http.Server{Addr: port, Handler: r}
+	if clientCAFile := config.Config.GetString(config.ConfigRegistryEventClientCAFile); clientCAFile != "" {
+		pool, err := clientCAPool(clientCAFile)
+		if err != nil {
+			logger.Error(err, "failed to load client CA", "file", clientCAFile)
+			return
+		}
+		// the token endpoint is served without client certificates, so they are verified only if given
+		// and required by the registry event handler
+		srv.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+		requireClientCert = true
+	}
+
+	certFile := config.Config.GetString(config.ConfigServerTLSCertFile)
+	keyFile := config.Config.GetString(config.ConfigServerTLSKeyFile)
+	if certFile == "" || keyFile == "" {
+		if requireClientCert {
+			logger.Error(fmt.Errorf("client CA is set without server certificate"), "Server listen error")
+			return
+		}
+		// token service takes passwords, so it is not served over plain http
+		logger.Info("Token service is disabled without TLS", "Path", TokenPath)
+		logger.Info("Listen", "Port", port)
+		if err := srv.ListenAndServe(); err != nil {
+			logger.Error(err, "Server listen error")
+		}
+		return
+	}
+
+	logger.Info("Handle", "Path", TokenPath)
+	r.HandleFunc(TokenPath, TokenHandler).Methods(http.MethodGet)
+
+	logger.Info("Listen TLS", "Port", port)
+	if err := srv.ListenAndServeTLS(certFile, keyFile); err != nil {
 		logger.Error(err, "Server listen error")
 	}
 }
+
+func clientCAPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate is found in %s", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/tmax-cloud/registry-operator/pkg/auth"
+)
+
+const (
+	// TokenPath is the path of builtin token service. The namespace is the realm of registries.
+	TokenPath = auth.TokenPath + "/{namespace}"
+)
+
+// +kubebuilder:rbac:groups=tmax.io,resources=repositorypermissions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// TokenHandler issues registry tokens following docker token authentication specification
+func TokenHandler(w http.ResponseWriter, r *http.Request) {
+	req := &auth.TokenRequest{
+		Namespace: mux.Vars(r)["namespace"],
+		Service:   r.URL.Query().Get("service"),
+		Scopes:    r.URL.Query()["scope"],
+	}
+	if req.Service == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "service is required")
+		return
+	}
+	if username, password, ok := r.BasicAuth(); ok {
+		req.Username, req.Password = username, password
+	}
+
+	token, err := auth.NewTokenService(k8sClient).Issue(req)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnauthorized) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", auth.TokenRealm(req.Namespace)))
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, err)
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidScope) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, err)
+			return
+		}
+		logz.Error(err, "failed to issue token", "namespace", req.Namespace, "service", req.Service)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		logz.Error(err, "failed to write token")
+	}
+}
@@ -36,6 +36,7 @@ DeleteManager() {
 
     # Delete webhook
     kubectl delete -f config/webhook/mutating-webhook.yaml
+    kubectl delete -f config/webhook/validating-webhook.yaml
 
     # Delete apiservice
     kubectl delete -f config/apiservice/apiservice.yaml